@@ -0,0 +1,13 @@
+package athenafake_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestAthenafake(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Athenafake Suite")
+}
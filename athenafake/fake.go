@@ -0,0 +1,365 @@
+// Package athenafake provides an in-memory implementation of the athena API used by athenaconv/client,
+// so code built on athena can be unit tested offline.
+package athenafake
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena"
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv/util"
+)
+
+// Operation is the name of an athena API operation implemented by Fake
+type Operation string
+
+const (
+	// OpStartQueryExecution is the StartQueryExecution operation
+	OpStartQueryExecution Operation = "StartQueryExecution"
+	// OpGetQueryExecution is the GetQueryExecution operation
+	OpGetQueryExecution Operation = "GetQueryExecution"
+	// OpGetQueryResults is the GetQueryResults operation
+	OpGetQueryResults Operation = "GetQueryResults"
+	// OpStopQueryExecution is the StopQueryExecution operation
+	OpStopQueryExecution Operation = "StopQueryExecution"
+	// OpCreatePreparedStatement is the CreatePreparedStatement operation
+	OpCreatePreparedStatement Operation = "CreatePreparedStatement"
+	// OpGetPreparedStatement is the GetPreparedStatement operation
+	OpGetPreparedStatement Operation = "GetPreparedStatement"
+	// OpUpdatePreparedStatement is the UpdatePreparedStatement operation
+	OpUpdatePreparedStatement Operation = "UpdatePreparedStatement"
+	// OpDeletePreparedStatement is the DeletePreparedStatement operation
+	OpDeletePreparedStatement Operation = "DeletePreparedStatement"
+	// OpListPreparedStatements is the ListPreparedStatements operation
+	OpListPreparedStatements Operation = "ListPreparedStatements"
+)
+
+// Query is a scripted query returned by Fake when started with the matching SQL
+type Query struct {
+	// States returned by successive GetQueryExecution calls, the last state is kept once reached.
+	// Defaults to QUEUED, RUNNING, SUCCEEDED.
+	States []types.QueryExecutionState
+	// StateChangeReason returned with the final state, e.g. the reason of FAILED state
+	StateChangeReason string
+	// Statistics returned by GetQueryExecution
+	Statistics *types.QueryExecutionStatistics
+	// Pages returned by successive GetQueryResults calls, the first page should contain the header row
+	Pages []*types.ResultSet
+}
+
+// Fake is an in-memory athena API with scripted queries, state transitions, injected errors and latency.
+// Fake is safe for concurrent use.
+type Fake struct {
+	mu         sync.Mutex
+	queries    map[string][]Query
+	started    map[string]int
+	executions map[string]*execution
+	order      []string
+	errors     map[Operation][]error
+	calls      map[Operation]int
+	latency    time.Duration
+	// statements are the prepared statements by workgroup and statement name
+	statements map[string]map[string]*types.PreparedStatement
+}
+
+// execution is the state of a started query
+type execution struct {
+	id          string
+	sql         string
+	input       *athena.StartQueryExecutionInput
+	query       Query
+	reported    int
+	stopped     bool
+	submittedAt time.Time
+}
+
+// New creates new Fake without scripted queries
+func New() *Fake {
+	return &Fake{
+		queries:    make(map[string][]Query),
+		started:    make(map[string]int),
+		executions: make(map[string]*execution),
+		errors:     make(map[Operation][]error),
+		calls:      make(map[Operation]int),
+		statements: make(map[string]map[string]*types.PreparedStatement),
+	}
+}
+
+// AddQuery scripts the query returned when StartQueryExecution is called with the given SQL, compared ignoring surrounding whitespace
+func (f *Fake) AddQuery(sql string, query Query) {
+	f.AddQuerySequence(sql, query)
+}
+
+// AddQuerySequence scripts the queries returned by successive StartQueryExecution calls with the given SQL,
+// e.g. a failed attempt followed by a successful one. The last query is kept once reached.
+func (f *Fake) AddQuerySequence(sql string, queries ...Query) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	scripted := make([]Query, 0, len(queries))
+	for _, query := range queries {
+		if len(query.States) == 0 {
+			query.States = []types.QueryExecutionState{types.QueryExecutionStateQueued, types.QueryExecutionStateRunning, types.QueryExecutionStateSucceeded}
+		}
+		scripted = append(scripted, query)
+	}
+	f.queries[normalizeSQL(sql)] = scripted
+	f.started[normalizeSQL(sql)] = 0
+}
+
+// FailNext makes the next calls of the given operation return the given errors, one error per call
+func (f *Fake) FailNext(op Operation, errs ...error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.errors[op] = append(f.errors[op], errs...)
+}
+
+// SetLatency delays every call by the given duration, or until the context is done
+func (f *Fake) SetLatency(latency time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.latency = latency
+}
+
+// Calls returns the number of calls of the given operation
+func (f *Fake) Calls(op Operation) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[op]
+}
+
+// Executions returns the IDs of started query executions in order
+func (f *Fake) Executions() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.order...)
+}
+
+// StartInput returns the StartQueryExecution input of the given query execution
+func (f *Fake) StartInput(queryExecutionID string) (*athena.StartQueryExecutionInput, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	exec, ok := f.executions[queryExecutionID]
+	if !ok {
+		return nil, false
+	}
+	return exec.input, true
+}
+
+// Stopped returns true if StopQueryExecution was called for the given query execution
+func (f *Fake) Stopped(queryExecutionID string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	exec, ok := f.executions[queryExecutionID]
+	return ok && exec.stopped
+}
+
+// StartQueryExecution starts the query scripted for the input SQL
+func (f *Fake) StartQueryExecution(ctx context.Context, params *athena.StartQueryExecutionInput, optFns ...func(*athena.Options)) (*athena.StartQueryExecutionOutput, error) {
+	if err := f.call(ctx, OpStartQueryExecution); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	sql := util.SafeString(params.QueryString)
+	if err := f.checkPreparedStatement(sql, params.WorkGroup); err != nil {
+		return nil, err
+	}
+	queries := f.queries[normalizeSQL(sql)]
+	if len(queries) == 0 {
+		err := fmt.Errorf("athenafake: no query scripted for SQL: %s", sql)
+		return nil, err
+	}
+	query := queries[len(queries)-1]
+	if started := f.started[normalizeSQL(sql)]; started < len(queries) {
+		query = queries[started]
+	}
+	f.started[normalizeSQL(sql)]++
+
+	id := "query-" + strconv.Itoa(len(f.order)+1)
+	f.executions[id] = &execution{
+		id:          id,
+		sql:         sql,
+		input:       params,
+		query:       query,
+		reported:    -1,
+		submittedAt: time.Now(),
+	}
+	f.order = append(f.order, id)
+	return &athena.StartQueryExecutionOutput{QueryExecutionId: util.RefString(id)}, nil
+}
+
+// GetQueryExecution returns the next scripted state of the query execution
+func (f *Fake) GetQueryExecution(ctx context.Context, params *athena.GetQueryExecutionInput, optFns ...func(*athena.Options)) (*athena.GetQueryExecutionOutput, error) {
+	if err := f.call(ctx, OpGetQueryExecution); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	exec, err := f.execution(params.QueryExecutionId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exec.stopped && exec.reported < len(exec.query.States)-1 {
+		exec.reported++
+	}
+	state := exec.currentState()
+	status := &types.QueryExecutionStatus{
+		State:              state,
+		SubmissionDateTime: &exec.submittedAt,
+	}
+	if isFinalState(state) {
+		completedAt := exec.submittedAt
+		status.CompletionDateTime = &completedAt
+		if exec.query.StateChangeReason != "" && !exec.stopped {
+			status.StateChangeReason = util.RefString(exec.query.StateChangeReason)
+		}
+	}
+	return &athena.GetQueryExecutionOutput{
+		QueryExecution: &types.QueryExecution{
+			QueryExecutionId:      util.RefString(exec.id),
+			Query:                 util.RefString(exec.sql),
+			QueryExecutionContext: exec.input.QueryExecutionContext,
+			ResultConfiguration:   exec.resultConfiguration(),
+			WorkGroup:             exec.input.WorkGroup,
+			Statistics:            exec.query.Statistics,
+			Status:                status,
+		},
+	}, nil
+}
+
+// GetQueryResults returns the scripted page for the NextToken, the query execution should have succeeded
+func (f *Fake) GetQueryResults(ctx context.Context, params *athena.GetQueryResultsInput, optFns ...func(*athena.Options)) (*athena.GetQueryResultsOutput, error) {
+	if err := f.call(ctx, OpGetQueryResults); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	exec, err := f.execution(params.QueryExecutionId)
+	if err != nil {
+		return nil, err
+	}
+	if state := exec.currentState(); state != types.QueryExecutionStateSucceeded {
+		err := fmt.Errorf("athenafake: query execution %s has not succeeded, state: %s", exec.id, state)
+		return nil, err
+	}
+
+	page := 0
+	if params.NextToken != nil {
+		page, err = strconv.Atoi(*params.NextToken)
+		if err != nil || page < 0 || page >= len(exec.query.Pages) {
+			err := fmt.Errorf("athenafake: invalid NextToken: %s", *params.NextToken)
+			return nil, err
+		}
+	}
+
+	output := &athena.GetQueryResultsOutput{
+		ResultSet: &types.ResultSet{},
+	}
+	if page < len(exec.query.Pages) {
+		output.ResultSet = copyResultSet(exec.query.Pages[page])
+	}
+	if page+1 < len(exec.query.Pages) {
+		output.NextToken = util.RefString(strconv.Itoa(page + 1))
+	}
+	return output, nil
+}
+
+// StopQueryExecution cancels the query execution, subsequent GetQueryExecution calls return CANCELLED
+func (f *Fake) StopQueryExecution(ctx context.Context, params *athena.StopQueryExecutionInput, optFns ...func(*athena.Options)) (*athena.StopQueryExecutionOutput, error) {
+	if err := f.call(ctx, OpStopQueryExecution); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	exec, err := f.execution(params.QueryExecutionId)
+	if err != nil {
+		return nil, err
+	}
+	if !isFinalState(exec.currentState()) {
+		exec.stopped = true
+	}
+	return &athena.StopQueryExecutionOutput{}, nil
+}
+
+// call records the call, applies the latency and returns the next injected error of the operation
+func (f *Fake) call(ctx context.Context, op Operation) error {
+	f.mu.Lock()
+	f.calls[op]++
+	latency := f.latency
+	var err error
+	if errs := f.errors[op]; len(errs) > 0 {
+		err = errs[0]
+		f.errors[op] = errs[1:]
+	}
+	f.mu.Unlock()
+
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+	return err
+}
+
+func (f *Fake) execution(queryExecutionID *string) (*execution, error) {
+	exec, ok := f.executions[util.SafeString(queryExecutionID)]
+	if !ok {
+		err := fmt.Errorf("athenafake: query execution not found: %s", util.SafeString(queryExecutionID))
+		return nil, err
+	}
+	return exec, nil
+}
+
+// resultConfiguration returns the result configuration of the start input with the output location of the CSV result file,
+// i.e. <output location>/<query execution ID>.csv like athena
+func (e *execution) resultConfiguration() *types.ResultConfiguration {
+	if e.input.ResultConfiguration == nil {
+		return nil
+	}
+	resultConfiguration := *e.input.ResultConfiguration
+	if resultConfiguration.OutputLocation != nil {
+		outputLocation := strings.TrimSuffix(*resultConfiguration.OutputLocation, "/") + "/" + e.id + ".csv"
+		resultConfiguration.OutputLocation = &outputLocation
+	}
+	return &resultConfiguration
+}
+
+// currentState returns the last reported state of the execution, CANCELLED once stopped
+func (e *execution) currentState() types.QueryExecutionState {
+	if e.stopped {
+		return types.QueryExecutionStateCancelled
+	}
+	if e.reported < 0 {
+		return e.query.States[0]
+	}
+	return e.query.States[e.reported]
+}
+
+func isFinalState(state types.QueryExecutionState) bool {
+	return state != types.QueryExecutionStateQueued && state != types.QueryExecutionStateRunning
+}
+
+func normalizeSQL(sql string) string {
+	return strings.TrimSpace(sql)
+}
+
+// copyResultSet returns a shallow copy of the page so that callers slicing the rows do not modify the script
+func copyResultSet(resultSet *types.ResultSet) *types.ResultSet {
+	copied := *resultSet
+	copied.Rows = append([]types.Row(nil), resultSet.Rows...)
+	return &copied
+}
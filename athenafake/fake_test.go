@@ -0,0 +1,211 @@
+package athenafake
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena"
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Fake", func() {
+	var ctx context.Context
+	var fake *Fake
+	var pages []*types.ResultSet
+
+	start := func(sql string) string {
+		output, err := fake.StartQueryExecution(ctx, &athena.StartQueryExecutionInput{QueryString: util.RefString(sql)})
+		Expect(err).ToNot(HaveOccurred())
+		return *output.QueryExecutionId
+	}
+	state := func(id string) types.QueryExecutionState {
+		output, err := fake.GetQueryExecution(ctx, &athena.GetQueryExecutionInput{QueryExecutionId: util.RefString(id)})
+		Expect(err).ToNot(HaveOccurred())
+		return output.QueryExecution.Status.State
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		fake = New()
+		metadata := &types.ResultSetMetadata{
+			ColumnInfo: []types.ColumnInfo{{Name: util.RefString("id"), Type: util.RefString("integer")}},
+		}
+		pages = []*types.ResultSet{
+			{ResultSetMetadata: metadata, Rows: []types.Row{{Data: []types.Datum{{VarCharValue: util.RefString("id")}}}}},
+			{ResultSetMetadata: metadata, Rows: []types.Row{{Data: []types.Datum{{VarCharValue: util.RefString("1")}}}}},
+		}
+		fake.AddQuery("select id from t", Query{Pages: pages})
+	})
+
+	When("query is scripted", func() {
+		It("should transition through default states and return pages", func() {
+			id := start("  select id from t\n")
+			Expect(id).To(Equal("query-1"))
+
+			_, err := fake.GetQueryResults(ctx, &athena.GetQueryResultsInput{QueryExecutionId: &id})
+			Expect(err).To(HaveOccurred())
+
+			Expect(state(id)).To(Equal(types.QueryExecutionStateQueued))
+			Expect(state(id)).To(Equal(types.QueryExecutionStateRunning))
+			Expect(state(id)).To(Equal(types.QueryExecutionStateSucceeded))
+			Expect(state(id)).To(Equal(types.QueryExecutionStateSucceeded))
+
+			output, err := fake.GetQueryResults(ctx, &athena.GetQueryResultsInput{QueryExecutionId: &id})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(output.ResultSet.Rows).To(Equal(pages[0].Rows))
+			Expect(*output.NextToken).To(Equal("1"))
+
+			output, err = fake.GetQueryResults(ctx, &athena.GetQueryResultsInput{QueryExecutionId: &id, NextToken: output.NextToken})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(output.ResultSet.Rows).To(Equal(pages[1].Rows))
+			Expect(output.NextToken).To(BeNil())
+
+			Expect(fake.Calls(OpGetQueryExecution)).To(Equal(4))
+			Expect(fake.Calls(OpGetQueryResults)).To(Equal(3))
+		})
+
+		It("should return scripted failure with reason", func() {
+			fake.AddQuery("select fail", Query{
+				States:            []types.QueryExecutionState{types.QueryExecutionStateFailed},
+				StateChangeReason: "SYNTAX_ERROR",
+			})
+			id := start("select fail")
+			output, err := fake.GetQueryExecution(ctx, &athena.GetQueryExecutionInput{QueryExecutionId: &id})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(output.QueryExecution.Status.State).To(Equal(types.QueryExecutionStateFailed))
+			Expect(*output.QueryExecution.Status.StateChangeReason).To(Equal("SYNTAX_ERROR"))
+			Expect(output.QueryExecution.Status.CompletionDateTime).ToNot(BeNil())
+		})
+	})
+
+	When("query is started with output location", func() {
+		It("should return the location of the CSV result file", func() {
+			output, err := fake.StartQueryExecution(ctx, &athena.StartQueryExecutionInput{
+				QueryString:         util.RefString("select id from t"),
+				ResultConfiguration: &types.ResultConfiguration{OutputLocation: util.RefString("s3://bucket/results/")},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			execOutput, err := fake.GetQueryExecution(ctx, &athena.GetQueryExecutionInput{QueryExecutionId: output.QueryExecutionId})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*execOutput.QueryExecution.ResultConfiguration.OutputLocation).To(Equal("s3://bucket/results/query-1.csv"))
+		})
+	})
+
+	When("query sequence is scripted", func() {
+		It("should return successive queries and keep the last one", func() {
+			fake.AddQuerySequence("select seq",
+				Query{States: []types.QueryExecutionState{types.QueryExecutionStateFailed}},
+				Query{States: []types.QueryExecutionState{types.QueryExecutionStateSucceeded}},
+			)
+			Expect(state(start("select seq"))).To(Equal(types.QueryExecutionStateFailed))
+			Expect(state(start("select seq"))).To(Equal(types.QueryExecutionStateSucceeded))
+			Expect(state(start("select seq"))).To(Equal(types.QueryExecutionStateSucceeded))
+			Expect(fake.Executions()).To(Equal([]string{"query-1", "query-2", "query-3"}))
+		})
+	})
+
+	When("prepared statement is managed", func() {
+		It("should create, get, update, list and delete it", func() {
+			input := &athena.CreatePreparedStatementInput{
+				StatementName:  util.RefString("find"),
+				QueryStatement: util.RefString("select id from t where id = ?"),
+			}
+			_, err := fake.CreatePreparedStatement(ctx, input)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = fake.CreatePreparedStatement(ctx, input)
+			Expect(err).To(HaveOccurred())
+
+			_, err = fake.UpdatePreparedStatement(ctx, &athena.UpdatePreparedStatementInput{
+				StatementName:  util.RefString("find"),
+				QueryStatement: util.RefString("select id from t"),
+				WorkGroup:      util.RefString("primary"),
+			})
+			Expect(err).ToNot(HaveOccurred())
+			output, err := fake.GetPreparedStatement(ctx, &athena.GetPreparedStatementInput{StatementName: util.RefString("find")})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*output.PreparedStatement.QueryStatement).To(Equal("select id from t"))
+			Expect(*output.PreparedStatement.WorkGroupName).To(Equal("primary"))
+
+			listOutput, err := fake.ListPreparedStatements(ctx, &athena.ListPreparedStatementsInput{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(listOutput.PreparedStatements).To(HaveLen(1))
+			Expect(*listOutput.PreparedStatements[0].StatementName).To(Equal("find"))
+
+			_, err = fake.DeletePreparedStatement(ctx, &athena.DeletePreparedStatementInput{StatementName: util.RefString("find")})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fake.PreparedStatements("primary")).To(BeEmpty())
+		})
+
+		It("should return not found error with error code", func() {
+			_, err := fake.GetPreparedStatement(ctx, &athena.GetPreparedStatementInput{StatementName: util.RefString("missing")})
+			var apiErr *APIError
+			Expect(errors.As(err, &apiErr)).To(BeTrue())
+			Expect(apiErr.ErrorCode()).To(Equal("ResourceNotFoundException"))
+		})
+
+		It("should fail to execute statement which is not prepared", func() {
+			fake.AddQuery("EXECUTE find USING 1", Query{Pages: pages})
+			_, err := fake.StartQueryExecution(ctx, &athena.StartQueryExecutionInput{QueryString: util.RefString("EXECUTE find USING 1")})
+			Expect(err).To(MatchError(ContainSubstring("prepared statement not found: find")))
+
+			_, err = fake.CreatePreparedStatement(ctx, &athena.CreatePreparedStatementInput{
+				StatementName:  util.RefString("find"),
+				QueryStatement: util.RefString("select id from t where id = ?"),
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(start("EXECUTE find USING 1")).To(Equal("query-1"))
+		})
+	})
+
+	When("query is not scripted", func() {
+		It("should return error on start", func() {
+			_, err := fake.StartQueryExecution(ctx, &athena.StartQueryExecutionInput{QueryString: util.RefString("select 1")})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("no query scripted"))
+		})
+	})
+
+	When("query is stopped", func() {
+		It("should return cancelled state", func() {
+			id := start("select id from t")
+			Expect(state(id)).To(Equal(types.QueryExecutionStateQueued))
+			_, err := fake.StopQueryExecution(ctx, &athena.StopQueryExecutionInput{QueryExecutionId: &id})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fake.Stopped(id)).To(BeTrue())
+			Expect(state(id)).To(Equal(types.QueryExecutionStateCancelled))
+		})
+	})
+
+	When("errors are injected", func() {
+		It("should return each error once", func() {
+			fake.FailNext(OpStartQueryExecution, errors.New("first"), errors.New("second"))
+			_, err := fake.StartQueryExecution(ctx, &athena.StartQueryExecutionInput{QueryString: util.RefString("select id from t")})
+			Expect(err).To(MatchError("first"))
+			_, err = fake.StartQueryExecution(ctx, &athena.StartQueryExecutionInput{QueryString: util.RefString("select id from t")})
+			Expect(err).To(MatchError("second"))
+			Expect(start("select id from t")).To(Equal("query-1"))
+		})
+	})
+
+	When("latency is set", func() {
+		It("should delay calls", func() {
+			fake.SetLatency(20 * time.Millisecond)
+			started := time.Now()
+			start("select id from t")
+			Expect(time.Since(started)).To(BeNumerically(">=", 20*time.Millisecond))
+		})
+
+		It("should return context error if context is done first", func() {
+			fake.SetLatency(time.Minute)
+			cancelledCtx, cancel := context.WithCancel(ctx)
+			cancel()
+			_, err := fake.StartQueryExecution(cancelledCtx, &athena.StartQueryExecutionInput{QueryString: util.RefString("select id from t")})
+			Expect(err).To(MatchError(context.Canceled))
+		})
+	})
+})
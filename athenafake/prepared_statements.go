@@ -0,0 +1,168 @@
+package athenafake
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena"
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv/util"
+)
+
+const defaultWorkGroup = "primary"
+
+// APIError is returned by Fake for errors reported by athena with an error code, e.g. ResourceNotFoundException.
+// Like the API errors of aws-sdk-go-v2, the error code is exposed by ErrorCode.
+type APIError struct {
+	Code    string
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("athenafake: %s: %s", e.Code, e.Message)
+}
+
+// ErrorCode returns the error code of the API error
+func (e *APIError) ErrorCode() string {
+	return e.Code
+}
+
+// PreparedStatements returns the names of the prepared statements of the workgroup in order
+func (f *Fake) PreparedStatements(workGroup string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	names := make([]string, 0, len(f.statements[workGroup]))
+	for name := range f.statements[workGroup] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CreatePreparedStatement creates the prepared statement in the workgroup, the statement name should not exist yet
+func (f *Fake) CreatePreparedStatement(ctx context.Context, params *athena.CreatePreparedStatementInput, optFns ...func(*athena.Options)) (*athena.CreatePreparedStatementOutput, error) {
+	if err := f.call(ctx, OpCreatePreparedStatement); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	workGroup, name := workGroupOf(params.WorkGroup), util.SafeString(params.StatementName)
+	if _, ok := f.statements[workGroup][name]; ok {
+		err := &APIError{Code: "InvalidRequestException", Message: "prepared statement already exists: " + name}
+		return nil, err
+	}
+	if f.statements[workGroup] == nil {
+		f.statements[workGroup] = make(map[string]*types.PreparedStatement)
+	}
+	f.statements[workGroup][name] = newPreparedStatement(workGroup, name, params.QueryStatement, params.Description)
+	return &athena.CreatePreparedStatementOutput{}, nil
+}
+
+// GetPreparedStatement returns the prepared statement of the workgroup
+func (f *Fake) GetPreparedStatement(ctx context.Context, params *athena.GetPreparedStatementInput, optFns ...func(*athena.Options)) (*athena.GetPreparedStatementOutput, error) {
+	if err := f.call(ctx, OpGetPreparedStatement); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	statement, err := f.preparedStatement(params.WorkGroup, params.StatementName)
+	if err != nil {
+		return nil, err
+	}
+	copied := *statement
+	return &athena.GetPreparedStatementOutput{PreparedStatement: &copied}, nil
+}
+
+// UpdatePreparedStatement replaces the query statement of the prepared statement of the workgroup
+func (f *Fake) UpdatePreparedStatement(ctx context.Context, params *athena.UpdatePreparedStatementInput, optFns ...func(*athena.Options)) (*athena.UpdatePreparedStatementOutput, error) {
+	if err := f.call(ctx, OpUpdatePreparedStatement); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, err := f.preparedStatement(params.WorkGroup, params.StatementName); err != nil {
+		return nil, err
+	}
+	workGroup, name := workGroupOf(params.WorkGroup), util.SafeString(params.StatementName)
+	f.statements[workGroup][name] = newPreparedStatement(workGroup, name, params.QueryStatement, params.Description)
+	return &athena.UpdatePreparedStatementOutput{}, nil
+}
+
+// DeletePreparedStatement deletes the prepared statement of the workgroup
+func (f *Fake) DeletePreparedStatement(ctx context.Context, params *athena.DeletePreparedStatementInput, optFns ...func(*athena.Options)) (*athena.DeletePreparedStatementOutput, error) {
+	if err := f.call(ctx, OpDeletePreparedStatement); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, err := f.preparedStatement(params.WorkGroup, params.StatementName); err != nil {
+		return nil, err
+	}
+	delete(f.statements[workGroupOf(params.WorkGroup)], util.SafeString(params.StatementName))
+	return &athena.DeletePreparedStatementOutput{}, nil
+}
+
+// ListPreparedStatements returns the prepared statements of the workgroup ordered by name, all in a single page
+func (f *Fake) ListPreparedStatements(ctx context.Context, params *athena.ListPreparedStatementsInput, optFns ...func(*athena.Options)) (*athena.ListPreparedStatementsOutput, error) {
+	if err := f.call(ctx, OpListPreparedStatements); err != nil {
+		return nil, err
+	}
+
+	workGroup := workGroupOf(params.WorkGroup)
+	output := &athena.ListPreparedStatementsOutput{}
+	for _, name := range f.PreparedStatements(workGroup) {
+		f.mu.Lock()
+		statement := f.statements[workGroup][name]
+		f.mu.Unlock()
+		output.PreparedStatements = append(output.PreparedStatements, types.PreparedStatementSummary{
+			StatementName:    util.RefString(name),
+			LastModifiedTime: statement.LastModifiedTime,
+		})
+	}
+	return output, nil
+}
+
+func (f *Fake) preparedStatement(workGroup, name *string) (*types.PreparedStatement, error) {
+	statement, ok := f.statements[workGroupOf(workGroup)][util.SafeString(name)]
+	if !ok {
+		err := &APIError{Code: "ResourceNotFoundException", Message: "prepared statement not found: " + util.SafeString(name)}
+		return nil, err
+	}
+	return statement, nil
+}
+
+// checkPreparedStatement returns error if the SQL executes a prepared statement which does not exist in the workgroup
+func (f *Fake) checkPreparedStatement(sql string, workGroup *string) error {
+	fields := strings.Fields(sql)
+	if len(fields) < 2 || !strings.EqualFold(fields[0], "execute") {
+		return nil
+	}
+	_, err := f.preparedStatement(workGroup, &fields[1])
+	return err
+}
+
+func newPreparedStatement(workGroup, name string, queryStatement, description *string) *types.PreparedStatement {
+	lastModified := time.Now()
+	return &types.PreparedStatement{
+		StatementName:    util.RefString(name),
+		WorkGroupName:    util.RefString(workGroup),
+		QueryStatement:   queryStatement,
+		Description:      description,
+		LastModifiedTime: &lastModified,
+	}
+}
+
+// workGroupOf returns the workgroup name, the primary workgroup if not set like athena
+func workGroupOf(workGroup *string) string {
+	if util.SafeString(workGroup) == "" {
+		return defaultWorkGroup
+	}
+	return *workGroup
+}
@@ -0,0 +1,147 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kent-id/athenaconv"
+)
+
+// statement is the SQL query started by Client with its execution parameters, if any
+type statement struct {
+	sql                 string
+	executionParameters []string
+	// preparedSQL is the SQL query of the prepared statement run by the EXECUTE statement, classified by RetryPolicy instead of sql
+	preparedSQL string
+}
+
+// retrySQL returns the SQL query classified by RetryPolicy.IsIdempotent
+func (s statement) retrySQL() string {
+	if s.preparedSQL != "" {
+		return s.preparedSQL
+	}
+	return s.sql
+}
+
+// splitArgs separates the query args bound to the ? placeholders from the athenaconv.MapperOption values configuring the mapper
+func splitArgs(args []interface{}) ([]interface{}, []athenaconv.MapperOption) {
+	queryArgs := make([]interface{}, 0, len(args))
+	opts := make([]athenaconv.MapperOption, 0)
+	for _, arg := range args {
+		if opt, ok := arg.(athenaconv.MapperOption); ok {
+			opts = append(opts, opt)
+			continue
+		}
+		queryArgs = append(queryArgs, arg)
+	}
+	return queryArgs, opts
+}
+
+// bindArgs formats the args with athenaconv.FormatLiteral, passed as execution parameters unless Config.InterpolateArgs is set
+func (c *Client) bindArgs(sqlQuery string, args []interface{}) (statement, error) {
+	placeholders := countPlaceholders(sqlQuery)
+	if placeholders != len(args) {
+		err := fmt.Errorf("mismatched query args count: %d, expected: %d placeholders", len(args), placeholders)
+		return statement{}, err
+	}
+	if len(args) == 0 {
+		return statement{sql: sqlQuery}, nil
+	}
+
+	literals, err := formatArgs(args)
+	if err != nil {
+		return statement{}, err
+	}
+
+	if c.config.InterpolateArgs {
+		return statement{sql: interpolatePlaceholders(sqlQuery, literals)}, nil
+	}
+	return statement{sql: sqlQuery, executionParameters: literals}, nil
+}
+
+// formatArgs formats the query args into SQL literals with athenaconv.FormatLiteral
+func formatArgs(args []interface{}) ([]string, error) {
+	literals := make([]string, 0, len(args))
+	for i, arg := range args {
+		literal, err := athenaconv.FormatLiteral(arg)
+		if err != nil {
+			err := fmt.Errorf("invalid query arg %d: %v", i+1, err)
+			return nil, err
+		}
+		literals = append(literals, literal)
+	}
+	return literals, nil
+}
+
+// countPlaceholders returns the number of ? placeholders of the SQL query
+func countPlaceholders(sqlQuery string) int {
+	count := 0
+	scanPlaceholders(sqlQuery, func(index int) string {
+		count++
+		return "?"
+	})
+	return count
+}
+
+// interpolatePlaceholders replaces the ? placeholders of the SQL query with the literals in order
+func interpolatePlaceholders(sqlQuery string, literals []string) string {
+	return scanPlaceholders(sqlQuery, func(index int) string {
+		return literals[index]
+	})
+}
+
+// scanPlaceholders returns the SQL query with every ? placeholder replaced, ? in string literals, quoted identifiers and comments are kept
+func scanPlaceholders(sqlQuery string, replace func(index int) string) string {
+	var builder strings.Builder
+	index := 0
+	for i := 0; i < len(sqlQuery); i++ {
+		c := sqlQuery[i]
+		switch {
+		case c == '\'' || c == '"':
+			end := i + 1
+			for end < len(sqlQuery) {
+				if sqlQuery[end] == c {
+					// quote escaped by doubling
+					if end+1 < len(sqlQuery) && sqlQuery[end+1] == c {
+						end += 2
+						continue
+					}
+					break
+				}
+				end++
+			}
+			end = minInt(end, len(sqlQuery)-1)
+			builder.WriteString(sqlQuery[i : end+1])
+			i = end
+		case c == '-' && strings.HasPrefix(sqlQuery[i:], "--"):
+			end := strings.IndexByte(sqlQuery[i:], '\n')
+			if end < 0 {
+				end = len(sqlQuery) - i - 1
+			}
+			builder.WriteString(sqlQuery[i : i+end+1])
+			i += end
+		case c == '/' && strings.HasPrefix(sqlQuery[i:], "/*"):
+			end := strings.Index(sqlQuery[i+2:], "*/")
+			if end < 0 {
+				end = len(sqlQuery) - i - 1
+			} else {
+				end += 3
+			}
+			builder.WriteString(sqlQuery[i : i+end+1])
+			i += end
+		case c == '?':
+			builder.WriteString(replace(index))
+			index++
+		default:
+			builder.WriteByte(c)
+		}
+	}
+	return builder.String()
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv"
+	"github.com/kent-id/athenaconv/athenafake"
+	"github.com/kent-id/athenaconv/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Args", func() {
+	Context("interpolatePlaceholders", func() {
+		It("should replace placeholders outside of literals, identifiers and comments", func() {
+			sqlQuery := `select '?', "col?", 'it''s ?' -- why?
+from t /* where x = ? */ where a = ? and b in (?, ?)`
+			Expect(countPlaceholders(sqlQuery)).To(Equal(3))
+			Expect(interpolatePlaceholders(sqlQuery, []string{"1", "'x'", "NULL"})).To(Equal(`select '?', "col?", 'it''s ?' -- why?
+from t /* where x = ? */ where a = 1 and b in ('x', NULL)`))
+		})
+
+		It("should keep unterminated literals and comments", func() {
+			Expect(countPlaceholders("select 'a?")).To(Equal(0))
+			Expect(countPlaceholders("select 1 /* ?")).To(Equal(0))
+			Expect(countPlaceholders("select ? -- ?")).To(Equal(1))
+		})
+	})
+
+	Context("Query with args", func() {
+		var ctx context.Context
+		var api *athenafake.Fake
+		var athenaClient *Client
+		const sqlQuery = "select id, name from t where name = ? and id > ?"
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			api = athenafake.New()
+			athenaClient = New(api, Config{WaitInterval: time.Millisecond, InterpolateArgs: true})
+		})
+
+		It("should bind args and split mapper options", func() {
+			pages := newTestPages(1, 1)
+			pages[0].Rows = pages[0].Rows[1:]
+			api.AddQuery("select id, name from t where name = 'o''neil' and id > 10", athenafake.Query{Pages: pages})
+
+			result, err := athenaClient.Query(ctx, sqlQuery, reflect.TypeOf(testModel{}), "o'neil", athenaconv.WithHeaderRow(athenaconv.HeaderRowAbsent), 10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal([]interface{}{&testModel{ID: 0, Name: "name 0"}}))
+			startInput, ok := api.StartInput("query-1")
+			Expect(ok).To(BeTrue())
+			Expect(*startInput.QueryString).To(Equal("select id, name from t where name = 'o''neil' and id > 10"))
+		})
+
+		It("should return error if args do not match placeholders", func() {
+			_, err := athenaClient.QueryMaps(ctx, sqlQuery, "a")
+			Expect(err).To(MatchError("mismatched query args count: 1, expected: 2 placeholders"))
+			Expect(api.Calls(athenafake.OpStartQueryExecution)).To(Equal(0))
+		})
+
+		It("should return error for unsupported args", func() {
+			_, err := athenaClient.QueryRecords(ctx, sqlQuery, "a", make(chan int))
+			Expect(err).To(MatchError("invalid query arg 2: unsupported literal type: chan int"))
+		})
+
+		It("should pass args as execution parameters unless interpolated", func() {
+			athenaClient = New(api, Config{WaitInterval: time.Millisecond})
+			api.AddQuery("select 1 where x = ? and y = ?", athenafake.Query{Pages: []*types.ResultSet{{
+				ResultSetMetadata: &types.ResultSetMetadata{ColumnInfo: []types.ColumnInfo{{Name: util.RefString("_col0"), Type: util.RefString("integer")}}},
+			}}})
+
+			_, err := athenaClient.QueryMaps(ctx, "select 1 where x = ? and y = ?", "a", 1)
+			Expect(err).ToNot(HaveOccurred())
+			startInput, ok := api.StartInput("query-1")
+			Expect(ok).To(BeTrue())
+			Expect(*startInput.QueryString).To(Equal("select 1 where x = ? and y = ?"))
+			Expect(startInput.ExecutionParameters).To(Equal([]string{"'a'", "1"}))
+		})
+	})
+})
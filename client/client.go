@@ -0,0 +1,419 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/athena"
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv"
+	"github.com/kent-id/athenaconv/util"
+)
+
+const (
+	// MaxPageSize is the max number of rows per GetQueryResults call allowed by athena
+	MaxPageSize int32 = 1000
+
+	defaultWaitInterval = 1 * time.Second
+	csvResultPageSize   = 10000
+	stopQueryTimeout    = 10 * time.Second
+
+	// maxPollErrors is the max number of consecutive transient GetQueryExecution errors retried while waiting
+	maxPollErrors       = 5
+	maxPollErrorBackoff = 30 * time.Second
+)
+
+// AthenaAPI is the subset of the athena client from aws-sdk-go-v2 used by Client.
+// It is implemented by *athena.Client, and by athenafake.Fake for offline tests.
+type AthenaAPI interface {
+	StartQueryExecution(ctx context.Context, params *athena.StartQueryExecutionInput, optFns ...func(*athena.Options)) (*athena.StartQueryExecutionOutput, error)
+	GetQueryExecution(ctx context.Context, params *athena.GetQueryExecutionInput, optFns ...func(*athena.Options)) (*athena.GetQueryExecutionOutput, error)
+	GetQueryResults(ctx context.Context, params *athena.GetQueryResultsInput, optFns ...func(*athena.Options)) (*athena.GetQueryResultsOutput, error)
+	StopQueryExecution(ctx context.Context, params *athena.StopQueryExecutionInput, optFns ...func(*athena.Options)) (*athena.StopQueryExecutionOutput, error)
+}
+
+var _ AthenaAPI = (*athena.Client)(nil)
+
+// Config defines where and how queries are executed by Client
+type Config struct {
+	// WorkGroup in which the queries are executed
+	WorkGroup string
+	// Catalog of the query execution context, e.g. AwsDataCatalog
+	Catalog string
+	// Database of the query execution context
+	Database string
+	// OutputLocation is the S3 location of the query results, e.g. s3://bucket/path/, optional if defined by the workgroup
+	OutputLocation string
+	// PageSize is the max number of rows fetched per GetQueryResults call, defaults to MaxPageSize
+	PageSize int32
+	// WaitInterval between GetQueryExecution calls while the query is queued or running, defaults to 1 second.
+	// Ignored if PollStrategy is set.
+	WaitInterval time.Duration
+	// PollStrategy decides the wait between GetQueryExecution calls, defaults to FixedPollStrategy with WaitInterval
+	PollStrategy PollStrategy
+	// OnPollFinished is called with the polling metrics of every query execution once polling stops, optional
+	OnPollFinished func(metrics PollMetrics)
+	// RetryPolicy resubmits idempotent queries failing with transient errors, defaults to no retries
+	RetryPolicy *RetryPolicy
+	// PrefetchPages is the max number of result pages fetched in the background ahead of the page being converted or consumed,
+	// so that fetching and converting the results overlap. Defaults to 0, fetching the next page only once the current page is consumed.
+	PrefetchPages int
+	// ObjectFetcher fetches the CSV result file of the query from the output location once CSVResultThreshold rows are fetched, optional
+	ObjectFetcher ObjectFetcher
+	// CSVResultThreshold is the number of rows fetched with GetQueryResults, including the header row, before reading the remaining rows
+	// from the CSV result file with ObjectFetcher, which is much faster for large results.
+	// Defaults to 0, always fetching the results with GetQueryResults. Ignored if ObjectFetcher is nil.
+	CSVResultThreshold int
+	// InterpolateArgs formats the query args into the SQL query on the client instead of passing them as ExecutionParameters,
+	// e.g. for workgroups without support of execution parameters
+	InterpolateArgs bool
+	// QueryTimeout is the max duration of each query including fetching the results, applied in addition to the context deadline.
+	// Defaults to no timeout.
+	QueryTimeout time.Duration
+}
+
+// Client is a client to AWS Athena providing strongly-typed model binding using athenaconv mappers
+type Client struct {
+	api    AthenaAPI
+	config Config
+}
+
+// New creates new Client for the given athena API and configuration
+//
+// Example:
+//
+// athenaClient := client.New(athena.NewFromConfig(awsConfig), client.Config{WorkGroup: "primary", Database: "my_db"})
+func New(api AthenaAPI, config Config) *Client {
+	if config.PageSize <= 0 || config.PageSize > MaxPageSize {
+		config.PageSize = MaxPageSize
+	}
+	if config.WaitInterval <= 0 {
+		config.WaitInterval = defaultWaitInterval
+	}
+	if config.PollStrategy == nil {
+		config.PollStrategy = FixedPollStrategy{Interval: config.WaitInterval}
+	}
+	return &Client{
+		api:    api,
+		config: config,
+	}
+}
+
+// NewFromConfig creates new Client using athena client from aws-sdk-go-v2 created with the given aws.Config
+func NewFromConfig(awsConfig aws.Config, config Config) *Client {
+	return New(athena.NewFromConfig(awsConfig), config)
+}
+
+// Query executes the SQL query and converts the results into array of pointers to modelType, see athenaconv.NewMapperFor.
+// args are bound to the ? placeholders of the SQL query in order, athenaconv.MapperOption values in args configure the mapper instead.
+//
+// Example:
+//
+// result, err := athenaClient.Query(ctx, "select * from t where id = ? and day > ?", reflect.TypeOf(MyModel{}), 42, day)
+func (c *Client) Query(ctx context.Context, sqlQuery string, modelType reflect.Type, args ...interface{}) ([]interface{}, error) {
+	queryArgs, opts := splitArgs(args)
+	mapper, err := athenaconv.NewMapperFor(modelType, opts...)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := c.bindArgs(sqlQuery, queryArgs)
+	if err != nil {
+		return nil, err
+	}
+	return c.queryModels(ctx, stmt, mapper)
+}
+
+// queryModels executes the statement and converts the results with the mapper
+func (c *Client) queryModels(ctx context.Context, stmt statement, mapper athenaconv.DataMapper) ([]interface{}, error) {
+	result := make([]interface{}, 0)
+	err := c.query(ctx, stmt, func(resultSet *types.ResultSet) error {
+		mapped, err := mapper.FromAthenaResultSetV2(ctx, resultSet)
+		if err != nil {
+			return err
+		}
+		result = append(result, mapped...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// QueryInto executes the SQL query and appends the results into dest, which should be a pointer to slice of struct or slice of pointer to struct.
+// args are handled the same way as Query.
+//
+// Example:
+//
+// var models []MyModel
+// err := athenaClient.QueryInto(ctx, sql, &models)
+func (c *Client) QueryInto(ctx context.Context, sqlQuery string, dest interface{}, args ...interface{}) error {
+	return queryInto(dest, func(modelType reflect.Type) ([]interface{}, error) {
+		return c.Query(ctx, sqlQuery, modelType, args...)
+	})
+}
+
+// queryInto appends the results of query for the model type of dest into dest
+func queryInto(dest interface{}, query func(modelType reflect.Type) ([]interface{}, error)) error {
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Ptr || destValue.IsNil() || destValue.Elem().Kind() != reflect.Slice {
+		err := fmt.Errorf("%T is invalid dest, expecting pointer to slice", dest)
+		return err
+	}
+
+	sliceValue := destValue.Elem()
+	elemType := sliceValue.Type().Elem()
+	modelType := elemType
+	if elemType.Kind() == reflect.Ptr {
+		modelType = elemType.Elem()
+	}
+
+	result, err := query(modelType)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range result {
+		itemValue := reflect.ValueOf(item)
+		if elemType.Kind() != reflect.Ptr {
+			itemValue = itemValue.Elem()
+		}
+		sliceValue = reflect.Append(sliceValue, itemValue)
+	}
+	destValue.Elem().Set(sliceValue)
+	return nil
+}
+
+// QueryRecords executes the SQL query and converts the results into ordered records, see athenaconv.NewDynamicMapper.
+// args are handled the same way as Query.
+func (c *Client) QueryRecords(ctx context.Context, sqlQuery string, args ...interface{}) ([]*athenaconv.Record, error) {
+	queryArgs, opts := splitArgs(args)
+	mapper := athenaconv.NewDynamicMapper(opts...)
+	stmt, err := c.bindArgs(sqlQuery, queryArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*athenaconv.Record, 0)
+	err = c.query(ctx, stmt, func(resultSet *types.ResultSet) error {
+		records, err := mapper.RecordsFromAthenaResultSetV2(ctx, resultSet)
+		if err != nil {
+			return err
+		}
+		result = append(result, records...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// QueryMaps executes the SQL query and converts the results into maps of athena column name to value, see athenaconv.NewDynamicMapper.
+// args are handled the same way as Query.
+func (c *Client) QueryMaps(ctx context.Context, sqlQuery string, args ...interface{}) ([]map[string]interface{}, error) {
+	records, err := c.QueryRecords(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]map[string]interface{}, 0, len(records))
+	for _, record := range records {
+		result = append(result, record.Map())
+	}
+	return result, nil
+}
+
+// query executes the SQL query, waits until it finishes and calls handlePage for every page of the results.
+// The query execution is stopped if the context is done before it finishes.
+func (c *Client) query(ctx context.Context, stmt statement, handlePage func(resultSet *types.ResultSet) error) error {
+	if c.config.QueryTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.config.QueryTimeout)
+		defer cancel()
+	}
+
+	// 1. start query and wait until query finishes, resubmitting transient failures according to the retry policy
+	queryExecutionID, err := c.executeWithRetry(ctx, stmt)
+	if err != nil {
+		return err
+	}
+
+	// 2. finally if query is successful, get the query results output page by page
+	err = c.getQueryResults(ctx, queryExecutionID, handlePage)
+	if err != nil {
+		return fetchContextError(ctx, queryExecutionID, err)
+	}
+	return nil
+}
+
+// execute starts the query and waits until it finishes, returns the query execution ID if the query was started
+func (c *Client) execute(ctx context.Context, stmt statement) (string, error) {
+	queryExecutionID, err := c.startQueryExecution(ctx, stmt)
+	if err != nil {
+		return "", err
+	}
+
+	queryExecution, err := c.waitQueryExecution(ctx, queryExecutionID)
+	if err != nil {
+		return queryExecutionID, c.stopOnWaitError(ctx, queryExecutionID, err)
+	}
+	if queryExecution.Status.State != types.QueryExecutionStateSucceeded {
+		err := newQueryFailedError(stmt.sql, queryExecution)
+		return queryExecutionID, err
+	}
+	return queryExecutionID, nil
+}
+
+// stopOnWaitError stops the query execution after waiting for it failed, so that it is not left running.
+// Returns QueryContextError if the context is done, otherwise QueryPollError.
+// It should only be called while the query execution is queued or running.
+func (c *Client) stopOnWaitError(ctx context.Context, queryExecutionID string, err error) error {
+	// the query context may already be done, use a separate context so that the query is stopped regardless
+	stopCtx, cancel := context.WithTimeout(context.Background(), stopQueryTimeout)
+	defer cancel()
+	_, stopErr := c.api.StopQueryExecution(stopCtx, &athena.StopQueryExecutionInput{
+		QueryExecutionId: util.RefString(queryExecutionID),
+	})
+	if ctx.Err() != nil {
+		return &QueryContextError{
+			QueryExecutionID: queryExecutionID,
+			Err:              ctx.Err(),
+			Stopped:          stopErr == nil,
+			StopErr:          stopErr,
+		}
+	}
+	return &QueryPollError{
+		QueryExecutionID: queryExecutionID,
+		Err:              err,
+		Stopped:          stopErr == nil,
+		StopErr:          stopErr,
+	}
+}
+
+// fetchContextError returns QueryContextError without stopping the succeeded query execution if the context is done
+// while its results are fetched, otherwise returns err as is
+func fetchContextError(ctx context.Context, queryExecutionID string, err error) error {
+	if ctx.Err() == nil {
+		return err
+	}
+	return &QueryContextError{
+		QueryExecutionID: queryExecutionID,
+		Err:              ctx.Err(),
+	}
+}
+
+func (c *Client) startQueryExecution(ctx context.Context, stmt statement) (string, error) {
+	startQueryExecInput := athena.StartQueryExecutionInput{
+		QueryExecutionContext: &types.QueryExecutionContext{
+			Database: util.RefString(c.config.Database),
+			Catalog:  util.RefString(c.config.Catalog),
+		},
+		QueryString: util.RefString(stmt.sql),
+	}
+	if len(stmt.executionParameters) > 0 {
+		startQueryExecInput.ExecutionParameters = stmt.executionParameters
+	}
+	if c.config.WorkGroup != "" {
+		startQueryExecInput.WorkGroup = util.RefString(c.config.WorkGroup)
+	}
+	if c.config.OutputLocation != "" {
+		startQueryExecInput.ResultConfiguration = &types.ResultConfiguration{
+			OutputLocation: util.RefString(c.config.OutputLocation),
+		}
+	}
+
+	startQueryExecOutput, err := c.api.StartQueryExecution(ctx, &startQueryExecInput)
+	if err != nil {
+		return "", err
+	}
+	return util.SafeString(startQueryExecOutput.QueryExecutionId), nil
+}
+
+// waitQueryExecution polls the query execution until it is neither queued nor running
+func (c *Client) waitQueryExecution(ctx context.Context, queryExecutionID string) (*types.QueryExecution, error) {
+	queryExecInput := athena.GetQueryExecutionInput{
+		QueryExecutionId: util.RefString(queryExecutionID),
+	}
+
+	metrics := PollMetrics{QueryExecutionID: queryExecutionID}
+	started := time.Now()
+	defer func() {
+		if c.config.OnPollFinished != nil {
+			metrics.Elapsed = time.Since(started)
+			c.config.OnPollFinished(metrics)
+		}
+	}()
+
+	pollErrors := 0
+	for attempt := 1; ; attempt++ {
+		queryExecOutput, err := c.api.GetQueryExecution(ctx, &queryExecInput)
+		metrics.Polls++
+		if err != nil {
+			if pollErrors >= maxPollErrors || !IsTransientError(err) {
+				return nil, err
+			}
+			// the query execution is still known, poll it again instead of giving up on it
+			pollErrors++
+			backoff := ExponentialPollStrategy{Initial: c.config.WaitInterval, Max: maxPollErrorBackoff}.NextInterval(pollErrors, nil)
+			metrics.TotalWait += backoff
+			if err := sleepContext(ctx, backoff); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		pollErrors = 0
+		if queryExecOutput.QueryExecution == nil || queryExecOutput.QueryExecution.Status == nil {
+			err := fmt.Errorf("query execution %s returned no status", queryExecutionID)
+			return nil, err
+		}
+		metrics.QueueTime, metrics.EngineExecutionTime = executionTimes(queryExecOutput.QueryExecution)
+
+		state := queryExecOutput.QueryExecution.Status.State
+		if state != types.QueryExecutionStateRunning && state != types.QueryExecutionStateQueued {
+			metrics.FinalState = state
+			return queryExecOutput.QueryExecution, nil
+		}
+
+		interval := c.config.PollStrategy.NextInterval(attempt, queryExecOutput.QueryExecution)
+		metrics.TotalWait += interval
+		if err := sleepContext(ctx, interval); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// sleepContext pauses for the given duration, returns the context error if the context is done first
+func sleepContext(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (c *Client) getQueryResults(ctx context.Context, queryExecutionID string, handlePage func(resultSet *types.ResultSet) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pager := c.newResultPager(ctx, queryExecutionID)
+	defer pager.close()
+	for !pager.done {
+		resultSet, err := pager.next(ctx)
+		if err != nil {
+			return err
+		}
+		if resultSet != nil {
+			if err := handlePage(resultSet); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,268 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena"
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv/athenafake"
+	"github.com/kent-id/athenaconv/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ AthenaAPI = (*athenafake.Fake)(nil)
+
+const testSQL = "select id, name from t"
+
+type testModel struct {
+	ID   int    `athenaconv:"id"`
+	Name string `athenaconv:"name"`
+}
+
+// cancellingAPI cancels the context of the query when its results are fetched
+type cancellingAPI struct {
+	*athenafake.Fake
+	cancel context.CancelFunc
+}
+
+func (a *cancellingAPI) GetQueryResults(ctx context.Context, params *athena.GetQueryResultsInput, optFns ...func(*athena.Options)) (*athena.GetQueryResultsOutput, error) {
+	a.cancel()
+	return nil, ctx.Err()
+}
+
+// newTestPages returns pages of id and name rows, the first page contains the header row
+func newTestPages(pageCount, rowsPerPage int) []*types.ResultSet {
+	metadata := &types.ResultSetMetadata{
+		ColumnInfo: []types.ColumnInfo{
+			{Name: util.RefString("id"), Type: util.RefString("integer")},
+			{Name: util.RefString("name"), Type: util.RefString("varchar")},
+		},
+	}
+
+	pages := make([]*types.ResultSet, 0, pageCount)
+	id := 0
+	for p := 0; p < pageCount; p++ {
+		page := &types.ResultSet{ResultSetMetadata: metadata}
+		if p == 0 {
+			page.Rows = append(page.Rows, types.Row{Data: []types.Datum{{VarCharValue: util.RefString("id")}, {VarCharValue: util.RefString("name")}}})
+		}
+		for r := 0; r < rowsPerPage; r++ {
+			page.Rows = append(page.Rows, types.Row{Data: []types.Datum{
+				{VarCharValue: util.RefString(strconv.Itoa(id))},
+				{VarCharValue: util.RefString("name " + strconv.Itoa(id))},
+			}})
+			id++
+		}
+		pages = append(pages, page)
+	}
+	return pages
+}
+
+var _ = Describe("Client", func() {
+	var ctx context.Context
+	var api *athenafake.Fake
+	var athenaClient *Client
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		api = athenafake.New()
+		api.AddQuery(testSQL, athenafake.Query{
+			Pages: newTestPages(3, 5),
+		})
+		athenaClient = New(api, Config{
+			WorkGroup:      "primary",
+			Catalog:        "AwsDataCatalog",
+			Database:       "my_db",
+			OutputLocation: "s3://bucket/results/",
+			PageSize:       5,
+			WaitInterval:   time.Millisecond,
+		})
+	})
+
+	Context("New", func() {
+		It("should default page size and wait interval", func() {
+			c := New(api, Config{PageSize: 5000})
+			Expect(c.config.PageSize).To(Equal(MaxPageSize))
+			Expect(c.config.WaitInterval).To(Equal(defaultWaitInterval))
+			Expect(c.config.PollStrategy).To(Equal(FixedPollStrategy{Interval: defaultWaitInterval}))
+		})
+	})
+
+	Context("Query", func() {
+		When("query succeeds", func() {
+			It("should start query with configuration and map all pages", func() {
+				result, err := athenaClient.Query(ctx, testSQL, reflect.TypeOf(testModel{}))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(result)).To(Equal(15))
+				for i, item := range result {
+					Expect(item).To(Equal(&testModel{ID: i, Name: "name " + strconv.Itoa(i)}))
+				}
+
+				Expect(api.Executions()).To(Equal([]string{"query-1"}))
+				startInput, ok := api.StartInput("query-1")
+				Expect(ok).To(BeTrue())
+				Expect(*startInput.QueryString).To(Equal(testSQL))
+				Expect(*startInput.WorkGroup).To(Equal("primary"))
+				Expect(*startInput.QueryExecutionContext.Catalog).To(Equal("AwsDataCatalog"))
+				Expect(*startInput.QueryExecutionContext.Database).To(Equal("my_db"))
+				Expect(*startInput.ResultConfiguration.OutputLocation).To(Equal("s3://bucket/results/"))
+
+				Expect(api.Calls(athenafake.OpGetQueryExecution)).To(Equal(3))
+				Expect(api.Calls(athenafake.OpGetQueryResults)).To(Equal(3))
+			})
+		})
+
+		When("query fails", func() {
+			It("should return error with state", func() {
+				api.AddQuery("select fail", athenafake.Query{
+					States: []types.QueryExecutionState{types.QueryExecutionStateRunning, types.QueryExecutionStateFailed},
+				})
+				_, err := athenaClient.Query(ctx, "select fail", reflect.TypeOf(testModel{}))
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("query-1 failed with status: FAILED"))
+				Expect(api.Calls(athenafake.OpGetQueryResults)).To(Equal(0))
+			})
+		})
+
+		When("start query returns error", func() {
+			It("should return error", func() {
+				api.FailNext(athenafake.OpStartQueryExecution, errors.New("access denied"))
+				_, err := athenaClient.Query(ctx, testSQL, reflect.TypeOf(testModel{}))
+				Expect(err).To(MatchError("access denied"))
+			})
+		})
+
+		When("get query results returns error", func() {
+			It("should return error", func() {
+				api.FailNext(athenafake.OpGetQueryResults, errors.New("throttled"))
+				_, err := athenaClient.Query(ctx, testSQL, reflect.TypeOf(testModel{}))
+				Expect(err).To(MatchError("throttled"))
+			})
+		})
+
+		When("context is cancelled while query is running", func() {
+			It("should stop query execution and return context error with query execution ID", func() {
+				api.AddQuery("select slow", athenafake.Query{
+					States: []types.QueryExecutionState{types.QueryExecutionStateRunning},
+				})
+				timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+				defer cancel()
+
+				_, err := athenaClient.Query(timeoutCtx, "select slow", reflect.TypeOf(testModel{}))
+				Expect(err).To(HaveOccurred())
+				Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+				var contextErr *QueryContextError
+				Expect(errors.As(err, &contextErr)).To(BeTrue())
+				Expect(contextErr.QueryExecutionID).To(Equal("query-1"))
+				Expect(contextErr.StopErr).ToNot(HaveOccurred())
+				Expect(contextErr.Stopped).To(BeTrue())
+				Expect(err.Error()).To(ContainSubstring("query execution query-1 stopped"))
+				Expect(api.Stopped("query-1")).To(BeTrue())
+			})
+		})
+
+		When("context is cancelled while results are fetched", func() {
+			It("should return context error without stopping the succeeded query", func() {
+				cancelCtx, cancel := context.WithCancel(ctx)
+				defer cancel()
+				athenaClient = New(&cancellingAPI{Fake: api, cancel: cancel}, Config{WaitInterval: time.Millisecond})
+
+				_, err := athenaClient.Query(cancelCtx, testSQL, reflect.TypeOf(testModel{}))
+				Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+				var contextErr *QueryContextError
+				Expect(errors.As(err, &contextErr)).To(BeTrue())
+				Expect(contextErr.QueryExecutionID).To(Equal("query-1"))
+				Expect(contextErr.Stopped).To(BeFalse())
+				Expect(err).To(MatchError("query execution query-1 succeeded, fetching results interrupted: context canceled"))
+				Expect(api.Calls(athenafake.OpStopQueryExecution)).To(Equal(0))
+			})
+		})
+
+		When("query timeout is exceeded", func() {
+			It("should stop query execution regardless of caller context", func() {
+				api.AddQuery("select slow", athenafake.Query{
+					States: []types.QueryExecutionState{types.QueryExecutionStateQueued},
+				})
+				athenaClient = New(api, Config{
+					WaitInterval: time.Millisecond,
+					QueryTimeout: 20 * time.Millisecond,
+				})
+
+				_, err := athenaClient.Query(ctx, "select slow", reflect.TypeOf(testModel{}))
+				Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+				Expect(api.Stopped("query-1")).To(BeTrue())
+			})
+		})
+
+		When("stopping query execution fails", func() {
+			It("should return context error with stop error", func() {
+				api.AddQuery("select slow", athenafake.Query{
+					States: []types.QueryExecutionState{types.QueryExecutionStateRunning},
+				})
+				api.FailNext(athenafake.OpStopQueryExecution, errors.New("stop failed"))
+				cancelledCtx, cancel := context.WithCancel(ctx)
+				cancel()
+
+				_, err := athenaClient.Query(cancelledCtx, "select slow", reflect.TypeOf(testModel{}))
+				Expect(errors.Is(err, context.Canceled)).To(BeTrue())
+				var contextErr *QueryContextError
+				Expect(errors.As(err, &contextErr)).To(BeTrue())
+				Expect(contextErr.StopErr).To(MatchError("stop failed"))
+			})
+		})
+
+		When("model type is invalid", func() {
+			It("should return error before starting query", func() {
+				_, err := athenaClient.Query(ctx, testSQL, reflect.TypeOf(&testModel{}))
+				Expect(err).To(HaveOccurred())
+				Expect(strings.ToLower(err.Error())).To(ContainSubstring("invalid modeltype"))
+				Expect(api.Calls(athenafake.OpStartQueryExecution)).To(Equal(0))
+			})
+		})
+	})
+
+	Context("QueryInto", func() {
+		It("should append results into slice of struct", func() {
+			var result []testModel
+			err := athenaClient.QueryInto(ctx, testSQL, &result)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(result)).To(Equal(15))
+			Expect(result[14]).To(Equal(testModel{ID: 14, Name: "name 14"}))
+		})
+
+		It("should append results into slice of pointer to struct", func() {
+			var result []*testModel
+			err := athenaClient.QueryInto(ctx, testSQL, &result)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(result)).To(Equal(15))
+			Expect(result[0]).To(Equal(&testModel{ID: 0, Name: "name 0"}))
+		})
+
+		It("should return error if dest is not a pointer to slice", func() {
+			var result []testModel
+			err := athenaClient.QueryInto(ctx, testSQL, result)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("invalid dest"))
+		})
+	})
+
+	Context("QueryRecords and QueryMaps", func() {
+		It("should convert results without model", func() {
+			records, err := athenaClient.QueryRecords(ctx, testSQL)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(records)).To(Equal(15))
+			Expect(records[3].Values).To(Equal([]interface{}{3, "name 3"}))
+
+			maps, err := athenaClient.QueryMaps(ctx, testSQL)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(maps)).To(Equal(15))
+			Expect(maps[4]).To(Equal(map[string]interface{}{"id": 4, "name": "name 4"}))
+		})
+	})
+})
@@ -0,0 +1,133 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv/util"
+)
+
+// QueryContextError is returned when the context of a query is cancelled or its deadline is exceeded.
+// The query execution is stopped if it is still queued or running, StopErr is set if stopping failed.
+// It is not stopped if the context is done while the results of the succeeded query are fetched.
+// Err is context.Canceled or context.DeadlineExceeded, so errors.Is can be used on QueryContextError.
+type QueryContextError struct {
+	QueryExecutionID string
+	Err              error
+	// Stopped is true if the query execution was stopped
+	Stopped bool
+	StopErr error
+}
+
+func (e *QueryContextError) Error() string {
+	switch {
+	case e.StopErr != nil:
+		return fmt.Sprintf("query execution %s interrupted: %v, failed to stop query execution: %v", e.QueryExecutionID, e.Err, e.StopErr)
+	case e.Stopped:
+		return fmt.Sprintf("query execution %s stopped: %v", e.QueryExecutionID, e.Err)
+	}
+	return fmt.Sprintf("query execution %s succeeded, fetching results interrupted: %v", e.QueryExecutionID, e.Err)
+}
+
+// Unwrap returns the context error
+func (e *QueryContextError) Unwrap() error {
+	return e.Err
+}
+
+// QueryPollError is returned when polling the query execution fails with a non transient error,
+// or with transient errors more often than retried. The query execution is stopped, StopErr is set if stopping failed.
+type QueryPollError struct {
+	QueryExecutionID string
+	Err              error
+	// Stopped is true if the query execution was stopped
+	Stopped bool
+	StopErr error
+}
+
+func (e *QueryPollError) Error() string {
+	if e.StopErr != nil {
+		return fmt.Sprintf("polling query execution %s failed: %v, failed to stop query execution: %v", e.QueryExecutionID, e.Err, e.StopErr)
+	}
+	return fmt.Sprintf("polling query execution %s failed: %v, query execution stopped", e.QueryExecutionID, e.Err)
+}
+
+// Unwrap returns the error of the last poll
+func (e *QueryPollError) Unwrap() error {
+	return e.Err
+}
+
+// QueryFailedError is returned when the query execution finishes in a state other than SUCCEEDED, i.e. FAILED or CANCELLED.
+// AthenaErrorCategory and AthenaErrorType are set from the AthenaError of the query status when reported,
+// see https://docs.aws.amazon.com/athena/latest/APIReference/API_AthenaError.html for their values.
+type QueryFailedError struct {
+	QueryExecutionID    string
+	State               types.QueryExecutionState
+	StateChangeReason   string
+	AthenaErrorCategory int32
+	AthenaErrorType     int32
+	SubmissionDateTime  time.Time
+	CompletionDateTime  time.Time
+	Statistics          *types.QueryExecutionStatistics
+	Query               string
+}
+
+func newQueryFailedError(sqlQuery string, queryExecution *types.QueryExecution) *QueryFailedError {
+	err := &QueryFailedError{
+		QueryExecutionID: util.SafeString(queryExecution.QueryExecutionId),
+		Statistics:       queryExecution.Statistics,
+		Query:            sqlQuery,
+	}
+	if queryExecution.Query != nil {
+		err.Query = *queryExecution.Query
+	}
+	if status := queryExecution.Status; status != nil {
+		err.State = status.State
+		err.StateChangeReason = util.SafeString(status.StateChangeReason)
+		err.AthenaErrorCategory, err.AthenaErrorType = athenaErrorOf(status)
+		if status.SubmissionDateTime != nil {
+			err.SubmissionDateTime = *status.SubmissionDateTime
+		}
+		if status.CompletionDateTime != nil {
+			err.CompletionDateTime = *status.CompletionDateTime
+		}
+	}
+	return err
+}
+
+func (e *QueryFailedError) Error() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "query execution %s failed with status: %s", e.QueryExecutionID, e.State)
+	if e.StateChangeReason != "" {
+		fmt.Fprintf(&builder, ", reason: %s", e.StateChangeReason)
+	}
+	if e.AthenaErrorCategory != 0 {
+		fmt.Fprintf(&builder, ", athena error category: %d, type: %d", e.AthenaErrorCategory, e.AthenaErrorType)
+	}
+	return builder.String()
+}
+
+// athenaErrorOf returns the AthenaError category and type of the query status, zero if not reported
+func athenaErrorOf(status *types.QueryExecutionStatus) (int32, int32) {
+	if status.AthenaError == nil {
+		return 0, 0
+	}
+	return util.SafeInt32(status.AthenaError.ErrorCategory), util.SafeInt32(status.AthenaError.ErrorType)
+}
+
+// RetryError is returned when a query failed after being resubmitted at least once by the RetryPolicy.
+// QueryExecutionIDs contains the ID of every attempt in order, Err is the error of the last attempt.
+type RetryError struct {
+	QueryExecutionIDs []string
+	Err               error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("query failed after %d attempts, query execution IDs: [%s]: %v", len(e.QueryExecutionIDs), strings.Join(e.QueryExecutionIDs, ", "), e.Err)
+}
+
+// Unwrap returns the error of the last attempt
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
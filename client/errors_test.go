@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv/athenafake"
+	"github.com/kent-id/athenaconv/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Errors", func() {
+	Context("QueryFailedError", func() {
+		It("should expose the query execution failure through errors.As", func() {
+			// arrange
+			api := athenafake.New()
+			api.AddQuery("select fail", athenafake.Query{
+				States:            []types.QueryExecutionState{types.QueryExecutionStateRunning, types.QueryExecutionStateFailed},
+				StateChangeReason: "SYNTAX_ERROR: line 1:8: Column 'fail' cannot be resolved",
+				Statistics:        &types.QueryExecutionStatistics{EngineExecutionTimeInMillis: util.RefInt64(250)},
+			})
+			athenaClient := New(api, Config{WaitInterval: time.Millisecond})
+
+			// act
+			_, err := athenaClient.Query(context.Background(), "select fail", reflect.TypeOf(testModel{}))
+
+			// assert
+			var failedErr *QueryFailedError
+			Expect(errors.As(err, &failedErr)).To(BeTrue())
+			Expect(failedErr.QueryExecutionID).To(Equal("query-1"))
+			Expect(failedErr.State).To(Equal(types.QueryExecutionStateFailed))
+			Expect(failedErr.StateChangeReason).To(HavePrefix("SYNTAX_ERROR"))
+			Expect(failedErr.Query).To(Equal("select fail"))
+			Expect(failedErr.SubmissionDateTime.IsZero()).To(BeFalse())
+			Expect(failedErr.CompletionDateTime.Before(failedErr.SubmissionDateTime)).To(BeFalse())
+			Expect(*failedErr.Statistics.EngineExecutionTimeInMillis).To(Equal(int64(250)))
+			Expect(failedErr.AthenaErrorCategory).To(BeZero())
+			Expect(err.Error()).To(Equal("query execution query-1 failed with status: FAILED, reason: SYNTAX_ERROR: line 1:8: Column 'fail' cannot be resolved"))
+		})
+
+		It("should format athena error category and type when set", func() {
+			err := &QueryFailedError{
+				QueryExecutionID:    "query-1",
+				State:               types.QueryExecutionStateFailed,
+				AthenaErrorCategory: 2,
+				AthenaErrorType:     1301,
+			}
+			Expect(err.Error()).To(Equal("query execution query-1 failed with status: FAILED, athena error category: 2, type: 1301"))
+		})
+	})
+
+	Context("athenaErrorOf", func() {
+		It("should read AthenaError when the status reports it", func() {
+			status := &types.QueryExecutionStatus{
+				AthenaError: &types.AthenaError{ErrorCategory: util.RefInt32(1), ErrorType: util.RefInt32(401)},
+			}
+
+			category, errorType := athenaErrorOf(status)
+			Expect(category).To(Equal(int32(1)))
+			Expect(errorType).To(Equal(int32(401)))
+		})
+
+		It("should return zero when the status does not report it", func() {
+			category, errorType := athenaErrorOf(&types.QueryExecutionStatus{})
+			Expect(category).To(BeZero())
+			Expect(errorType).To(BeZero())
+			category, _ = athenaErrorOf(&types.QueryExecutionStatus{AthenaError: &types.AthenaError{}})
+			Expect(category).To(BeZero())
+		})
+	})
+})
@@ -0,0 +1,226 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/kent-id/athenaconv"
+)
+
+// RowIterator streams the results of a query row by row, fetching the next page from athena once the current page is consumed,
+// or in the background while the current page is consumed if Config.PrefetchPages is set.
+// RowIterator is not safe for concurrent use, Close should be called to stop prefetching when the rows are not read until the end,
+// prefetching also stops once the context passed to QueryIterator is done.
+//
+// Example:
+//
+//	rows, err := athenaClient.QueryIterator(ctx, sql, reflect.TypeOf(MyModel{}))
+//	defer rows.Close()
+//	for rows.Next(ctx) {
+//		var model MyModel
+//		err := rows.Scan(&model)
+//	}
+//	err = rows.Err()
+type RowIterator struct {
+	client           *Client
+	mapper           athenaconv.DataMapper
+	modelType        reflect.Type
+	queryExecutionID string
+	deadline         time.Time
+	pager            *resultPager
+	cancelPager      context.CancelFunc
+	rows             []interface{}
+	index            int
+	err              error
+	closed           bool
+}
+
+// QueryIterator executes the SQL query, waits until it finishes and returns RowIterator over the results converted into modelType,
+// see athenaconv.NewMapperFor. Pages of the results are only fetched when iterating. args are handled the same way as Query.
+func (c *Client) QueryIterator(ctx context.Context, sqlQuery string, modelType reflect.Type, args ...interface{}) (*RowIterator, error) {
+	queryArgs, opts := splitArgs(args)
+	mapper, err := athenaconv.NewMapperFor(modelType, opts...)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := c.bindArgs(sqlQuery, queryArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	var deadline time.Time
+	if c.config.QueryTimeout > 0 {
+		deadline = time.Now().Add(c.config.QueryTimeout)
+	}
+	executeCtx, cancel := withDeadline(ctx, deadline)
+	defer cancel()
+
+	queryExecutionID, err := c.executeWithRetry(executeCtx, stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	// pages may be prefetched in between calls to Next, the pager is bound to the context of the query instead of the context of Next
+	pagerCtx, cancelPager := withDeadline(ctx, deadline)
+	return &RowIterator{
+		client:           c,
+		mapper:           mapper,
+		modelType:        modelType,
+		queryExecutionID: queryExecutionID,
+		deadline:         deadline,
+		pager:            c.newResultPager(pagerCtx, queryExecutionID),
+		cancelPager:      cancelPager,
+		index:            -1,
+	}, nil
+}
+
+// QueryExecutionID returns the ID of the query execution the results are fetched from
+func (it *RowIterator) QueryExecutionID() string {
+	return it.queryExecutionID
+}
+
+// Next advances to the next row, fetching the next page of the results if needed.
+// Returns false when there are no more rows, when an error occurs or when the iterator is closed, see Err.
+func (it *RowIterator) Next(ctx context.Context) bool {
+	if it.err != nil || it.closed {
+		return false
+	}
+
+	it.index++
+	for it.index >= len(it.rows) {
+		if it.pager.done {
+			it.rows = nil
+			return false
+		}
+		if err := it.fetchPage(ctx); err != nil {
+			it.err = err
+			it.rows = nil
+			it.cancelPager()
+			it.pager.close()
+			return false
+		}
+	}
+	return true
+}
+
+func (it *RowIterator) fetchPage(ctx context.Context) error {
+	ctx, cancel := withDeadline(ctx, it.deadline)
+	defer cancel()
+
+	if err := ctx.Err(); err != nil {
+		return fetchContextError(ctx, it.queryExecutionID, err)
+	}
+	resultSet, err := it.pager.next(ctx)
+	if err != nil {
+		return fetchContextError(ctx, it.queryExecutionID, err)
+	}
+
+	it.rows = nil
+	it.index = 0
+	if resultSet == nil {
+		return nil
+	}
+	mapped, err := it.mapper.FromAthenaResultSetV2(ctx, resultSet)
+	if err != nil {
+		return err
+	}
+	it.rows = mapped
+	return nil
+}
+
+// Scan copies the current row into dest, which should be a pointer to the model type or a pointer to pointer to the model type
+func (it *RowIterator) Scan(dest interface{}) error {
+	if it.closed {
+		err := errors.New("row iterator is closed")
+		return err
+	}
+	if it.index < 0 || it.index >= len(it.rows) {
+		err := errors.New("no current row, Next should return true before calling Scan")
+		return err
+	}
+
+	destValue := reflect.ValueOf(dest)
+	if destValue.Kind() != reflect.Ptr || destValue.IsNil() {
+		err := fmt.Errorf("%T is invalid dest, expecting pointer to %v", dest, it.modelType)
+		return err
+	}
+
+	row := reflect.ValueOf(it.rows[it.index])
+	switch destValue.Elem().Type() {
+	case it.modelType:
+		destValue.Elem().Set(row.Elem())
+	case row.Type():
+		destValue.Elem().Set(row)
+	default:
+		err := fmt.Errorf("%T is invalid dest, expecting pointer to %v", dest, it.modelType)
+		return err
+	}
+	return nil
+}
+
+// Err returns the error that stopped the iteration, nil if all rows were read successfully
+func (it *RowIterator) Err() error {
+	return it.err
+}
+
+// Close stops the iteration, releases the current page and stops prefetching, it is safe to call Close multiple times.
+// The query execution has already finished, so closing the iterator early does not stop it.
+func (it *RowIterator) Close() error {
+	it.closed = true
+	it.rows = nil
+	it.cancelPager()
+	it.pager.close()
+	return nil
+}
+
+// QueryChannel executes the SQL query and streams the results converted into modelType to the rows channel as pointers to modelType.
+// args are handled the same way as Query. Both channels are always closed once the query finishes, fails or the context is done, the errors channel receives at most one error.
+// Rows should be read until the channel is closed, or the context cancelled, before reading the error.
+//
+// Example:
+//
+//	rows, errs := athenaClient.QueryChannel(ctx, sql, reflect.TypeOf(MyModel{}))
+//	for row := range rows {
+//		model := row.(*MyModel)
+//	}
+//	err := <-errs
+func (c *Client) QueryChannel(ctx context.Context, sqlQuery string, modelType reflect.Type, args ...interface{}) (<-chan interface{}, <-chan error) {
+	rowsChan := make(chan interface{})
+	errorsChan := make(chan error, 1)
+
+	go func() {
+		defer close(errorsChan)
+		defer close(rowsChan)
+
+		iterator, err := c.QueryIterator(ctx, sqlQuery, modelType, args...)
+		if err != nil {
+			errorsChan <- err
+			return
+		}
+		defer iterator.Close()
+
+		for iterator.Next(ctx) {
+			select {
+			case rowsChan <- iterator.rows[iterator.index]:
+			case <-ctx.Done():
+				errorsChan <- ctx.Err()
+				return
+			}
+		}
+		if err := iterator.Err(); err != nil {
+			errorsChan <- err
+		}
+	}()
+	return rowsChan, errorsChan
+}
+
+// withDeadline returns the context with the deadline applied, the context is returned as is if the deadline is zero
+func withDeadline(ctx context.Context, deadline time.Time) (context.Context, context.CancelFunc) {
+	if deadline.IsZero() {
+		return ctx, func() {}
+	}
+	return context.WithDeadline(ctx, deadline)
+}
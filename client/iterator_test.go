@@ -0,0 +1,225 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv/athenafake"
+	"github.com/kent-id/athenaconv/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Iterator", func() {
+	var ctx context.Context
+	var api *athenafake.Fake
+	var athenaClient *Client
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		api = athenafake.New()
+		api.AddQuery(testSQL, athenafake.Query{
+			Pages: newTestPages(3, 5),
+		})
+		athenaClient = New(api, Config{
+			PageSize:     5,
+			WaitInterval: time.Millisecond,
+		})
+	})
+
+	Context("QueryIterator", func() {
+		When("query succeeds", func() {
+			It("should stream all rows fetching pages lazily", func() {
+				rows, err := athenaClient.QueryIterator(ctx, testSQL, reflect.TypeOf(testModel{}))
+				Expect(err).ToNot(HaveOccurred())
+				defer rows.Close()
+				Expect(rows.QueryExecutionID()).To(Equal("query-1"))
+				Expect(api.Calls(athenafake.OpGetQueryResults)).To(Equal(0))
+
+				result := make([]testModel, 0)
+				for rows.Next(ctx) {
+					var model testModel
+					Expect(rows.Scan(&model)).To(Succeed())
+					result = append(result, model)
+					Expect(api.Calls(athenafake.OpGetQueryResults)).To(Equal((len(result) + 4) / 5))
+				}
+				Expect(rows.Err()).ToNot(HaveOccurred())
+				Expect(len(result)).To(Equal(15))
+				Expect(result[0]).To(Equal(testModel{ID: 0, Name: "name 0"}))
+				Expect(result[14]).To(Equal(testModel{ID: 14, Name: "name 14"}))
+				Expect(rows.Next(ctx)).To(BeFalse())
+			})
+
+			It("should scan into pointer to pointer", func() {
+				rows, err := athenaClient.QueryIterator(ctx, testSQL, reflect.TypeOf(testModel{}))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rows.Next(ctx)).To(BeTrue())
+
+				var model *testModel
+				Expect(rows.Scan(&model)).To(Succeed())
+				Expect(model.Name).To(Equal("name 0"))
+			})
+
+			It("should skip empty pages", func() {
+				pages := newTestPages(2, 1)
+				api.AddQuery("select empty", athenafake.Query{
+					Pages: []*types.ResultSet{pages[0], {ResultSetMetadata: pages[0].ResultSetMetadata}, pages[1]},
+				})
+				rows, err := athenaClient.QueryIterator(ctx, "select empty", reflect.TypeOf(testModel{}))
+				Expect(err).ToNot(HaveOccurred())
+
+				count := 0
+				for rows.Next(ctx) {
+					count++
+				}
+				Expect(rows.Err()).ToNot(HaveOccurred())
+				Expect(count).To(Equal(2))
+			})
+		})
+
+		When("Scan is called with invalid state or dest", func() {
+			It("should return error", func() {
+				rows, err := athenaClient.QueryIterator(ctx, testSQL, reflect.TypeOf(testModel{}))
+				Expect(err).ToNot(HaveOccurred())
+
+				var model testModel
+				err = rows.Scan(&model)
+				Expect(strings.ToLower(err.Error())).To(ContainSubstring("no current row"))
+
+				Expect(rows.Next(ctx)).To(BeTrue())
+				var name string
+				err = rows.Scan(&name)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("invalid dest"))
+				err = rows.Scan(model)
+				Expect(err).To(HaveOccurred())
+
+				Expect(rows.Close()).To(Succeed())
+				Expect(rows.Close()).To(Succeed())
+				Expect(rows.Next(ctx)).To(BeFalse())
+				err = rows.Scan(&model)
+				Expect(strings.ToLower(err.Error())).To(ContainSubstring("closed"))
+			})
+		})
+
+		When("query fails", func() {
+			It("should return error before iterating", func() {
+				api.AddQuery("select fail", athenafake.Query{
+					States: []types.QueryExecutionState{types.QueryExecutionStateFailed},
+				})
+				_, err := athenaClient.QueryIterator(ctx, "select fail", reflect.TypeOf(testModel{}))
+				var failedErr *QueryFailedError
+				Expect(errors.As(err, &failedErr)).To(BeTrue())
+			})
+		})
+
+		When("fetching a page fails", func() {
+			It("should stop iterating and return the error", func() {
+				rows, err := athenaClient.QueryIterator(ctx, testSQL, reflect.TypeOf(testModel{}))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(rows.Next(ctx)).To(BeTrue())
+				api.FailNext(athenafake.OpGetQueryResults, errors.New("throttled"))
+
+				count := 1
+				for rows.Next(ctx) {
+					count++
+				}
+				Expect(count).To(Equal(5))
+				Expect(rows.Err()).To(MatchError("throttled"))
+			})
+		})
+
+		When("mapping a page fails", func() {
+			It("should stop iterating and return the error", func() {
+				pages := newTestPages(2, 1)
+				pages[1].Rows[0].Data[0].VarCharValue = util.RefString("invalid")
+				api.AddQuery("select invalid", athenafake.Query{Pages: pages})
+				rows, err := athenaClient.QueryIterator(ctx, "select invalid", reflect.TypeOf(testModel{}))
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(rows.Next(ctx)).To(BeTrue())
+				Expect(rows.Next(ctx)).To(BeFalse())
+				Expect(strings.ToLower(rows.Err().Error())).To(MatchRegexp("parsing .* invalid syntax"))
+			})
+		})
+
+		When("context is cancelled while iterating", func() {
+			It("should return context error", func() {
+				rows, err := athenaClient.QueryIterator(ctx, testSQL, reflect.TypeOf(testModel{}))
+				Expect(err).ToNot(HaveOccurred())
+				cancelledCtx, cancel := context.WithCancel(ctx)
+				cancel()
+
+				Expect(rows.Next(cancelledCtx)).To(BeFalse())
+				Expect(errors.Is(rows.Err(), context.Canceled)).To(BeTrue())
+				var contextErr *QueryContextError
+				Expect(errors.As(rows.Err(), &contextErr)).To(BeTrue())
+				Expect(contextErr.Stopped).To(BeFalse())
+				Expect(api.Calls(athenafake.OpStopQueryExecution)).To(Equal(0))
+			})
+		})
+	})
+
+	Context("QueryChannel", func() {
+		When("query succeeds", func() {
+			It("should stream all rows and close both channels", func() {
+				rows, errs := athenaClient.QueryChannel(ctx, testSQL, reflect.TypeOf(testModel{}))
+
+				count := 0
+				for row := range rows {
+					Expect(row.(*testModel).ID).To(Equal(count))
+					count++
+				}
+				Expect(count).To(Equal(15))
+				Expect(<-errs).ToNot(HaveOccurred())
+				_, ok := <-errs
+				Expect(ok).To(BeFalse())
+			})
+		})
+
+		When("mapping a page fails", func() {
+			It("should send the error and close both channels", func() {
+				pages := newTestPages(2, 1)
+				pages[1].Rows[0].Data[0].VarCharValue = util.RefString("invalid")
+				api.AddQuery("select invalid", athenafake.Query{Pages: pages})
+
+				rows, errs := athenaClient.QueryChannel(ctx, "select invalid", reflect.TypeOf(testModel{}))
+				count := 0
+				for range rows {
+					count++
+				}
+				Expect(count).To(Equal(1))
+				Expect(<-errs).To(HaveOccurred())
+			})
+		})
+
+		When("query fails", func() {
+			It("should send the error and close both channels", func() {
+				api.AddQuery("select fail", athenafake.Query{
+					States: []types.QueryExecutionState{types.QueryExecutionStateFailed},
+				})
+				rows, errs := athenaClient.QueryChannel(ctx, "select fail", reflect.TypeOf(testModel{}))
+				_, ok := <-rows
+				Expect(ok).To(BeFalse())
+				var failedErr *QueryFailedError
+				Expect(errors.As(<-errs, &failedErr)).To(BeTrue())
+			})
+		})
+
+		When("context is cancelled while the consumer stopped reading", func() {
+			It("should close both channels", func() {
+				cancelCtx, cancel := context.WithCancel(ctx)
+				rows, errs := athenaClient.QueryChannel(cancelCtx, testSQL, reflect.TypeOf(testModel{}))
+				<-rows
+				cancel()
+
+				Eventually(errs).Should(Receive(MatchError(context.Canceled)))
+				Eventually(rows).Should(BeClosed())
+			})
+		})
+	})
+})
@@ -0,0 +1,47 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const s3Scheme = "s3://"
+
+// ObjectFetcher fetches the objects written by athena to the query output location, e.g. using the GetObject API of the S3 client
+type ObjectFetcher interface {
+	// FetchObject opens the object at the location, e.g. s3://bucket/path/<query execution ID>.csv
+	FetchObject(ctx context.Context, location string) (io.ReadCloser, error)
+}
+
+// LocalObjectFetcher fetches objects from the local filesystem, s3://bucket/key is read from Root/bucket/key.
+// Useful for offline tests, or to read results previously downloaded from S3.
+type LocalObjectFetcher struct {
+	Root string
+}
+
+// FetchObject opens the file of the object at the location
+func (f LocalObjectFetcher) FetchObject(ctx context.Context, location string) (io.ReadCloser, error) {
+	bucket, key, err := parseS3Location(location)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(filepath.Join(f.Root, bucket, filepath.FromSlash(key)))
+}
+
+// parseS3Location returns the bucket and key of the location, e.g. s3://bucket/path/file.csv
+func parseS3Location(location string) (string, string, error) {
+	if !strings.HasPrefix(location, s3Scheme) {
+		err := fmt.Errorf("invalid S3 location: %s", location)
+		return "", "", err
+	}
+	parts := strings.SplitN(strings.TrimPrefix(location, s3Scheme), "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		err := fmt.Errorf("invalid S3 location: %s", location)
+		return "", "", err
+	}
+	return parts[0], parts[1], nil
+}
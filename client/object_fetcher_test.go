@@ -0,0 +1,49 @@
+package client
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Object fetcher", func() {
+	Context("LocalObjectFetcher", func() {
+		var root string
+
+		BeforeEach(func() {
+			var err error
+			root, err = ioutil.TempDir("", "athenaconv")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(root)
+		})
+
+		It("should read the object from root/bucket/key", func() {
+			Expect(os.MkdirAll(filepath.Join(root, "bucket", "results"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(root, "bucket", "results", "query-1.csv"), []byte("\"id\"\n"), 0644)).To(Succeed())
+
+			object, err := LocalObjectFetcher{Root: root}.FetchObject(context.Background(), "s3://bucket/results/query-1.csv")
+			Expect(err).ToNot(HaveOccurred())
+			defer object.Close()
+			content, err := ioutil.ReadAll(object)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(content)).To(Equal("\"id\"\n"))
+		})
+
+		It("should return error for missing objects and invalid locations", func() {
+			_, err := LocalObjectFetcher{Root: root}.FetchObject(context.Background(), "s3://bucket/missing.csv")
+			Expect(os.IsNotExist(err)).To(BeTrue())
+
+			_, err = LocalObjectFetcher{Root: root}.FetchObject(context.Background(), "https://bucket/missing.csv")
+			Expect(err).To(MatchError("invalid S3 location: https://bucket/missing.csv"))
+			_, err = LocalObjectFetcher{Root: root}.FetchObject(context.Background(), "s3://bucket")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,181 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena"
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv"
+	"github.com/kent-id/athenaconv/util"
+)
+
+// resultPager fetches the results of a query execution page by page, prefetching pages in the background if Config.PrefetchPages is set.
+// Once Config.CSVResultThreshold rows are fetched with GetQueryResults, the remaining rows are read from the CSV result file instead.
+type resultPager struct {
+	api           AthenaAPI
+	objectFetcher ObjectFetcher
+	csvThreshold  int
+	input         athena.GetQueryResultsInput
+	done          bool
+	// pages receives the prefetched pages, nil if prefetching is disabled
+	pages chan resultPage
+
+	// state of fetch, only accessed by the prefetching goroutine if prefetching is enabled
+	rowsFetched int
+	metadata    *types.ResultSetMetadata
+	csvObject   io.ReadCloser
+	csvReader   *athenaconv.CSVResultReader
+}
+
+type resultPage struct {
+	resultSet *types.ResultSet
+	last      bool
+	err       error
+}
+
+// newResultPager creates new resultPager, prefetching runs until the last page is fetched, an error occurs or ctx is done
+func (c *Client) newResultPager(ctx context.Context, queryExecutionID string) *resultPager {
+	pager := &resultPager{
+		api:           c.api,
+		objectFetcher: c.config.ObjectFetcher,
+		csvThreshold:  c.config.CSVResultThreshold,
+		input: athena.GetQueryResultsInput{
+			QueryExecutionId: util.RefString(queryExecutionID),
+			MaxResults:       util.RefInt32(c.config.PageSize),
+		},
+	}
+	if c.config.PrefetchPages > 0 {
+		pager.pages = make(chan resultPage, c.config.PrefetchPages)
+		go pager.prefetch(ctx)
+	}
+	return pager
+}
+
+// next returns the next page of the results, nil if the page is empty
+func (p *resultPager) next(ctx context.Context) (*types.ResultSet, error) {
+	if p.pages == nil {
+		resultSet, last, err := p.fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+		p.done = last
+		return resultSet, nil
+	}
+
+	select {
+	case page, ok := <-p.pages:
+		if !ok {
+			err := errors.New("result pager is closed")
+			return nil, err
+		}
+		if page.err != nil {
+			return nil, page.err
+		}
+		p.done = page.last
+		return page.resultSet, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// close releases the CSV result file, the prefetching goroutine releases it itself once it stops
+func (p *resultPager) close() {
+	if p.pages == nil {
+		p.closeCSV()
+	}
+}
+
+// prefetch fetches the pages into the pages channel, blocking while the channel is full
+func (p *resultPager) prefetch(ctx context.Context) {
+	defer close(p.pages)
+	defer p.closeCSV()
+	for {
+		resultSet, last, err := p.fetch(ctx)
+		select {
+		case p.pages <- resultPage{resultSet: resultSet, last: last, err: err}:
+		case <-ctx.Done():
+			return
+		}
+		if err != nil || last {
+			return
+		}
+	}
+}
+
+// fetch returns the next page from the CSV result file if opened, otherwise from GetQueryResults, returns true if it is the last page
+func (p *resultPager) fetch(ctx context.Context) (*types.ResultSet, bool, error) {
+	if p.csvReader != nil {
+		return p.fetchCSV()
+	}
+
+	queryResultOutput, err := p.api.GetQueryResults(ctx, &p.input)
+	if err != nil {
+		return nil, false, err
+	}
+	p.input.NextToken = queryResultOutput.NextToken
+	last := queryResultOutput.NextToken == nil
+	if resultSet := queryResultOutput.ResultSet; resultSet != nil {
+		p.rowsFetched += len(resultSet.Rows)
+		if p.metadata == nil {
+			p.metadata = resultSet.ResultSetMetadata
+		}
+	}
+
+	if !last && p.objectFetcher != nil && p.csvThreshold > 0 && p.rowsFetched >= p.csvThreshold {
+		if err := p.openCSV(ctx); err != nil {
+			return nil, false, err
+		}
+	}
+	return queryResultOutput.ResultSet, last, nil
+}
+
+// openCSV opens the CSV result file of the query execution and skips the rows already fetched with GetQueryResults
+func (p *resultPager) openCSV(ctx context.Context) error {
+	queryExecOutput, err := p.api.GetQueryExecution(ctx, &athena.GetQueryExecutionInput{
+		QueryExecutionId: p.input.QueryExecutionId,
+	})
+	if err != nil {
+		return err
+	}
+	queryExecution := queryExecOutput.QueryExecution
+	if queryExecution == nil || queryExecution.ResultConfiguration == nil || queryExecution.ResultConfiguration.OutputLocation == nil {
+		err := fmt.Errorf("query execution %s returned no output location", util.SafeString(p.input.QueryExecutionId))
+		return err
+	}
+
+	object, err := p.objectFetcher.FetchObject(ctx, *queryExecution.ResultConfiguration.OutputLocation)
+	if err != nil {
+		return err
+	}
+	reader := athenaconv.NewCSVResultReader(object, p.metadata)
+	if err := reader.Skip(p.rowsFetched); err != nil {
+		object.Close()
+		err := fmt.Errorf("failed to skip %d rows of the csv result file: %v", p.rowsFetched, err)
+		return err
+	}
+	p.csvObject = object
+	p.csvReader = reader
+	return nil
+}
+
+func (p *resultPager) fetchCSV() (*types.ResultSet, bool, error) {
+	resultSet, err := p.csvReader.Next(csvResultPageSize)
+	if err == io.EOF {
+		p.closeCSV()
+		return nil, true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return resultSet, false, nil
+}
+
+func (p *resultPager) closeCSV() {
+	if p.csvObject != nil {
+		p.csvObject.Close()
+		p.csvObject = nil
+	}
+}
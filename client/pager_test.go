@@ -0,0 +1,215 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+
+	"github.com/kent-id/athenaconv/athenafake"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// writeTestCSV writes the rows of the pages as the CSV result file written by athena to root/bucket/key of the location
+func writeTestCSV(root, location string, pages []*types.ResultSet) {
+	bucket, key, err := parseS3Location(location)
+	Expect(err).ToNot(HaveOccurred())
+	var builder strings.Builder
+	for _, page := range pages {
+		for _, row := range page.Rows {
+			fields := make([]string, 0, len(row.Data))
+			for _, datum := range row.Data {
+				if datum.VarCharValue == nil {
+					fields = append(fields, "")
+				} else {
+					fields = append(fields, `"`+strings.ReplaceAll(*datum.VarCharValue, `"`, `""`)+`"`)
+				}
+			}
+			builder.WriteString(strings.Join(fields, ",") + "\n")
+		}
+	}
+
+	path := filepath.Join(root, bucket, filepath.FromSlash(key))
+	Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+	Expect(ioutil.WriteFile(path, []byte(builder.String()), 0644)).To(Succeed())
+}
+
+var _ = Describe("Result pager", func() {
+	var ctx context.Context
+	var api *athenafake.Fake
+	var athenaClient *Client
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		api = athenafake.New()
+		api.AddQuery(testSQL, athenafake.Query{
+			Pages: newTestPages(5, 2),
+		})
+		athenaClient = New(api, Config{
+			PageSize:      2,
+			WaitInterval:  time.Millisecond,
+			PrefetchPages: 1,
+		})
+	})
+
+	When("prefetching is enabled", func() {
+		It("should return all pages in order", func() {
+			var result []testModel
+			err := athenaClient.QueryInto(ctx, testSQL, &result)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(result)).To(Equal(10))
+			for i, model := range result {
+				Expect(model.ID).To(Equal(i))
+			}
+			Expect(api.Calls(athenafake.OpGetQueryResults)).To(Equal(5))
+		})
+
+		It("should fetch ahead of the consumed page up to the buffer size", func() {
+			rows, err := athenaClient.QueryIterator(ctx, testSQL, reflect.TypeOf(testModel{}))
+			Expect(err).ToNot(HaveOccurred())
+			defer rows.Close()
+			Expect(rows.Next(ctx)).To(BeTrue())
+
+			// first page is consumed, second page is buffered and third page is waiting for buffer space
+			Eventually(func() int { return api.Calls(athenafake.OpGetQueryResults) }).Should(Equal(3))
+			Consistently(func() int { return api.Calls(athenafake.OpGetQueryResults) }, 50*time.Millisecond).Should(Equal(3))
+		})
+
+		It("should stop prefetching when the iterator is closed", func() {
+			api.SetLatency(10 * time.Millisecond)
+			rows, err := athenaClient.QueryIterator(ctx, testSQL, reflect.TypeOf(testModel{}))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rows.Next(ctx)).To(BeTrue())
+			Expect(rows.Close()).To(Succeed())
+
+			calls := api.Calls(athenafake.OpGetQueryResults)
+			Consistently(func() int { return api.Calls(athenafake.OpGetQueryResults) }, 100*time.Millisecond).Should(BeNumerically("<=", calls+1))
+		})
+
+		It("should stop prefetching when the context of an abandoned iterator is cancelled", func() {
+			api.SetLatency(10 * time.Millisecond)
+			queryCtx, cancel := context.WithCancel(ctx)
+			rows, err := athenaClient.QueryIterator(queryCtx, testSQL, reflect.TypeOf(testModel{}))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(rows.Next(ctx)).To(BeTrue())
+			cancel()
+
+			// the pager goroutine closes the channel once it stops, without fetching the last page
+			var pages []resultPage
+			Eventually(func() bool {
+				page, ok := <-rows.pager.pages
+				pages = append(pages, page)
+				return !ok
+			}, time.Second).Should(BeTrue())
+			for _, page := range pages {
+				Expect(page.last).To(BeFalse())
+			}
+		})
+
+		It("should return prefetch errors in order", func() {
+			api.FailNext(athenafake.OpGetQueryResults, nil, errors.New("throttled"))
+			rows, err := athenaClient.QueryIterator(ctx, testSQL, reflect.TypeOf(testModel{}))
+			Expect(err).ToNot(HaveOccurred())
+
+			count := 0
+			for rows.Next(ctx) {
+				count++
+			}
+			Expect(count).To(Equal(2))
+			Expect(rows.Err()).To(MatchError("throttled"))
+		})
+
+		It("should return context error while waiting for the next page", func() {
+			_, err := athenaClient.QueryIterator(ctx, testSQL, reflect.TypeOf(testModel{}))
+			Expect(err).ToNot(HaveOccurred())
+			api.SetLatency(time.Second)
+			pagerCtx, cancelPager := context.WithCancel(ctx)
+			defer cancelPager()
+			pager := athenaClient.newResultPager(pagerCtx, "query-1")
+			timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+			defer cancel()
+
+			_, err = pager.next(timeoutCtx)
+			Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+		})
+	})
+
+	When("CSV result threshold is reached", func() {
+		var root string
+		var pages []*types.ResultSet
+
+		BeforeEach(func() {
+			var err error
+			root, err = ioutil.TempDir("", "athenaconv")
+			Expect(err).ToNot(HaveOccurred())
+
+			pages = newTestPages(5, 2)
+			api.AddQuery(testSQL, athenafake.Query{Pages: pages})
+			writeTestCSV(root, "s3://bucket/results/query-1.csv", pages)
+			athenaClient = New(api, Config{
+				OutputLocation:     "s3://bucket/results/",
+				PageSize:           2,
+				WaitInterval:       time.Millisecond,
+				ObjectFetcher:      LocalObjectFetcher{Root: root},
+				CSVResultThreshold: 3,
+			})
+		})
+
+		AfterEach(func() {
+			os.RemoveAll(root)
+		})
+
+		It("should read the remaining rows from the CSV result file", func() {
+			var result []testModel
+			err := athenaClient.QueryInto(ctx, testSQL, &result)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(result)).To(Equal(10))
+			for i, model := range result {
+				Expect(model).To(Equal(testModel{ID: i, Name: "name " + strconv.Itoa(i)}))
+			}
+			Expect(api.Calls(athenafake.OpGetQueryResults)).To(Equal(1))
+		})
+
+		It("should read the remaining rows from the CSV result file while prefetching", func() {
+			athenaClient.config.PrefetchPages = 2
+			rows, err := athenaClient.QueryIterator(ctx, testSQL, reflect.TypeOf(testModel{}))
+			Expect(err).ToNot(HaveOccurred())
+			defer rows.Close()
+
+			count := 0
+			for rows.Next(ctx) {
+				var model testModel
+				Expect(rows.Scan(&model)).To(Succeed())
+				Expect(model.ID).To(Equal(count))
+				count++
+			}
+			Expect(rows.Err()).ToNot(HaveOccurred())
+			Expect(count).To(Equal(10))
+			Expect(api.Calls(athenafake.OpGetQueryResults)).To(Equal(1))
+		})
+
+		It("should keep paging GetQueryResults below the threshold", func() {
+			athenaClient.config.CSVResultThreshold = 100
+			var result []testModel
+			err := athenaClient.QueryInto(ctx, testSQL, &result)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(result)).To(Equal(10))
+			Expect(api.Calls(athenafake.OpGetQueryResults)).To(Equal(5))
+		})
+
+		It("should return error if the CSV result file is missing", func() {
+			os.RemoveAll(root)
+			var result []testModel
+			err := athenaClient.QueryInto(ctx, testSQL, &result)
+			Expect(os.IsNotExist(err)).To(BeTrue())
+		})
+	})
+})
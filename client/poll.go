@@ -0,0 +1,167 @@
+package client
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+)
+
+// PollStrategy decides how long Client waits between GetQueryExecution calls while the query is queued or running
+type PollStrategy interface {
+	// NextInterval returns the wait before the next poll, attempt is 1 for the wait after the first poll.
+	// execution is the latest query execution returned by athena, including its Status and Statistics.
+	NextInterval(attempt int, execution *types.QueryExecution) time.Duration
+}
+
+// PollMetrics describes the polling of a single query execution, e.g. to tune the PollStrategy
+type PollMetrics struct {
+	QueryExecutionID string
+	// Polls is the number of GetQueryExecution calls
+	Polls int
+	// TotalWait is the sum of the intervals waited between polls
+	TotalWait time.Duration
+	// Elapsed is the wall-clock duration from the first poll until the query finished or polling stopped
+	Elapsed time.Duration
+	// FinalState is the last state returned by athena, empty if polling stopped on error
+	FinalState types.QueryExecutionState
+	// QueueTime is the time the query spent queued, as reported by athena statistics
+	QueueTime time.Duration
+	// EngineExecutionTime is the time the query spent running, as reported by athena statistics
+	EngineExecutionTime time.Duration
+}
+
+// FixedPollStrategy waits the same interval between every poll
+type FixedPollStrategy struct {
+	Interval time.Duration
+}
+
+// NextInterval returns the fixed interval
+func (s FixedPollStrategy) NextInterval(attempt int, execution *types.QueryExecution) time.Duration {
+	return s.Interval
+}
+
+// defaultInitialPollInterval is the first interval of ExponentialPollStrategy without Initial
+const defaultInitialPollInterval = 100 * time.Millisecond
+
+// ExponentialPollStrategy multiplies the interval after every poll, starting at Initial and capped at Max
+type ExponentialPollStrategy struct {
+	// Initial interval after the first poll, defaults to 100ms
+	Initial time.Duration
+	// Max interval, defaults to no cap other than the max time.Duration
+	Max time.Duration
+	// Multiplier of the interval after every poll, defaults to 2
+	Multiplier float64
+}
+
+// NextInterval returns Initial * Multiplier^(attempt-1), capped at Max
+func (s ExponentialPollStrategy) NextInterval(attempt int, execution *types.QueryExecution) time.Duration {
+	multiplier := s.Multiplier
+	if multiplier <= 1 {
+		multiplier = 2
+	}
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	initial := s.Initial
+	if initial <= 0 {
+		initial = defaultInitialPollInterval
+	}
+
+	// the float interval overflows time.Duration, or reaches +Inf, after enough attempts
+	interval := float64(initial) * math.Pow(multiplier, float64(attempt-1))
+	if s.Max > 0 && interval > float64(s.Max) {
+		return s.Max
+	}
+	if interval >= math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(interval)
+}
+
+// JitteredPollStrategy randomizes the interval of the wrapped strategy by up to +/- Jitter fraction,
+// so that many clients started together do not poll athena at the same time
+type JitteredPollStrategy struct {
+	Strategy PollStrategy
+	// Jitter is a fraction between 0 and 1 of the wrapped interval, values out of range are clamped
+	Jitter float64
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// NewJitteredPollStrategy creates new JitteredPollStrategy, jitter is a fraction between 0 and 1 of the wrapped interval
+func NewJitteredPollStrategy(strategy PollStrategy, jitter float64) *JitteredPollStrategy {
+	return &JitteredPollStrategy{
+		Strategy: strategy,
+		Jitter:   clampJitter(jitter),
+		rand:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// NextInterval returns the wrapped interval multiplied by a random factor in [1 - Jitter, 1 + Jitter]
+func (s *JitteredPollStrategy) NextInterval(attempt int, execution *types.QueryExecution) time.Duration {
+	interval := s.Strategy.NextInterval(attempt, execution)
+
+	s.mu.Lock()
+	if s.rand == nil {
+		// created as struct literal instead of NewJitteredPollStrategy
+		s.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	random := s.rand.Float64()
+	s.mu.Unlock()
+
+	factor := 1 + clampJitter(s.Jitter)*(2*random-1)
+	return time.Duration(float64(interval) * factor)
+}
+
+func clampJitter(jitter float64) float64 {
+	return math.Max(0, math.Min(1, jitter))
+}
+
+// StatisticsPollStrategy adapts the interval to the query execution statistics reported by athena:
+// it waits Fraction of the time the query has spent queued and running so far, bounded by Min and Max.
+// Short queries are polled often while long queries are polled less, keeping the relative latency overhead constant.
+type StatisticsPollStrategy struct {
+	// Fraction of the elapsed query time to wait, defaults to 0.1
+	Fraction float64
+	Min      time.Duration
+	Max      time.Duration
+}
+
+// NextInterval returns Fraction of the queue and engine execution time reported so far, bounded by Min and Max
+func (s StatisticsPollStrategy) NextInterval(attempt int, execution *types.QueryExecution) time.Duration {
+	fraction := s.Fraction
+	if fraction <= 0 {
+		fraction = 0.1
+	}
+
+	queueTime, engineExecutionTime := executionTimes(execution)
+	interval := time.Duration(float64(queueTime+engineExecutionTime) * fraction)
+	if interval < s.Min {
+		interval = s.Min
+	}
+	if s.Max > 0 && interval > s.Max {
+		interval = s.Max
+	}
+	return interval
+}
+
+// executionTimes returns the queue and engine execution time from the query execution statistics
+func executionTimes(execution *types.QueryExecution) (time.Duration, time.Duration) {
+	if execution == nil || execution.Statistics == nil {
+		return 0, 0
+	}
+
+	var queueTime, engineExecutionTime time.Duration
+	if execution.Statistics.QueryQueueTimeInMillis != nil {
+		queueTime = time.Duration(*execution.Statistics.QueryQueueTimeInMillis) * time.Millisecond
+	}
+	if execution.Statistics.EngineExecutionTimeInMillis != nil {
+		engineExecutionTime = time.Duration(*execution.Statistics.EngineExecutionTimeInMillis) * time.Millisecond
+	}
+	return queueTime, engineExecutionTime
+}
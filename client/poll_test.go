@@ -0,0 +1,138 @@
+package client
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv/athenafake"
+	"github.com/kent-id/athenaconv/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Poll strategy", func() {
+	Context("FixedPollStrategy", func() {
+		It("should return the same interval", func() {
+			strategy := FixedPollStrategy{Interval: time.Second}
+			Expect(strategy.NextInterval(1, nil)).To(Equal(time.Second))
+			Expect(strategy.NextInterval(100, nil)).To(Equal(time.Second))
+		})
+	})
+
+	Context("ExponentialPollStrategy", func() {
+		It("should grow the interval up to max", func() {
+			strategy := ExponentialPollStrategy{Initial: 100 * time.Millisecond, Max: time.Second}
+			Expect(strategy.NextInterval(1, nil)).To(Equal(100 * time.Millisecond))
+			Expect(strategy.NextInterval(2, nil)).To(Equal(200 * time.Millisecond))
+			Expect(strategy.NextInterval(4, nil)).To(Equal(800 * time.Millisecond))
+			Expect(strategy.NextInterval(5, nil)).To(Equal(time.Second))
+			Expect(strategy.NextInterval(1000, nil)).To(Equal(time.Second))
+		})
+
+		It("should use the given multiplier", func() {
+			strategy := ExponentialPollStrategy{Initial: 100 * time.Millisecond, Multiplier: 1.5}
+			Expect(strategy.NextInterval(3, nil)).To(Equal(225 * time.Millisecond))
+		})
+
+		It("should default initial interval", func() {
+			strategy := ExponentialPollStrategy{Max: 10 * time.Second}
+			Expect(strategy.NextInterval(1, nil)).To(Equal(100 * time.Millisecond))
+			Expect(strategy.NextInterval(3, nil)).To(Equal(400 * time.Millisecond))
+			Expect(strategy.NextInterval(100, nil)).To(Equal(10 * time.Second))
+		})
+
+		It("should cap the interval at max duration without max", func() {
+			strategy := ExponentialPollStrategy{Initial: time.Second}
+			Expect(strategy.NextInterval(64, nil)).To(Equal(time.Duration(math.MaxInt64)))
+			Expect(strategy.NextInterval(100000, nil)).To(Equal(time.Duration(math.MaxInt64)))
+		})
+	})
+
+	Context("JitteredPollStrategy", func() {
+		It("should randomize the interval within jitter bounds", func() {
+			strategy := NewJitteredPollStrategy(FixedPollStrategy{Interval: time.Second}, 0.2)
+			seen := make(map[time.Duration]bool)
+			for i := 0; i < 100; i++ {
+				interval := strategy.NextInterval(i, nil)
+				Expect(interval).To(BeNumerically(">=", 800*time.Millisecond))
+				Expect(interval).To(BeNumerically("<=", 1200*time.Millisecond))
+				seen[interval] = true
+			}
+			Expect(len(seen)).To(BeNumerically(">", 1))
+		})
+
+		It("should clamp jitter fraction", func() {
+			strategy := NewJitteredPollStrategy(FixedPollStrategy{Interval: time.Second}, 5)
+			Expect(strategy.Jitter).To(Equal(float64(1)))
+		})
+
+		It("should work when created as struct literal", func() {
+			strategy := &JitteredPollStrategy{Strategy: FixedPollStrategy{Interval: time.Second}, Jitter: -3}
+			Expect(strategy.NextInterval(1, nil)).To(Equal(time.Second))
+
+			strategy = &JitteredPollStrategy{Strategy: FixedPollStrategy{Interval: time.Second}, Jitter: 5}
+			for i := 0; i < 100; i++ {
+				Expect(strategy.NextInterval(i, nil)).To(BeNumerically("<=", 2*time.Second))
+			}
+		})
+	})
+
+	Context("StatisticsPollStrategy", func() {
+		It("should wait a fraction of the elapsed query time within bounds", func() {
+			strategy := StatisticsPollStrategy{Fraction: 0.1, Min: 100 * time.Millisecond, Max: 5 * time.Second}
+			execution := &types.QueryExecution{
+				Statistics: &types.QueryExecutionStatistics{
+					QueryQueueTimeInMillis:      util.RefInt64(2000),
+					EngineExecutionTimeInMillis: util.RefInt64(8000),
+				},
+			}
+			Expect(strategy.NextInterval(1, execution)).To(Equal(time.Second))
+
+			execution.Statistics.EngineExecutionTimeInMillis = util.RefInt64(600000)
+			Expect(strategy.NextInterval(1, execution)).To(Equal(5 * time.Second))
+
+			Expect(strategy.NextInterval(1, &types.QueryExecution{})).To(Equal(100 * time.Millisecond))
+		})
+	})
+
+	Context("Client polling", func() {
+		It("should use the poll strategy and report poll metrics", func() {
+			api := athenafake.New()
+			api.AddQuery(testSQL, athenafake.Query{
+				States: []types.QueryExecutionState{
+					types.QueryExecutionStateQueued,
+					types.QueryExecutionStateRunning,
+					types.QueryExecutionStateRunning,
+					types.QueryExecutionStateSucceeded,
+				},
+				Statistics: &types.QueryExecutionStatistics{
+					QueryQueueTimeInMillis:      util.RefInt64(10),
+					EngineExecutionTimeInMillis: util.RefInt64(20),
+				},
+				Pages: newTestPages(1, 1),
+			})
+
+			var metrics []PollMetrics
+			athenaClient := New(api, Config{
+				PollStrategy: ExponentialPollStrategy{Initial: time.Millisecond, Max: 2 * time.Millisecond},
+				OnPollFinished: func(m PollMetrics) {
+					metrics = append(metrics, m)
+				},
+			})
+
+			_, err := athenaClient.Query(context.Background(), testSQL, reflect.TypeOf(testModel{}))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(metrics)).To(Equal(1))
+			Expect(metrics[0].QueryExecutionID).To(Equal("query-1"))
+			Expect(metrics[0].Polls).To(Equal(4))
+			Expect(metrics[0].TotalWait).To(Equal(5 * time.Millisecond))
+			Expect(metrics[0].Elapsed).To(BeNumerically(">=", 5*time.Millisecond))
+			Expect(metrics[0].FinalState).To(Equal(types.QueryExecutionStateSucceeded))
+			Expect(metrics[0].QueueTime).To(Equal(10 * time.Millisecond))
+			Expect(metrics[0].EngineExecutionTime).To(Equal(20 * time.Millisecond))
+		})
+	})
+})
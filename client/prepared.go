@@ -0,0 +1,317 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena"
+	"github.com/kent-id/athenaconv"
+	"github.com/kent-id/athenaconv/util"
+)
+
+const (
+	defaultWorkGroup       = "primary"
+	resourceNotFoundCode   = "ResourceNotFoundException"
+	statementVersionLength = 8
+)
+
+var (
+	statementNamePattern    = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	statementVersionPattern = regexp.MustCompile(fmt.Sprintf(`^[0-9a-f]{%d}$`, statementVersionLength))
+)
+
+// PreparedStatementAPI is the subset of the athena client from aws-sdk-go-v2 used by PreparedStatements.
+// It is implemented by *athena.Client, and by athenafake.Fake for offline tests.
+type PreparedStatementAPI interface {
+	CreatePreparedStatement(ctx context.Context, params *athena.CreatePreparedStatementInput, optFns ...func(*athena.Options)) (*athena.CreatePreparedStatementOutput, error)
+	GetPreparedStatement(ctx context.Context, params *athena.GetPreparedStatementInput, optFns ...func(*athena.Options)) (*athena.GetPreparedStatementOutput, error)
+	UpdatePreparedStatement(ctx context.Context, params *athena.UpdatePreparedStatementInput, optFns ...func(*athena.Options)) (*athena.UpdatePreparedStatementOutput, error)
+	DeletePreparedStatement(ctx context.Context, params *athena.DeletePreparedStatementInput, optFns ...func(*athena.Options)) (*athena.DeletePreparedStatementOutput, error)
+	ListPreparedStatements(ctx context.Context, params *athena.ListPreparedStatementsInput, optFns ...func(*athena.Options)) (*athena.ListPreparedStatementsOutput, error)
+}
+
+var _ PreparedStatementAPI = (*athena.Client)(nil)
+
+// PreparedStatements manages the prepared statements of the workgroup of Client and executes them with query args.
+// Registered statements are cached, so that executing them repeatedly costs no extra API calls.
+type PreparedStatements struct {
+	client    *Client
+	api       PreparedStatementAPI
+	workGroup string
+
+	mu sync.Mutex
+	// statements are the SQL queries of the registered statements by name
+	statements map[string]string
+	// versions are the current versioned names of the statements registered with RegisterVersioned by name
+	versions map[string]string
+}
+
+// NewPreparedStatements creates new PreparedStatements for the workgroup of the client, the primary workgroup if not set
+//
+// Example:
+//
+//	athenaAPI := athena.NewFromConfig(awsConfig)
+//	statements := client.NewPreparedStatements(client.New(athenaAPI, config), athenaAPI)
+func NewPreparedStatements(client *Client, api PreparedStatementAPI) *PreparedStatements {
+	workGroup := client.config.WorkGroup
+	if workGroup == "" {
+		workGroup = defaultWorkGroup
+	}
+	return &PreparedStatements{
+		client:     client,
+		api:        api,
+		workGroup:  workGroup,
+		statements: make(map[string]string),
+		versions:   make(map[string]string),
+	}
+}
+
+// Register creates the prepared statement with the given name and SQL query, with ? placeholders for the query args.
+// The statement is updated if it already exists with a different SQL query.
+func (p *PreparedStatements) Register(ctx context.Context, name string, sqlQuery string) error {
+	if err := validateStatementName(name); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	cached, ok := p.statements[name]
+	p.mu.Unlock()
+	if ok && cached == sqlQuery {
+		return nil
+	}
+
+	current, found, err := p.get(ctx, name)
+	if err != nil {
+		return err
+	}
+	switch {
+	case !found:
+		_, err = p.api.CreatePreparedStatement(ctx, &athena.CreatePreparedStatementInput{
+			StatementName:  util.RefString(name),
+			QueryStatement: util.RefString(sqlQuery),
+			WorkGroup:      util.RefString(p.workGroup),
+		})
+	case current != sqlQuery:
+		_, err = p.api.UpdatePreparedStatement(ctx, &athena.UpdatePreparedStatementInput{
+			StatementName:  util.RefString(name),
+			QueryStatement: util.RefString(sqlQuery),
+			WorkGroup:      util.RefString(p.workGroup),
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.statements[name] = sqlQuery
+	return nil
+}
+
+// RegisterVersioned registers the SQL query as the prepared statement named after name and the hash of the SQL query,
+// e.g. my_query_1a2b3c4d, and returns the versioned name. Changing the SQL query creates a new version while the
+// previous versions keep serving the instances not upgraded yet, see RemoveStaleVersions.
+func (p *PreparedStatements) RegisterVersioned(ctx context.Context, name string, sqlQuery string) (string, error) {
+	if err := validateStatementName(name); err != nil {
+		return "", err
+	}
+
+	versionedName := VersionedStatementName(name, sqlQuery)
+	if err := p.Register(ctx, versionedName, sqlQuery); err != nil {
+		return "", err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.versions[name] = versionedName
+	return versionedName, nil
+}
+
+// VersionedStatementName returns the name of the prepared statement versioned by the hash of the SQL query
+func VersionedStatementName(name string, sqlQuery string) string {
+	hash := sha256.Sum256([]byte(sqlQuery))
+	return name + "_" + hex.EncodeToString(hash[:])[:statementVersionLength]
+}
+
+// RemoveStaleVersions deletes the versions of the statement other than the one last registered with RegisterVersioned,
+// returns the names of the deleted prepared statements
+func (p *PreparedStatements) RemoveStaleVersions(ctx context.Context, name string) ([]string, error) {
+	p.mu.Lock()
+	current, ok := p.versions[name]
+	p.mu.Unlock()
+	if !ok {
+		err := fmt.Errorf("prepared statement %s has no registered version", name)
+		return nil, err
+	}
+
+	names, err := p.list(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	removed := make([]string, 0)
+	for _, statementName := range names {
+		if statementName == current || !isStatementVersion(name, statementName) {
+			continue
+		}
+		_, err := p.api.DeletePreparedStatement(ctx, &athena.DeletePreparedStatementInput{
+			StatementName: util.RefString(statementName),
+			WorkGroup:     util.RefString(p.workGroup),
+		})
+		if err != nil && !isResourceNotFound(err) {
+			return removed, err
+		}
+
+		p.mu.Lock()
+		delete(p.statements, statementName)
+		p.mu.Unlock()
+		removed = append(removed, statementName)
+	}
+	return removed, nil
+}
+
+// Query executes the prepared statement with the args and converts the results into array of pointers to modelType, see Client.Query.
+// The statement is looked up in the workgroup if it was not registered with this PreparedStatements.
+//
+// Example:
+//
+//	result, err := statements.Query(ctx, "find_by_id", reflect.TypeOf(MyModel{}), 42)
+func (p *PreparedStatements) Query(ctx context.Context, name string, modelType reflect.Type, args ...interface{}) ([]interface{}, error) {
+	queryArgs, opts := splitArgs(args)
+	mapper, err := athenaconv.NewMapperFor(modelType, opts...)
+	if err != nil {
+		return nil, err
+	}
+	stmt, err := p.bindArgs(ctx, name, queryArgs)
+	if err != nil {
+		return nil, err
+	}
+	return p.client.queryModels(ctx, stmt, mapper)
+}
+
+// QueryInto executes the prepared statement with the args and appends the results into dest, see Client.QueryInto
+func (p *PreparedStatements) QueryInto(ctx context.Context, name string, dest interface{}, args ...interface{}) error {
+	return queryInto(dest, func(modelType reflect.Type) ([]interface{}, error) {
+		return p.Query(ctx, name, modelType, args...)
+	})
+}
+
+// bindArgs returns the EXECUTE statement of the prepared statement with the args,
+// passed as execution parameters, or as literals in the USING clause if Config.InterpolateArgs is set
+func (p *PreparedStatements) bindArgs(ctx context.Context, name string, args []interface{}) (statement, error) {
+	if err := validateStatementName(name); err != nil {
+		return statement{}, err
+	}
+	sqlQuery, err := p.statement(ctx, name)
+	if err != nil {
+		return statement{}, err
+	}
+
+	placeholders := countPlaceholders(sqlQuery)
+	if placeholders != len(args) {
+		err := fmt.Errorf("mismatched query args count: %d, expected: %d placeholders", len(args), placeholders)
+		return statement{}, err
+	}
+
+	execute := "EXECUTE " + name
+	if len(args) == 0 {
+		return statement{sql: execute, preparedSQL: sqlQuery}, nil
+	}
+	literals, err := formatArgs(args)
+	if err != nil {
+		return statement{}, err
+	}
+	if p.client.config.InterpolateArgs {
+		return statement{sql: execute + " USING " + strings.Join(literals, ", "), preparedSQL: sqlQuery}, nil
+	}
+	return statement{sql: execute, executionParameters: literals, preparedSQL: sqlQuery}, nil
+}
+
+// statement returns the SQL query of the prepared statement, from the cache if registered with this PreparedStatements
+func (p *PreparedStatements) statement(ctx context.Context, name string) (string, error) {
+	p.mu.Lock()
+	sqlQuery, ok := p.statements[name]
+	p.mu.Unlock()
+	if ok {
+		return sqlQuery, nil
+	}
+
+	sqlQuery, found, err := p.get(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		err := fmt.Errorf("prepared statement %s not found in workgroup %s", name, p.workGroup)
+		return "", err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.statements[name] = sqlQuery
+	return sqlQuery, nil
+}
+
+// get returns the SQL query of the prepared statement in the workgroup, found is false if the statement does not exist
+func (p *PreparedStatements) get(ctx context.Context, name string) (sqlQuery string, found bool, err error) {
+	output, err := p.api.GetPreparedStatement(ctx, &athena.GetPreparedStatementInput{
+		StatementName: util.RefString(name),
+		WorkGroup:     util.RefString(p.workGroup),
+	})
+	if err != nil {
+		if isResourceNotFound(err) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	if output.PreparedStatement == nil {
+		return "", false, nil
+	}
+	return util.SafeString(output.PreparedStatement.QueryStatement), true, nil
+}
+
+// list returns the names of all prepared statements in the workgroup
+func (p *PreparedStatements) list(ctx context.Context) ([]string, error) {
+	input := athena.ListPreparedStatementsInput{
+		WorkGroup: util.RefString(p.workGroup),
+	}
+	names := make([]string, 0)
+	for {
+		output, err := p.api.ListPreparedStatements(ctx, &input)
+		if err != nil {
+			return nil, err
+		}
+		for _, summary := range output.PreparedStatements {
+			names = append(names, util.SafeString(summary.StatementName))
+		}
+		if output.NextToken == nil {
+			return names, nil
+		}
+		input.NextToken = output.NextToken
+	}
+}
+
+func validateStatementName(name string) error {
+	if !statementNamePattern.MatchString(name) {
+		err := fmt.Errorf("invalid prepared statement name: %s", name)
+		return err
+	}
+	return nil
+}
+
+// isStatementVersion returns true if statementName is a version of the statement created by RegisterVersioned
+func isStatementVersion(name string, statementName string) bool {
+	prefix := name + "_"
+	return strings.HasPrefix(statementName, prefix) && statementVersionPattern.MatchString(statementName[len(prefix):])
+}
+
+func isResourceNotFound(err error) bool {
+	var apiErr interface{ ErrorCode() string }
+	return errors.As(err, &apiErr) && apiErr.ErrorCode() == resourceNotFoundCode
+}
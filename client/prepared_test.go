@@ -0,0 +1,205 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena"
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv/athenafake"
+	"github.com/kent-id/athenaconv/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ PreparedStatementAPI = (*athenafake.Fake)(nil)
+
+var _ = Describe("PreparedStatements", func() {
+	const preparedSQL = "select id, name from t where id > ? and name <> ?"
+
+	var ctx context.Context
+	var api *athenafake.Fake
+	var statements *PreparedStatements
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		api = athenafake.New()
+		statements = NewPreparedStatements(New(api, Config{WorkGroup: "wg", WaitInterval: time.Millisecond}), api)
+	})
+
+	Context("Register", func() {
+		It("should create the statement once", func() {
+			Expect(statements.Register(ctx, "find_items", preparedSQL)).To(Succeed())
+			Expect(statements.Register(ctx, "find_items", preparedSQL)).To(Succeed())
+
+			Expect(api.PreparedStatements("wg")).To(Equal([]string{"find_items"}))
+			Expect(api.Calls(athenafake.OpCreatePreparedStatement)).To(Equal(1))
+			Expect(api.Calls(athenafake.OpGetPreparedStatement)).To(Equal(1))
+		})
+
+		It("should update the statement with different SQL query", func() {
+			Expect(statements.Register(ctx, "find_items", "select 1")).To(Succeed())
+			Expect(NewPreparedStatements(New(api, Config{WorkGroup: "wg"}), api).Register(ctx, "find_items", preparedSQL)).To(Succeed())
+
+			output, err := api.GetPreparedStatement(ctx, &athena.GetPreparedStatementInput{
+				StatementName: util.RefString("find_items"),
+				WorkGroup:     util.RefString("wg"),
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(*output.PreparedStatement.QueryStatement).To(Equal(preparedSQL))
+			Expect(api.Calls(athenafake.OpUpdatePreparedStatement)).To(Equal(1))
+		})
+
+		It("should use the primary workgroup by default", func() {
+			statements = NewPreparedStatements(New(api, Config{}), api)
+			Expect(statements.Register(ctx, "find_items", preparedSQL)).To(Succeed())
+			Expect(api.PreparedStatements("primary")).To(Equal([]string{"find_items"}))
+		})
+
+		It("should return error for invalid name", func() {
+			err := statements.Register(ctx, "find-items; drop", preparedSQL)
+			Expect(err).To(MatchError("invalid prepared statement name: find-items; drop"))
+			Expect(api.Calls(athenafake.OpGetPreparedStatement)).To(Equal(0))
+		})
+
+		It("should return API error other than not found", func() {
+			api.FailNext(athenafake.OpGetPreparedStatement, errors.New("access denied"))
+			Expect(statements.Register(ctx, "find_items", preparedSQL)).To(MatchError("access denied"))
+		})
+	})
+
+	Context("RegisterVersioned", func() {
+		It("should register the statement named after the hash of the SQL query", func() {
+			name, err := statements.RegisterVersioned(ctx, "find_items", preparedSQL)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(name).To(Equal(VersionedStatementName("find_items", preparedSQL)))
+			Expect(name).To(MatchRegexp(`^find_items_[0-9a-f]{8}$`))
+			Expect(api.PreparedStatements("wg")).To(Equal([]string{name}))
+		})
+
+		It("should create new version for different SQL query", func() {
+			first, err := statements.RegisterVersioned(ctx, "find_items", "select 1")
+			Expect(err).ToNot(HaveOccurred())
+			second, err := statements.RegisterVersioned(ctx, "find_items", preparedSQL)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(second).ToNot(Equal(first))
+			Expect(api.PreparedStatements("wg")).To(ConsistOf(first, second))
+		})
+	})
+
+	Context("RemoveStaleVersions", func() {
+		It("should delete versions other than the current one", func() {
+			stale, err := statements.RegisterVersioned(ctx, "find_items", "select 1")
+			Expect(err).ToNot(HaveOccurred())
+			current, err := statements.RegisterVersioned(ctx, "find_items", preparedSQL)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(statements.Register(ctx, "find_items_other", "select 2")).To(Succeed())
+			Expect(statements.Register(ctx, "find_items", "select 3")).To(Succeed())
+
+			removed, err := statements.RemoveStaleVersions(ctx, "find_items")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(removed).To(Equal([]string{stale}))
+			Expect(api.PreparedStatements("wg")).To(ConsistOf("find_items", "find_items_other", current))
+		})
+
+		It("should return error if no version is registered", func() {
+			_, err := statements.RemoveStaleVersions(ctx, "find_items")
+			Expect(err).To(MatchError("prepared statement find_items has no registered version"))
+		})
+	})
+
+	Context("Query", func() {
+		BeforeEach(func() {
+			api.AddQuery("EXECUTE find_items USING 1, 'it''s'", athenafake.Query{Pages: newTestPages(1, 3)})
+		})
+
+		It("should execute the statement with the args", func() {
+			// arrange
+			statements = NewPreparedStatements(New(api, Config{WorkGroup: "wg", WaitInterval: time.Millisecond, InterpolateArgs: true}), api)
+			Expect(statements.Register(ctx, "find_items", preparedSQL)).To(Succeed())
+
+			// act
+			result, err := statements.Query(ctx, "find_items", reflect.TypeOf(testModel{}), 1, "it's")
+
+			// assert
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(HaveLen(3))
+			Expect(result[2]).To(Equal(&testModel{ID: 2, Name: "name 2"}))
+		})
+
+		It("should look up the statement not registered with the manager", func() {
+			api.AddQuery("EXECUTE find_items", athenafake.Query{Pages: newTestPages(1, 3)})
+			Expect(NewPreparedStatements(New(api, Config{WorkGroup: "wg"}), api).Register(ctx, "find_items", preparedSQL)).To(Succeed())
+
+			var result []testModel
+			err := statements.QueryInto(ctx, "find_items", &result, 1, "it's")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(HaveLen(3))
+			Expect(api.Calls(athenafake.OpGetPreparedStatement)).To(Equal(2))
+			startInput, ok := api.StartInput("query-1")
+			Expect(ok).To(BeTrue())
+			Expect(startInput.ExecutionParameters).To(Equal([]string{"1", "'it''s'"}))
+		})
+
+		It("should resubmit transient failures of read-only statements", func() {
+			statements.client.config.RetryPolicy = &RetryPolicy{MaxAttempts: 2, Backoff: FixedPollStrategy{Interval: time.Millisecond}}
+			Expect(statements.Register(ctx, "find_items", preparedSQL)).To(Succeed())
+			api.AddQuerySequence("EXECUTE find_items", athenafake.Query{States: []types.QueryExecutionState{types.QueryExecutionStateFailed}, StateChangeReason: "Rate exceeded"}, athenafake.Query{Pages: newTestPages(1, 3)})
+
+			result, err := statements.Query(ctx, "find_items", reflect.TypeOf(testModel{}), 1, "it's")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(HaveLen(3))
+			Expect(api.Executions()).To(Equal([]string{"query-1", "query-2"}))
+		})
+
+		It("should not resubmit failures of other statements", func() {
+			statements.client.config.RetryPolicy = &RetryPolicy{MaxAttempts: 2, Backoff: FixedPollStrategy{Interval: time.Millisecond}}
+			Expect(statements.Register(ctx, "insert_items", "insert into t select ?")).To(Succeed())
+			api.AddQuerySequence("EXECUTE insert_items", athenafake.Query{States: []types.QueryExecutionState{types.QueryExecutionStateFailed}, StateChangeReason: "Rate exceeded"})
+
+			_, err := statements.Query(ctx, "insert_items", reflect.TypeOf(testModel{}), 1)
+			Expect(err).To(HaveOccurred())
+			Expect(api.Executions()).To(Equal([]string{"query-1"}))
+		})
+
+		It("should return error if the statement does not exist", func() {
+			_, err := statements.Query(ctx, "find_items", reflect.TypeOf(testModel{}), 1, "it's")
+			Expect(err).To(MatchError("prepared statement find_items not found in workgroup wg"))
+			Expect(api.Executions()).To(BeEmpty())
+		})
+
+		It("should return error for mismatched args count", func() {
+			Expect(statements.Register(ctx, "find_items", preparedSQL)).To(Succeed())
+			_, err := statements.Query(ctx, "find_items", reflect.TypeOf(testModel{}), 1)
+			Expect(err).To(MatchError("mismatched query args count: 1, expected: 2 placeholders"))
+		})
+	})
+
+	Context("bindArgs", func() {
+		BeforeEach(func() {
+			Expect(statements.Register(ctx, "find_items", preparedSQL)).To(Succeed())
+		})
+
+		It("should pass the args as execution parameters", func() {
+			stmt, err := statements.bindArgs(ctx, "find_items", []interface{}{1, "a"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stmt).To(Equal(statement{sql: "EXECUTE find_items", executionParameters: []string{"1", "'a'"}, preparedSQL: preparedSQL}))
+		})
+
+		It("should format the args into the USING clause if interpolated", func() {
+			statements.client.config.InterpolateArgs = true
+			stmt, err := statements.bindArgs(ctx, "find_items", []interface{}{1, "a"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stmt).To(Equal(statement{sql: "EXECUTE find_items USING 1, 'a'", preparedSQL: preparedSQL}))
+		})
+
+		It("should execute without USING clause if the statement has no placeholders", func() {
+			Expect(statements.Register(ctx, "all_items", "select id from t")).To(Succeed())
+			stmt, err := statements.bindArgs(ctx, "all_items", nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stmt).To(Equal(statement{sql: "EXECUTE all_items", preparedSQL: "select id from t"}))
+		})
+	})
+})
@@ -0,0 +1,152 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+)
+
+var (
+	// TransientErrorCodes are the API error codes of StartQueryExecution and GetQueryExecution considered transient
+	TransientErrorCodes = []string{
+		"ThrottlingException",
+		"TooManyRequestsException",
+		"InternalServerException",
+		"ServiceUnavailable",
+	}
+
+	// TransientFailureReasons are substrings of QueryExecution.Status.StateChangeReason considered transient, compared ignoring case
+	TransientFailureReasons = []string{
+		"ThrottlingException",
+		"Rate exceeded",
+		"Query exhausted resources at this scale factor",
+		"Please reduce your request rate",
+		"Slow Down",
+		"SlowDown",
+		"Internal error",
+		"INTERNAL_ERROR",
+	}
+
+	// idempotentStatementPrefixes are the leading keywords of read-only statements that are safe to resubmit
+	idempotentStatementPrefixes = []string{"select", "with", "show", "describe", "explain", "values"}
+)
+
+// RetryPolicy resubmits queries failing with transient errors
+type RetryPolicy struct {
+	// MaxAttempts is the max number of query executions including the first one, values below 2 disable retries
+	MaxAttempts int
+	// Backoff decides the wait before resubmitting, NextInterval is called with the number of the failed attempt.
+	// Defaults to ExponentialPollStrategy from 1 second up to 30 seconds.
+	Backoff PollStrategy
+	// IsRetryable classifies the error of a failed attempt, defaults to IsTransientError
+	IsRetryable func(err error) bool
+	// IsIdempotent decides whether the SQL query is safe to resubmit, defaults to IsIdempotentQuery.
+	// It is called with the SQL query of the prepared statement for the queries of PreparedStatements.
+	IsIdempotent func(sqlQuery string) bool
+}
+
+// IsTransientError returns true if the error is an API error with one of TransientErrorCodes,
+// or a failed query execution whose state change reason contains one of TransientFailureReasons
+func IsTransientError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var apiErr interface{ ErrorCode() string }
+	if errors.As(err, &apiErr) {
+		for _, code := range TransientErrorCodes {
+			if apiErr.ErrorCode() == code {
+				return true
+			}
+		}
+	}
+
+	var failure *QueryFailedError
+	if errors.As(err, &failure) && failure.State == types.QueryExecutionStateFailed {
+		reason := strings.ToLower(failure.StateChangeReason)
+		for _, transientReason := range TransientFailureReasons {
+			if strings.Contains(reason, strings.ToLower(transientReason)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// IsIdempotentQuery returns true if the SQL query is a read-only statement, e.g. SELECT, WITH or SHOW
+func IsIdempotentQuery(sqlQuery string) bool {
+	fields := strings.Fields(strings.TrimLeft(sqlQuery, "( \t\r\n"))
+	if len(fields) == 0 {
+		return false
+	}
+	keyword := strings.ToLower(fields[0])
+	for _, prefix := range idempotentStatementPrefixes {
+		if keyword == prefix {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *RetryPolicy) shouldRetry(attempt int, sqlQuery string, err error) bool {
+	if p == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+
+	isIdempotent := p.IsIdempotent
+	if isIdempotent == nil {
+		isIdempotent = IsIdempotentQuery
+	}
+	isRetryable := p.IsRetryable
+	if isRetryable == nil {
+		isRetryable = IsTransientError
+	}
+	return isIdempotent(sqlQuery) && isRetryable(err)
+}
+
+func (p *RetryPolicy) backoff(attempt int) time.Duration {
+	backoff := p.Backoff
+	if backoff == nil {
+		backoff = ExponentialPollStrategy{Initial: 1 * time.Second, Max: 30 * time.Second}
+	}
+	return backoff.NextInterval(attempt, nil)
+}
+
+// executeWithRetry executes the query and resubmits it according to the retry policy, returns the ID of the successful query execution
+func (c *Client) executeWithRetry(ctx context.Context, stmt statement) (string, error) {
+	queryExecutionIDs := make([]string, 0, 1)
+	for attempt := 1; ; attempt++ {
+		queryExecutionID, err := c.execute(ctx, stmt)
+		if queryExecutionID != "" {
+			queryExecutionIDs = append(queryExecutionIDs, queryExecutionID)
+		}
+		if err == nil {
+			return queryExecutionID, nil
+		}
+
+		// only resubmit if the query was not started or has failed, a query execution that could not be polled is stopped instead
+		var failure *QueryFailedError
+		resubmittable := queryExecutionID == "" || (errors.As(err, &failure) && failure.State == types.QueryExecutionStateFailed)
+		if !resubmittable || !c.config.RetryPolicy.shouldRetry(attempt, stmt.retrySQL(), err) {
+			return "", newRetryError(attempt, queryExecutionIDs, err)
+		}
+		if sleepErr := sleepContext(ctx, c.config.RetryPolicy.backoff(attempt)); sleepErr != nil {
+			// the context is done before resubmitting, keep the IDs of the failed attempts
+			return "", &RetryError{QueryExecutionIDs: queryExecutionIDs, Err: sleepErr}
+		}
+	}
+}
+
+// newRetryError wraps the error of the last attempt in RetryError if the query was resubmitted at least once
+func newRetryError(attempts int, queryExecutionIDs []string, err error) error {
+	if attempts <= 1 {
+		return err
+	}
+	return &RetryError{
+		QueryExecutionIDs: queryExecutionIDs,
+		Err:               err,
+	}
+}
@@ -0,0 +1,167 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv/athenafake"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// testAPIError mimics the API errors of aws-sdk-go-v2 exposing ErrorCode
+type testAPIError struct {
+	code string
+}
+
+func (e *testAPIError) Error() string {
+	return "api error " + e.code
+}
+
+func (e *testAPIError) ErrorCode() string {
+	return e.code
+}
+
+var _ = Describe("Retry", func() {
+	var ctx context.Context
+	var api *athenafake.Fake
+	var athenaClient *Client
+
+	failed := func(reason string) athenafake.Query {
+		return athenafake.Query{
+			States:            []types.QueryExecutionState{types.QueryExecutionStateFailed},
+			StateChangeReason: reason,
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		api = athenafake.New()
+		athenaClient = New(api, Config{
+			WaitInterval: time.Millisecond,
+			RetryPolicy: &RetryPolicy{
+				MaxAttempts: 3,
+				Backoff:     FixedPollStrategy{Interval: time.Millisecond},
+			},
+		})
+	})
+
+	Context("IsTransientError", func() {
+		It("should classify failed query executions by state change reason", func() {
+			Expect(IsTransientError(&QueryFailedError{State: types.QueryExecutionStateFailed, StateChangeReason: "Query exhausted resources at this scale factor"})).To(BeTrue())
+			Expect(IsTransientError(&QueryFailedError{State: types.QueryExecutionStateFailed, StateChangeReason: "HIVE_CANNOT_OPEN_SPLIT: Error opening Hive split: Slow Down"})).To(BeTrue())
+			Expect(IsTransientError(&QueryFailedError{State: types.QueryExecutionStateFailed, StateChangeReason: "SYNTAX_ERROR: line 1:8: Column 'x' cannot be resolved"})).To(BeFalse())
+			Expect(IsTransientError(&QueryFailedError{State: types.QueryExecutionStateCancelled, StateChangeReason: "Rate exceeded"})).To(BeFalse())
+		})
+
+		It("should classify API errors by error code", func() {
+			Expect(IsTransientError(&testAPIError{code: "ThrottlingException"})).To(BeTrue())
+			Expect(IsTransientError(&testAPIError{code: "InvalidRequestException"})).To(BeFalse())
+		})
+
+		It("should not retry context errors", func() {
+			Expect(IsTransientError(context.Canceled)).To(BeFalse())
+			Expect(IsTransientError(&QueryContextError{Err: context.DeadlineExceeded})).To(BeFalse())
+		})
+	})
+
+	Context("IsIdempotentQuery", func() {
+		It("should accept read-only statements only", func() {
+			Expect(IsIdempotentQuery("  SELECT 1")).To(BeTrue())
+			Expect(IsIdempotentQuery("\nwith a as (select 1) select * from a")).To(BeTrue())
+			Expect(IsIdempotentQuery("(select 1) union (select 2)")).To(BeTrue())
+			Expect(IsIdempotentQuery("insert into t select 1")).To(BeFalse())
+			Expect(IsIdempotentQuery("create table t as select 1")).To(BeFalse())
+			Expect(IsIdempotentQuery("")).To(BeFalse())
+		})
+	})
+
+	Context("Query with retry policy", func() {
+		It("should resubmit transient failures until success", func() {
+			api.AddQuerySequence(testSQL, failed("ThrottlingException: Rate exceeded"), failed("Query exhausted resources at this scale factor"), athenafake.Query{Pages: newTestPages(1, 2)})
+
+			result, err := athenaClient.Query(ctx, testSQL, reflect.TypeOf(testModel{}))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(result)).To(Equal(2))
+			Expect(api.Executions()).To(Equal([]string{"query-1", "query-2", "query-3"}))
+		})
+
+		It("should return every attempt's execution ID when attempts are exhausted", func() {
+			api.AddQuerySequence(testSQL, failed("Rate exceeded"))
+
+			_, err := athenaClient.Query(ctx, testSQL, reflect.TypeOf(testModel{}))
+			Expect(err).To(HaveOccurred())
+			var retryErr *RetryError
+			Expect(errors.As(err, &retryErr)).To(BeTrue())
+			Expect(retryErr.QueryExecutionIDs).To(Equal([]string{"query-1", "query-2", "query-3"}))
+			Expect(err.Error()).To(ContainSubstring("after 3 attempts"))
+			Expect(err.Error()).To(ContainSubstring("query-3 failed with status: FAILED, reason: Rate exceeded"))
+		})
+
+		It("should resubmit when start query returns transient API error", func() {
+			api.AddQuery(testSQL, athenafake.Query{Pages: newTestPages(1, 1)})
+			api.FailNext(athenafake.OpStartQueryExecution, &testAPIError{code: "ThrottlingException"})
+
+			_, err := athenaClient.Query(ctx, testSQL, reflect.TypeOf(testModel{}))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(api.Calls(athenafake.OpStartQueryExecution)).To(Equal(2))
+			Expect(api.Executions()).To(Equal([]string{"query-1"}))
+		})
+
+		It("should poll the query execution again on transient API error instead of resubmitting", func() {
+			api.AddQuery(testSQL, athenafake.Query{Pages: newTestPages(1, 1)})
+			api.FailNext(athenafake.OpGetQueryExecution, &testAPIError{code: "ThrottlingException"})
+
+			_, err := athenaClient.Query(ctx, testSQL, reflect.TypeOf(testModel{}))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(api.Executions()).To(Equal([]string{"query-1"}))
+		})
+
+		It("should stop the query execution instead of resubmitting when polling fails", func() {
+			api.AddQuery(testSQL, athenafake.Query{Pages: newTestPages(1, 1)})
+			api.FailNext(athenafake.OpGetQueryExecution, &testAPIError{code: "AccessDeniedException"})
+
+			_, err := athenaClient.Query(ctx, testSQL, reflect.TypeOf(testModel{}))
+			var pollErr *QueryPollError
+			Expect(errors.As(err, &pollErr)).To(BeTrue())
+			Expect(pollErr.Stopped).To(BeTrue())
+			Expect(api.Stopped("query-1")).To(BeTrue())
+			Expect(api.Executions()).To(Equal([]string{"query-1"}))
+		})
+
+		It("should not resubmit non-transient failures", func() {
+			api.AddQuerySequence(testSQL, failed("SYNTAX_ERROR"))
+
+			_, err := athenaClient.Query(ctx, testSQL, reflect.TypeOf(testModel{}))
+			Expect(err).To(HaveOccurred())
+			var retryErr *RetryError
+			Expect(errors.As(err, &retryErr)).To(BeFalse())
+			Expect(err.Error()).To(ContainSubstring("reason: SYNTAX_ERROR"))
+			Expect(api.Executions()).To(Equal([]string{"query-1"}))
+		})
+
+		It("should not resubmit non-idempotent queries", func() {
+			api.AddQuerySequence("insert into t select 1", failed("Rate exceeded"))
+
+			_, err := athenaClient.Query(ctx, "insert into t select 1", reflect.TypeOf(testModel{}))
+			Expect(err).To(HaveOccurred())
+			Expect(api.Executions()).To(Equal([]string{"query-1"}))
+		})
+
+		It("should stop resubmitting when the context is done", func() {
+			api.AddQuerySequence(testSQL, failed("Rate exceeded"))
+			athenaClient.config.RetryPolicy.Backoff = FixedPollStrategy{Interval: time.Minute}
+			timeoutCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+			defer cancel()
+
+			_, err := athenaClient.Query(timeoutCtx, testSQL, reflect.TypeOf(testModel{}))
+			Expect(errors.Is(err, context.DeadlineExceeded)).To(BeTrue())
+			var retryErr *RetryError
+			Expect(errors.As(err, &retryErr)).To(BeTrue())
+			Expect(retryErr.QueryExecutionIDs).To(Equal([]string{"query-1"}))
+		})
+	})
+})
@@ -0,0 +1,109 @@
+// Command athenaconv-gen generates Go structs with athenaconv tags from a saved GetQueryResults JSON output,
+// a CREATE TABLE DDL file or a GetTableMetadata JSON output.
+// With -mapper, it generates the reflection-free row mapper of a struct declared in the package directory instead.
+//
+// Usage:
+//
+//	athenaconv-gen -input events.json -struct Event -package models -output event_gen.go
+//
+// or with go generate:
+//
+//	//go:generate go run github.com/kent-id/athenaconv/cmd/athenaconv-gen -input events.sql -struct Event -output event_gen.go
+//	//go:generate go run github.com/kent-id/athenaconv/cmd/athenaconv-gen -mapper -struct Event -output event_mapper_gen.go
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/kent-id/athenaconv/codegen"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "athenaconv-gen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("athenaconv-gen", flag.ContinueOnError)
+	input := flags.String("input", "", "input file, reads stdin if empty")
+	source := flags.String("source", string(codegen.SourceAuto), "input kind: auto, results (GetQueryResults JSON), ddl (CREATE TABLE) or table (GetTableMetadata JSON)")
+	output := flags.String("output", "", "output Go file, writes stdout if empty")
+	structName := flags.String("struct", "", "name of the generated struct (required)")
+	packageName := flags.String("package", "", "package of the generated file, defaults to $GOPACKAGE with go generate, otherwise models")
+	naming := flags.String("naming", string(codegen.NamingGo), "naming style of the fields: go (SourceID) or pascal (SourceId)")
+	extractNested := flags.Bool("extract-nested", false, "generate named types for struct columns instead of anonymous structs")
+	noPointers := flags.Bool("no-pointers", false, "generate value fields for nullable columns instead of pointers")
+	complexMode := flags.String("complex", string(codegen.ComplexString), "types of map and struct columns: string (text of the values) or json (typed values, selected with CastComplexToJSON)")
+	mapper := flags.Bool("mapper", false, "generate the row mapper of the struct declared in the package directory -input, defaults to the current directory")
+	funcName := flags.String("func", "", "name of the generated row mapper function with -mapper, defaults to Map<struct>AthenaRow")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *mapper {
+		dir := *input
+		if dir == "" {
+			dir = "."
+		}
+		generated, err := codegen.GenerateMapper(dir, codegen.MapperOptions{StructName: *structName, FuncName: *funcName})
+		if err != nil {
+			return err
+		}
+		return writeOutput(generated, *output, stdout)
+	}
+
+	if *packageName == "" {
+		*packageName = os.Getenv("GOPACKAGE")
+	}
+	if *naming != string(codegen.NamingGo) && *naming != string(codegen.NamingPascal) {
+		err := fmt.Errorf("invalid naming style: %s, expecting go or pascal", *naming)
+		return err
+	}
+
+	reader := stdin
+	description := "stdin"
+	if *input != "" {
+		file, err := os.Open(*input)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		reader = file
+		description = filepath.Base(*input)
+	}
+
+	columns, err := codegen.ReadColumns(reader, codegen.Source(*source))
+	if err != nil {
+		return err
+	}
+	generated, err := codegen.Generate(columns, codegen.Options{
+		StructName:    *structName,
+		PackageName:   *packageName,
+		Naming:        codegen.NamingStyle(*naming),
+		ExtractNested: *extractNested,
+		NoPointers:    *noPointers,
+		Complex:       codegen.ComplexMode(*complexMode),
+		Source:        description,
+	})
+	if err != nil {
+		return err
+	}
+
+	return writeOutput(generated, *output, stdout)
+}
+
+// writeOutput writes the generated source to the output file, or to stdout if empty
+func writeOutput(generated []byte, output string, stdout io.Writer) error {
+	if output == "" {
+		_, err := stdout.Write(generated)
+		return err
+	}
+	return ioutil.WriteFile(output, generated, 0644)
+}
@@ -0,0 +1,237 @@
+// Command athenaconv converts saved GetQueryResults JSON outputs, e.g. of aws athena get-query-results, into CSV,
+// JSON Lines or an aligned table, with the typed conversions of the athenaconv mappers. It works offline.
+//
+// Usage:
+//
+//	aws athena get-query-results --query-execution-id <id> > results.json
+//	athenaconv -format jsonl -columns id,name results.json
+//
+// Several files, or concatenated pages, are converted as pages of the same result set. Stdin is read if no file is given.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv"
+	"github.com/kent-id/athenaconv/util"
+)
+
+// mapperTypes are the athena types converted by the athenaconv mappers, the columns of other types, e.g. decimal or map,
+// are passed to the mapper as varchar so that their values are output as text, without the warning logged for each value
+var mapperTypes = map[string]bool{
+	"boolean":   true,
+	"varchar":   true,
+	"tinyint":   true,
+	"smallint":  true,
+	"integer":   true,
+	"bigint":    true,
+	"double":    true,
+	"float":     true,
+	"real":      true,
+	"array":     true,
+	"timestamp": true,
+	"date":      true,
+}
+
+// column is a result set column of the output
+type column struct {
+	athenaconv.ColumnMetadata
+	// key is the name keyed by its occurrence if duplicate, e.g. id#2, used in the JSON Lines output, see athenaconv.ColumnKeys
+	key string
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "athenaconv:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("athenaconv", flag.ContinueOnError)
+	format := flags.String("format", formatTable, "output format: csv, jsonl (JSON Lines) or table")
+	selection := flags.String("columns", "", "comma separated columns to output, by name, name#occurrence or #position, defaults to all columns")
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			// usage is already printed
+			return nil
+		}
+		return err
+	}
+
+	writer, err := newRowWriter(*format, stdout)
+	if err != nil {
+		return err
+	}
+	pages, err := readPages(flags.Args(), stdin)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	columns, err := resultSetColumns(ctx, pages[0].ResultSetMetadata)
+	if err != nil {
+		return err
+	}
+	selected, err := selectColumns(columns, *selection)
+	if err != nil {
+		return err
+	}
+	if err := writer.writeHeader(selected); err != nil {
+		return err
+	}
+
+	// the header row is detected on all the columns of the page, before the selected columns are converted
+	mapper := athenaconv.NewDynamicMapper(athenaconv.WithHeaderRow(athenaconv.HeaderRowAbsent))
+	for i, page := range pages {
+		if err := validatePageColumns(columns, page.ResultSetMetadata); err != nil {
+			err := fmt.Errorf("page %d: %v", i+1, err)
+			return err
+		}
+		selectedPage, err := selectedResultSet(page, selected)
+		if err != nil {
+			err := fmt.Errorf("page %d: %v", i+1, err)
+			return err
+		}
+		records, err := mapper.RecordsFromAthenaResultSetV2(ctx, selectedPage)
+		if err != nil {
+			err := fmt.Errorf("page %d: %v", i+1, err)
+			return err
+		}
+		for _, record := range records {
+			if err := writer.writeRow(record.Values); err != nil {
+				return err
+			}
+		}
+	}
+	return writer.flush()
+}
+
+// readPages reads the result set pages of the files, or of stdin if no file is given
+func readPages(files []string, stdin io.Reader) ([]*types.ResultSet, error) {
+	if len(files) == 0 {
+		return athenaconv.ReadQueryResults(stdin)
+	}
+
+	pages := make([]*types.ResultSet, 0, len(files))
+	for _, name := range files {
+		file, err := os.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		filePages, err := athenaconv.ReadQueryResults(file)
+		file.Close()
+		if err != nil {
+			err := fmt.Errorf("%s: %v", name, err)
+			return nil, err
+		}
+		pages = append(pages, filePages...)
+	}
+	return pages, nil
+}
+
+// resultSetColumns returns the ordered columns of the result set metadata, duplicate names are keyed by their occurrence
+func resultSetColumns(ctx context.Context, metadata *types.ResultSetMetadata) ([]column, error) {
+	columnMetadata, err := athenaconv.ResultSetColumns(ctx, metadata)
+	if err != nil {
+		return nil, err
+	}
+	columns := make([]column, 0, len(columnMetadata))
+	for i, key := range athenaconv.ColumnKeys(columnMetadata) {
+		columns = append(columns, column{ColumnMetadata: columnMetadata[i], key: key})
+	}
+	return columns, nil
+}
+
+// selectColumns returns the columns bound by the comma separated column keys, see athenaconv.LookupColumn, or all columns if empty
+func selectColumns(columns []column, selection string) ([]column, error) {
+	if strings.TrimSpace(selection) == "" {
+		return columns, nil
+	}
+
+	columnMetadata := make([]athenaconv.ColumnMetadata, len(columns))
+	for i, column := range columns {
+		columnMetadata[i] = column.ColumnMetadata
+	}
+	selected := make([]column, 0)
+	for _, key := range strings.Split(selection, ",") {
+		key = strings.TrimSpace(key)
+		index, ok := athenaconv.LookupColumn(columnMetadata, key)
+		if !ok {
+			err := fmt.Errorf("column '%s' not found in result set", key)
+			return nil, err
+		}
+		selected = append(selected, columns[index])
+	}
+	return selected, nil
+}
+
+// validatePageColumns returns error if the columns of the page differ from the columns of the first page
+func validatePageColumns(columns []column, metadata *types.ResultSetMetadata) error {
+	if len(metadata.ColumnInfo) != len(columns) {
+		err := fmt.Errorf("mismatched result set columns count, expecting %d but got %d", len(columns), len(metadata.ColumnInfo))
+		return err
+	}
+	for i, columnInfo := range metadata.ColumnInfo {
+		if util.SafeString(columnInfo.Name) != columns[i].Name || util.SafeString(columnInfo.Type) != columns[i].Type {
+			err := fmt.Errorf("mismatched result set column %d, expecting %s %s but got %s %s",
+				i, columns[i].Name, columns[i].Type, util.SafeString(columnInfo.Name), util.SafeString(columnInfo.Type))
+			return err
+		}
+	}
+	return nil
+}
+
+// selectedResultSet returns the page with the data of the selected columns only, without the header row,
+// so that the other columns are not converted
+func selectedResultSet(page *types.ResultSet, selected []column) (*types.ResultSet, error) {
+	columnInfo := make([]types.ColumnInfo, len(selected))
+	for i, column := range selected {
+		columnInfo[i] = page.ResultSetMetadata.ColumnInfo[column.Index]
+		if !mapperTypes[column.Type] {
+			columnInfo[i].Type = util.RefString("varchar")
+		}
+	}
+
+	rows := page.Rows
+	if len(rows) > 0 && isHeaderRow(rows[0], page.ResultSetMetadata) {
+		rows = rows[1:]
+	}
+	selectedRows := make([]types.Row, 0, len(rows))
+	for rowIndex, row := range rows {
+		if len(row.Data) != len(page.ResultSetMetadata.ColumnInfo) {
+			err := fmt.Errorf("mismatched row data and result set columns count, row: %d, rowDataLength: %d, columnsLength: %d",
+				rowIndex, len(row.Data), len(page.ResultSetMetadata.ColumnInfo))
+			return nil, err
+		}
+		data := make([]types.Datum, len(selected))
+		for i, column := range selected {
+			data[i] = row.Data[column.Index]
+		}
+		selectedRows = append(selectedRows, types.Row{Data: data})
+	}
+
+	return &types.ResultSet{
+		ResultSetMetadata: &types.ResultSetMetadata{ColumnInfo: columnInfo},
+		Rows:              selectedRows,
+	}, nil
+}
+
+// isHeaderRow returns true if the values of the row equal the column names, as detected by the athenaconv mappers
+func isHeaderRow(row types.Row, metadata *types.ResultSetMetadata) bool {
+	if len(row.Data) != len(metadata.ColumnInfo) {
+		return false
+	}
+	for i, columnInfo := range metadata.ColumnInfo {
+		if row.Data[i].VarCharValue == nil || *row.Data[i].VarCharValue != util.SafeString(columnInfo.Name) {
+			return false
+		}
+	}
+	return true
+}
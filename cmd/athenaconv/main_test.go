@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("athenaconv", func() {
+	const metadata = `"ResultSetMetadata": {"ColumnInfo": [
+		{"Name": "id", "Type": "integer"}, {"Name": "name", "Type": "varchar"}, {"Name": "tags", "Type": "array"},
+		{"Name": "score", "Type": "double"}, {"Name": "day", "Type": "date"}, {"Name": "id", "Type": "bigint"}
+	]}`
+	const firstPage = `{"ResultSet": {"Rows": [
+		{"Data": [{"VarCharValue": "id"}, {"VarCharValue": "name"}, {"VarCharValue": "tags"}, {"VarCharValue": "score"}, {"VarCharValue": "day"}, {"VarCharValue": "id"}]},
+		{"Data": [{"VarCharValue": "1"}, {"VarCharValue": "first, \"one\""}, {"VarCharValue": "[a, b]"}, {"VarCharValue": "1.5"}, {"VarCharValue": "2021-12-31"}, {"VarCharValue": "9"}]}
+	], ` + metadata + `}}`
+	const secondPage = `{"ResultSet": {"Rows": [
+		{"Data": [{"VarCharValue": "2"}, {}, {"VarCharValue": "[]"}, {"VarCharValue": "NaN"}, {"VarCharValue": "2022-01-01"}, {}]}
+	], ` + metadata + `}}`
+
+	convert := func(input string, args ...string) (string, error) {
+		var output bytes.Buffer
+		err := run(args, strings.NewReader(input), &output)
+		return output.String(), err
+	}
+
+	It("should write aligned table by default", func() {
+		output, err := convert(firstPage + secondPage)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output).To(Equal(`id | name         | tags   | score | day        | id
+---+--------------+--------+-------+------------+-----
+1  | first, "one" | [a, b] | 1.5   | 2021-12-31 | 9
+2  | NULL         | []     | NaN   | 2022-01-01 | NULL
+`))
+	})
+
+	It("should write CSV", func() {
+		output, err := convert(firstPage+secondPage, "-format", "csv")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output).To(Equal(`id,name,tags,score,day,id
+1,"first, ""one""","[a, b]",1.5,2021-12-31,9
+2,,[],NaN,2022-01-01,
+`))
+	})
+
+	It("should write JSON Lines with typed values", func() {
+		output, err := convert(firstPage+secondPage, "-format", "jsonl")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output).To(Equal(`{"id":1,"name":"first, \"one\"","tags":["a","b"],"score":1.5,"day":"2021-12-31","id#2":9}
+{"id":2,"name":null,"tags":[],"score":"NaN","day":"2022-01-01","id#2":null}
+`))
+	})
+
+	It("should write tinyint, smallint and decimal values as JSON numbers without logging", func() {
+		var logs bytes.Buffer
+		log.SetOutput(&logs)
+		defer log.SetOutput(os.Stderr)
+
+		output, err := convert(`{"ResultSet": {"Rows": [
+			{"Data": [{"VarCharValue": "-3"}, {"VarCharValue": "300"}, {"VarCharValue": "12345678901234567890.05"}]}
+		], "ResultSetMetadata": {"ColumnInfo": [
+			{"Name": "a", "Type": "tinyint"}, {"Name": "b", "Type": "smallint"}, {"Name": "c", "Type": "decimal", "Precision": 22, "Scale": 2}
+		]}}}`, "-format", "jsonl")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output).To(Equal(`{"a":-3,"b":300,"c":12345678901234567890.05}` + "\n"))
+		Expect(logs.String()).To(BeEmpty())
+	})
+
+	It("should convert the selected columns only and write complex values as text without logging", func() {
+		var logs bytes.Buffer
+		log.SetOutput(&logs)
+		defer log.SetOutput(os.Stderr)
+
+		output, err := convert(`{"ResultSet": {"Rows": [
+			{"Data": [{"VarCharValue": "id"}, {"VarCharValue": "attributes"}, {"VarCharValue": "day"}]},
+			{"Data": [{"VarCharValue": "1"}, {"VarCharValue": "{a=1}"}, {"VarCharValue": "not a date"}]},
+			{"Data": [{"VarCharValue": "2"}, {"VarCharValue": "{}"}, {"VarCharValue": "not a date"}]}
+		], "ResultSetMetadata": {"ColumnInfo": [
+			{"Name": "id", "Type": "integer"}, {"Name": "attributes", "Type": "map"}, {"Name": "day", "Type": "date"}
+		]}}}`, "-format", "csv", "-columns", "attributes,id")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output).To(Equal("attributes,id\n{a=1},1\n{},2\n"))
+		Expect(logs.String()).To(BeEmpty())
+	})
+
+	It("should write the selected columns", func() {
+		output, err := convert(firstPage, "-format", "jsonl", "-columns", "name, id#2,#0,id#1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output).To(Equal(`{"name":"first, \"one\"","id#2":9,"id":1,"id":1}` + "\n"))
+
+		_, err = convert(firstPage, "-columns", "missing")
+		Expect(err).To(MatchError("column 'missing' not found in result set"))
+	})
+
+	It("should read the pages of the files", func() {
+		dir, err := ioutil.TempDir("", "athenaconv")
+		Expect(err).ToNot(HaveOccurred())
+		defer os.RemoveAll(dir)
+		Expect(ioutil.WriteFile(filepath.Join(dir, "1.json"), []byte(firstPage), 0644)).To(Succeed())
+		Expect(ioutil.WriteFile(filepath.Join(dir, "2.json"), []byte(secondPage), 0644)).To(Succeed())
+
+		output, err := convert("", "-format", "csv", "-columns", "id", filepath.Join(dir, "1.json"), filepath.Join(dir, "2.json"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output).To(Equal("id\n1\n2\n"))
+	})
+
+	It("should print usage without error for -h", func() {
+		output, err := convert(firstPage, "-h")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output).To(BeEmpty())
+	})
+
+	It("should return error for invalid input", func() {
+		_, err := convert(firstPage, "-format", "xml")
+		Expect(err).To(MatchError("invalid format: xml, expecting csv, jsonl or table"))
+
+		_, err = convert(firstPage + strings.Replace(secondPage, `"Type": "date"`, `"Type": "varchar"`, 1))
+		Expect(err).To(MatchError("page 2: mismatched result set column 4, expecting day date but got day varchar"))
+
+		_, err = convert(strings.Replace(firstPage, `"1.5"`, `"abc"`, 1))
+		Expect(err).To(MatchError(ContainSubstring("page 1: ")))
+	})
+})
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	formatCSV   = "csv"
+	formatJSONL = "jsonl"
+	formatTable = "table"
+
+	// athena text formats of timestamp and date values
+	timestampLayout = "2006-01-02 15:04:05.000"
+	dateLayout      = "2006-01-02"
+)
+
+// rowWriter writes the converted rows in an output format
+type rowWriter interface {
+	writeHeader(columns []column) error
+	writeRow(values []interface{}) error
+	flush() error
+}
+
+func newRowWriter(format string, writer io.Writer) (rowWriter, error) {
+	switch format {
+	case formatCSV:
+		return &csvWriter{writer: csv.NewWriter(writer)}, nil
+	case formatJSONL:
+		return &jsonlWriter{writer: writer}, nil
+	case formatTable:
+		return &tableWriter{writer: writer}, nil
+	}
+	err := fmt.Errorf("invalid format: %s, expecting %s, %s or %s", format, formatCSV, formatJSONL, formatTable)
+	return nil, err
+}
+
+// csvWriter writes the column names followed by the rows, NULL is written as empty field
+type csvWriter struct {
+	writer  *csv.Writer
+	columns []column
+}
+
+func (w *csvWriter) writeHeader(columns []column) error {
+	w.columns = columns
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = column.Name
+	}
+	return w.writer.Write(names)
+}
+
+func (w *csvWriter) writeRow(values []interface{}) error {
+	fields := make([]string, len(values))
+	for i, value := range values {
+		if value != nil {
+			fields[i] = textValue(value, w.columns[i].Type)
+		}
+	}
+	return w.writer.Write(fields)
+}
+
+func (w *csvWriter) flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// jsonlWriter writes every row as JSON object of column key to value, in column order
+type jsonlWriter struct {
+	writer  io.Writer
+	columns []column
+}
+
+func (w *jsonlWriter) writeHeader(columns []column) error {
+	w.columns = columns
+	return nil
+}
+
+func (w *jsonlWriter) writeRow(values []interface{}) error {
+	var line bytes.Buffer
+	line.WriteByte('{')
+	for i, value := range values {
+		if i > 0 {
+			line.WriteByte(',')
+		}
+		key, err := json.Marshal(w.columns[i].key)
+		if err != nil {
+			return err
+		}
+		encoded, err := json.Marshal(jsonValue(value, w.columns[i].Type))
+		if err != nil {
+			return err
+		}
+		line.Write(key)
+		line.WriteByte(':')
+		line.Write(encoded)
+	}
+	line.WriteString("}\n")
+	_, err := w.writer.Write(line.Bytes())
+	return err
+}
+
+func (w *jsonlWriter) flush() error {
+	return nil
+}
+
+// tableWriter buffers the rows to write them as table with aligned columns, NULL is written as NULL
+type tableWriter struct {
+	writer io.Writer
+	rows   [][]string
+	types  []string
+}
+
+func (w *tableWriter) writeHeader(columns []column) error {
+	names := make([]string, len(columns))
+	w.types = make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = column.Name
+		w.types[i] = column.Type
+	}
+	w.rows = append(w.rows, names)
+	return nil
+}
+
+func (w *tableWriter) writeRow(values []interface{}) error {
+	cells := make([]string, len(values))
+	for i, value := range values {
+		cells[i] = "NULL"
+		if value != nil {
+			// keep one line per row
+			cells[i] = strings.NewReplacer("\n", `\n`, "\r", `\r`, "\t", `\t`).Replace(textValue(value, w.types[i]))
+		}
+	}
+	w.rows = append(w.rows, cells)
+	return nil
+}
+
+func (w *tableWriter) flush() error {
+	widths := make([]int, len(w.types))
+	for _, row := range w.rows {
+		for i, cell := range row {
+			if width := utf8.RuneCountInString(cell); width > widths[i] {
+				widths[i] = width
+			}
+		}
+	}
+
+	var table bytes.Buffer
+	for rowIndex, row := range w.rows {
+		for i, cell := range row {
+			if i > 0 {
+				table.WriteString(" | ")
+			}
+			table.WriteString(cell)
+			if i < len(row)-1 {
+				table.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell)))
+			}
+		}
+		table.WriteString("\n")
+
+		if rowIndex == 0 {
+			separators := make([]string, len(widths))
+			for i, width := range widths {
+				separators[i] = strings.Repeat("-", width)
+			}
+			table.WriteString(strings.Join(separators, "-+-") + "\n")
+		}
+	}
+	_, err := w.writer.Write(table.Bytes())
+	return err
+}
+
+// textValue returns the converted value as text in the format of athena, e.g. [a, b] for arrays
+func textValue(value interface{}, athenaType string) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return formatFloat(v)
+	case []string:
+		return "[" + strings.Join(v, ", ") + "]"
+	case time.Time:
+		if athenaType == "date" {
+			return v.Format(dateLayout)
+		}
+		return v.Format(timestampLayout)
+	}
+	return fmt.Sprint(value)
+}
+
+// jsonValue returns the converted value as JSON value: numbers, booleans and arrays are kept, decimal text is converted
+// to JSON numbers, timestamps, dates and the floats not representable in JSON are converted to text
+func jsonValue(value interface{}, athenaType string) interface{} {
+	switch v := value.(type) {
+	case string:
+		if athenaType == "decimal" {
+			// passed to the mapper as varchar, kept without loss of precision
+			return json.Number(v)
+		}
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return formatFloat(v)
+		}
+	case time.Time:
+		return textValue(v, athenaType)
+	}
+	return value
+}
+
+// formatFloat formats the float without exponent, NaN and infinities as athena: NaN, Infinity and -Infinity
+func formatFloat(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "Infinity"
+	case math.IsInf(value, -1):
+		return "-Infinity"
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,291 @@
+// Package codegen generates Go structs with athenaconv tags from the columns of athena result sets, DDL or table metadata.
+// It is used by the athenaconv-gen command.
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/token"
+	"strconv"
+	"strings"
+)
+
+const defaultPackageName = "models"
+
+// ComplexMode decides the Go types of the map, struct and row columns, and of the arrays of them
+type ComplexMode string
+
+const (
+	// ComplexString generates string fields holding the text of the complex columns, which the mappers convert without any cast
+	ComplexString ComplexMode = "string"
+	// ComplexJSON generates typed Go maps, structs and slices, decoded from JSON by the mappers.
+	// The columns must be selected as JSON, see athenaconv.CastComplexToJSON.
+	ComplexJSON ComplexMode = "json"
+)
+
+// Column is a column of the generated struct
+type Column struct {
+	// Name of the column, used in the athenaconv tag
+	Name string
+	// Type is the athena type of the column, e.g. varchar, bigint, array<string> or struct<city:string>
+	Type string
+	// Nullable columns are generated as pointer fields unless Options.NoPointers is set, except slices and maps
+	Nullable bool
+	// Comment of the column, added to the field, optional
+	Comment string
+}
+
+// Options configures the generated Go source
+type Options struct {
+	// StructName is the name of the generated struct
+	StructName string
+	// PackageName of the generated source, defaults to models
+	PackageName string
+	// Naming derives the field names from the column names, defaults to NamingGo
+	Naming NamingStyle
+	// ExtractNested generates named types for the struct and row columns, e.g. EventLocation for column location of Event,
+	// instead of anonymous struct types
+	ExtractNested bool
+	// NoPointers generates value fields for nullable columns, NULL is then converted to the zero value
+	NoPointers bool
+	// Complex decides the Go types of the complex columns, defaults to ComplexString
+	Complex ComplexMode
+	// Source is the description of where the columns are read from, added to the doc comment of the struct, optional
+	Source string
+}
+
+// Generate returns the formatted Go source of the struct of the columns.
+// The field types follow the conversion of the athenaconv mappers: varchar as string, integer as int, bigint as int64,
+// double as float64, boolean as bool, timestamp and date as time.Time, arrays of scalar types as []string,
+// and other scalar types as string. Maps, structs and arrays of them are generated as string, or with ComplexJSON
+// as typed Go maps, structs and slices, which the mappers decode from JSON, see athenaconv.CastComplexToJSON.
+//
+// Example:
+//
+//	source, err := codegen.Generate(columns, codegen.Options{StructName: "Event", PackageName: "models"})
+func Generate(columns []Column, options Options) ([]byte, error) {
+	if options.PackageName == "" {
+		options.PackageName = defaultPackageName
+	}
+	if options.Naming == "" {
+		options.Naming = NamingGo
+	}
+	if options.Complex == "" {
+		options.Complex = ComplexString
+	}
+	if !token.IsIdentifier(options.StructName) || !token.IsExported(options.StructName) {
+		err := fmt.Errorf("invalid struct name: '%s', expecting exported Go identifier", options.StructName)
+		return nil, err
+	}
+	if !token.IsIdentifier(options.PackageName) {
+		err := fmt.Errorf("invalid package name: '%s'", options.PackageName)
+		return nil, err
+	}
+	if options.Complex != ComplexString && options.Complex != ComplexJSON {
+		err := fmt.Errorf("invalid complex mode: '%s', expecting string or json", options.Complex)
+		return nil, err
+	}
+	if len(columns) == 0 {
+		err := fmt.Errorf("at least one column is required to generate struct %s", options.StructName)
+		return nil, err
+	}
+
+	g := &generator{
+		options:   options,
+		imports:   make(map[string]bool),
+		typeNames: uniqueNames{options.StructName: 1},
+	}
+	body, err := g.structType(options.StructName, columns)
+	if err != nil {
+		return nil, err
+	}
+
+	var source bytes.Buffer
+	source.WriteString("// Code generated by athenaconv-gen. DO NOT EDIT.\n\n")
+	source.WriteString("package " + options.PackageName + "\n\n")
+	if g.imports["time"] {
+		source.WriteString("import \"time\"\n\n")
+	}
+	if options.Source != "" {
+		source.WriteString("// " + options.StructName + " is generated from " + options.Source + "\n")
+	}
+	source.WriteString("type " + options.StructName + " " + body + "\n")
+	for _, nested := range g.nested {
+		source.WriteString("\n" + nested + "\n")
+	}
+
+	formatted, err := format.Source(source.Bytes())
+	if err != nil {
+		err := fmt.Errorf("cannot format generated source: %v", err)
+		return nil, err
+	}
+	return formatted, nil
+}
+
+type generator struct {
+	options   Options
+	imports   map[string]bool
+	typeNames uniqueNames
+	// nested are the declarations of the extracted nested struct types
+	nested []string
+}
+
+// structType returns the struct type of the top level columns, whose types follow the conversion of the mappers
+func (g *generator) structType(structName string, columns []Column) (string, error) {
+	fieldNames := make(uniqueNames)
+	occurrences := make(map[string]int)
+	var body strings.Builder
+	body.WriteString("struct {\n")
+	for _, column := range columns {
+		if column.Name == "" {
+			err := fmt.Errorf("column name is empty, type: %s", column.Type)
+			return "", err
+		}
+		parsed, err := parseAthenaType(column.Type)
+		if err != nil {
+			return "", err
+		}
+
+		fieldName := fieldNames.add(goName(column.Name, g.options.Naming))
+		goType, err := g.columnType(parsed, structName+fieldName)
+		if err != nil {
+			return "", err
+		}
+		if column.Nullable && !g.options.NoPointers && !strings.HasPrefix(goType, "[]") && !strings.HasPrefix(goType, "map[") {
+			goType = "*" + goType
+		}
+
+		// duplicate column names are bound by occurrence, e.g. id#2
+		occurrences[column.Name]++
+		key := column.Name
+		if occurrences[column.Name] > 1 {
+			key += "#" + strconv.Itoa(occurrences[column.Name])
+		}
+		if column.Comment != "" {
+			body.WriteString("// " + fieldName + " " + strings.Join(strings.Fields(column.Comment), " ") + "\n")
+		}
+		body.WriteString(fieldName + " " + goType + " " + fieldTag(key) + "\n")
+	}
+	body.WriteString("}")
+	return body.String(), nil
+}
+
+// columnType returns the Go type of the top level column converted by the mappers
+func (g *generator) columnType(parsed *athenaType, typeName string) (string, error) {
+	if parsed.name == "array" && (!parsed.isComplex() || !parsed.params[0].isComplex()) {
+		// arrays of scalar types are converted from text, or from JSON if cast to JSON
+		return "[]string", nil
+	}
+	if parsed.isComplex() {
+		if g.options.Complex == ComplexString {
+			// text of the complex value, e.g. {city=Paris, zip=75001}
+			return "string", nil
+		}
+		return g.jsonType(parsed, typeName)
+	}
+
+	switch parsed.name {
+	case "boolean":
+		return "bool", nil
+	case "tinyint", "smallint", "integer", "int":
+		return "int", nil
+	case "bigint":
+		return "int64", nil
+	case "double", "float", "real":
+		return "float64", nil
+	case "timestamp", "date":
+		g.imports["time"] = true
+		return "time.Time", nil
+	}
+	// varchar and the types without conversion, e.g. decimal, are converted to string
+	return "string", nil
+}
+
+// jsonType returns the Go type of a value of complex column decoded from JSON by the mappers
+func (g *generator) jsonType(parsed *athenaType, typeName string) (string, error) {
+	switch parsed.name {
+	case "array":
+		if len(parsed.params) != 1 {
+			return "[]string", nil
+		}
+		elemType, err := g.jsonType(parsed.params[0], typeName)
+		if err != nil {
+			return "", err
+		}
+		return "[]" + elemType, nil
+	case "map":
+		if len(parsed.params) != 2 {
+			err := fmt.Errorf("invalid map type, expecting key and value types: %s", parsed.name)
+			return "", err
+		}
+		keyType, err := g.jsonType(parsed.params[0], typeName+"Key")
+		if err != nil {
+			return "", err
+		}
+		valueType, err := g.jsonType(parsed.params[1], typeName)
+		if err != nil {
+			return "", err
+		}
+		return "map[" + keyType + "]" + valueType, nil
+	case "struct", "row":
+		return g.nestedStructType(parsed, typeName)
+	case "boolean":
+		return "bool", nil
+	case "tinyint":
+		return "int8", nil
+	case "smallint":
+		return "int16", nil
+	case "integer", "int":
+		return "int32", nil
+	case "bigint":
+		return "int64", nil
+	case "float", "real":
+		return "float32", nil
+	case "double":
+		return "float64", nil
+	case "timestamp", "date":
+		g.imports["time"] = true
+		return "time.Time", nil
+	case "binary", "varbinary":
+		return "[]byte", nil
+	}
+	return "string", nil
+}
+
+// nestedStructType returns the anonymous struct type of the struct fields, or the name of the extracted struct type
+func (g *generator) nestedStructType(parsed *athenaType, typeName string) (string, error) {
+	if len(parsed.fields) == 0 {
+		// row type of result set metadata without fields
+		return "string", nil
+	}
+
+	fieldNames := make(uniqueNames)
+	var body strings.Builder
+	body.WriteString("struct {\n")
+	for _, field := range parsed.fields {
+		fieldName := fieldNames.add(goName(field.name, g.options.Naming))
+		fieldType, err := g.jsonType(field.fieldType, typeName+fieldName)
+		if err != nil {
+			return "", err
+		}
+		body.WriteString(fieldName + " " + fieldType + " " + fieldTag(field.name) + "\n")
+	}
+	body.WriteString("}")
+
+	if !g.options.ExtractNested {
+		return body.String(), nil
+	}
+	name := g.typeNames.add(typeName)
+	g.nested = append(g.nested, "// "+name+" is a nested struct type of "+g.options.StructName+"\ntype "+name+" "+body.String())
+	return name, nil
+}
+
+// fieldTag returns the athenaconv struct tag of the column key as Go string literal
+func fieldTag(key string) string {
+	tag := "athenaconv:" + strconv.Quote(key)
+	if strings.Contains(tag, "`") {
+		return strconv.Quote(tag)
+	}
+	return "`" + tag + "`"
+}
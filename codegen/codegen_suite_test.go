@@ -0,0 +1,13 @@
+package codegen_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+func TestCodegen(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Codegen Suite")
+}
@@ -0,0 +1,171 @@
+package codegen
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv"
+	"github.com/kent-id/athenaconv/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Generate", func() {
+	var columns []Column
+
+	BeforeEach(func() {
+		columns = []Column{
+			{Name: "id", Type: "integer", Comment: "the event\nid"},
+			{Name: "source_ids", Type: "array", Nullable: true},
+			{Name: "score", Type: "double", Nullable: true},
+			{Name: "created_at", Type: "timestamp"},
+			{Name: "amount", Type: "decimal(10,2)"},
+			{Name: "id", Type: "varchar"},
+		}
+	})
+
+	When("columns are scalar", func() {
+		It("should generate struct with the types converted by the mappers", func() {
+			source, err := Generate(columns, Options{StructName: "Event", PackageName: "events", Source: "events.json"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(source)).To(Equal(`// Code generated by athenaconv-gen. DO NOT EDIT.
+
+package events
+
+import "time"
+
+// Event is generated from events.json
+type Event struct {
+	// ID the event id
+	ID        int       ` + "`athenaconv:\"id\"`" + `
+	SourceIDs []string  ` + "`athenaconv:\"source_ids\"`" + `
+	Score     *float64  ` + "`athenaconv:\"score\"`" + `
+	CreatedAt time.Time ` + "`athenaconv:\"created_at\"`" + `
+	Amount    string    ` + "`athenaconv:\"amount\"`" + `
+	ID2       string    ` + "`athenaconv:\"id#2\"`" + `
+}
+`))
+		})
+
+		It("should generate value fields without pointers", func() {
+			source, err := Generate(columns, Options{StructName: "Event", NoPointers: true, Naming: NamingPascal})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(source)).To(ContainSubstring("package models\n"))
+			Expect(string(source)).To(ContainSubstring("Score     float64 "))
+			Expect(string(source)).To(ContainSubstring("SourceIds []string "))
+		})
+	})
+
+	When("columns are complex", func() {
+		BeforeEach(func() {
+			columns = []Column{
+				{Name: "counts", Type: "map<string,bigint>", Nullable: true},
+				{Name: "location", Type: "struct<city:string,tags:array<struct<k:string,v:int>>>", Nullable: true},
+			}
+		})
+
+		It("should generate string fields by default", func() {
+			columns = append(columns, Column{Name: "tags", Type: "array<varchar>"}, Column{Name: "items", Type: "array<row(k varchar, v integer)>"})
+			source, err := Generate(columns, Options{StructName: "Event"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(source)).To(ContainSubstring(`type Event struct {
+	Counts   *string  ` + "`athenaconv:\"counts\"`" + `
+	Location *string  ` + "`athenaconv:\"location\"`" + `
+	Tags     []string ` + "`athenaconv:\"tags\"`" + `
+	Items    string   ` + "`athenaconv:\"items\"`" + `
+}`))
+
+			// the generated types are mapped from the columns of a query without cast to JSON
+			type event struct {
+				Counts   *string  `athenaconv:"counts"`
+				Location *string  `athenaconv:"location"`
+				Tags     []string `athenaconv:"tags"`
+				Items    string   `athenaconv:"items"`
+			}
+			mapper, err := athenaconv.NewMapperFor(reflect.TypeOf(event{}), athenaconv.WithHeaderRow(athenaconv.HeaderRowAbsent))
+			Expect(err).ToNot(HaveOccurred())
+			mapped, err := mapper.FromAthenaResultSetV2(context.Background(), &types.ResultSet{
+				ResultSetMetadata: &types.ResultSetMetadata{ColumnInfo: []types.ColumnInfo{
+					{Name: util.RefString("counts"), Type: util.RefString("map")},
+					{Name: util.RefString("location"), Type: util.RefString("row")},
+					{Name: util.RefString("tags"), Type: util.RefString("array")},
+					{Name: util.RefString("items"), Type: util.RefString("array")},
+				}},
+				Rows: []types.Row{{Data: []types.Datum{
+					{VarCharValue: util.RefString("{a=1}")},
+					{VarCharValue: util.RefString("{city=Paris, tags=[]}")},
+					{VarCharValue: util.RefString("[x, y]")},
+					{VarCharValue: util.RefString("[{k=a, v=1}]")},
+				}}},
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mapped).To(Equal([]interface{}{&event{
+				Counts:   util.RefString("{a=1}"),
+				Location: util.RefString("{city=Paris, tags=[]}"),
+				Tags:     []string{"x", "y"},
+				Items:    "[{k=a, v=1}]",
+			}}))
+		})
+
+		It("should generate anonymous struct types decoded from JSON", func() {
+			source, err := Generate(columns, Options{StructName: "Event", Complex: ComplexJSON})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(source)).To(ContainSubstring(`type Event struct {
+	Counts   map[string]int64 ` + "`athenaconv:\"counts\"`" + `
+	Location *struct {
+		City string ` + "`athenaconv:\"city\"`" + `
+		Tags []struct {
+			K string ` + "`athenaconv:\"k\"`" + `
+			V int32  ` + "`athenaconv:\"v\"`" + `
+		} ` + "`athenaconv:\"tags\"`" + `
+	} ` + "`athenaconv:\"location\"`" + `
+}`))
+		})
+
+		It("should extract nested struct types", func() {
+			source, err := Generate(columns, Options{StructName: "Event", ExtractNested: true, Complex: ComplexJSON})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(source)).To(ContainSubstring("	Location *EventLocation "))
+			Expect(string(source)).To(ContainSubstring(`// EventLocationTags is a nested struct type of Event
+type EventLocationTags struct {`))
+			Expect(string(source)).To(ContainSubstring(`type EventLocation struct {
+	City string              ` + "`athenaconv:\"city\"`" + `
+	Tags []EventLocationTags ` + "`athenaconv:\"tags\"`" + `
+}`))
+		})
+	})
+
+	When("options or columns are invalid", func() {
+		It("should return error for unexported struct name", func() {
+			_, err := Generate(columns, Options{StructName: "event"})
+			Expect(err).To(MatchError("invalid struct name: 'event', expecting exported Go identifier"))
+		})
+
+		It("should return error for invalid package name", func() {
+			_, err := Generate(columns, Options{StructName: "Event", PackageName: "my-models"})
+			Expect(err).To(MatchError("invalid package name: 'my-models'"))
+		})
+
+		It("should return error for invalid complex mode", func() {
+			_, err := Generate(columns, Options{StructName: "Event", Complex: "text"})
+			Expect(err).To(MatchError("invalid complex mode: 'text', expecting string or json"))
+		})
+
+		It("should return error without columns", func() {
+			_, err := Generate(nil, Options{StructName: "Event"})
+			Expect(err).To(MatchError("at least one column is required to generate struct Event"))
+		})
+
+		It("should return error for invalid column type", func() {
+			_, err := Generate([]Column{{Name: "id", Type: "array<int"}}, Options{StructName: "Event"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("fieldTag", func() {
+		It("should quote the tag if the column name has backquote", func() {
+			Expect(fieldTag("a`b")).To(Equal(`"athenaconv:\"a` + "`" + `b\""`))
+		})
+	})
+})
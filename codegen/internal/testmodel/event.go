@@ -0,0 +1,33 @@
+// Package testmodel declares the model whose generated row mapper is tested against the reflective mapper
+package testmodel
+
+import "time"
+
+//go:generate go run ../../../cmd/athenaconv-gen -mapper -struct Event -output event_mapper_gen.go
+
+// Event covers the field types with inlined conversions and the fields converted by athenaconv.SetColumnValue
+type Event struct {
+	ID        int              `athenaconv:"id"`
+	Total     int64            `athenaconv:"total"`
+	Score     float64          `athenaconv:"score"`
+	Active    bool             `athenaconv:"active"`
+	Name      string           `athenaconv:"name"`
+	CreatedAt time.Time        `athenaconv:"created_at"`
+	Day       time.Time        `athenaconv:"day"`
+	Tags      []string         `athenaconv:"tags"`
+	Labels    []string         `athenaconv:"labels"`
+	ParentID  *int64           `athenaconv:"parent_id"`
+	Comment   *string          `athenaconv:"comment"`
+	Rate      *float64         `athenaconv:"rate"`
+	Enabled   *bool            `athenaconv:"enabled"`
+	Count     *int             `athenaconv:"count"`
+	DeletedAt *time.Time       `athenaconv:"deleted_at"`
+	Location  *Location        `athenaconv:"location"`
+	Counts    map[string]int64 `athenaconv:"counts"`
+}
+
+// Location is a struct column decoded from JSON
+type Location struct {
+	City string `athenaconv:"city"`
+	Zip  int    `athenaconv:"zip"`
+}
@@ -0,0 +1,159 @@
+// Code generated by athenaconv-gen. DO NOT EDIT.
+
+package testmodel
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv"
+	"github.com/kent-id/athenaconv/util"
+)
+
+func init() {
+	athenaconv.RegisterRowMapper(reflect.TypeOf(Event{}), func(row types.Row, idx athenaconv.ColumnIndex) (interface{}, error) {
+		return MapEventAthenaRow(row, idx)
+	})
+}
+
+// MapEventAthenaRow converts the row to Event with the conversions of the athenaconv mappers, without reflection
+func MapEventAthenaRow(row types.Row, idx athenaconv.ColumnIndex) (*Event, error) {
+	model := &Event{}
+	if i := idx.Index(0); i >= 0 {
+		converted, err := strconv.Atoi(util.SafeString(row.Data[i].VarCharValue))
+		if err != nil {
+			return nil, err
+		}
+		model.ID = converted
+	}
+	if i := idx.Index(1); i >= 0 {
+		converted, err := strconv.ParseInt(util.SafeString(row.Data[i].VarCharValue), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		model.Total = converted
+	}
+	if i := idx.Index(2); i >= 0 {
+		converted, err := strconv.ParseFloat(util.SafeString(row.Data[i].VarCharValue), 64)
+		if err != nil {
+			return nil, err
+		}
+		model.Score = converted
+	}
+	if i := idx.Index(3); i >= 0 {
+		model.Active = strings.ToLower(util.SafeString(row.Data[i].VarCharValue)) == "true"
+	}
+	if i := idx.Index(4); i >= 0 {
+		model.Name = util.SafeString(row.Data[i].VarCharValue)
+	}
+	if i := idx.Index(5); i >= 0 {
+		layout := "2006-01-02 15:04:05"
+		if idx.Type(5) == "date" {
+			layout = "2006-01-02"
+		}
+		converted, err := time.Parse(layout, util.SafeString(row.Data[i].VarCharValue))
+		if err != nil {
+			return nil, err
+		}
+		model.CreatedAt = converted
+	}
+	if i := idx.Index(6); i >= 0 {
+		layout := "2006-01-02 15:04:05"
+		if idx.Type(6) == "date" {
+			layout = "2006-01-02"
+		}
+		converted, err := time.Parse(layout, util.SafeString(row.Data[i].VarCharValue))
+		if err != nil {
+			return nil, err
+		}
+		model.Day = converted
+	}
+	if i := idx.Index(7); i >= 0 {
+		if idx.Type(7) == "array" {
+			values := strings.Trim(util.SafeString(row.Data[i].VarCharValue), "[]")
+			model.Tags = make([]string, 0)
+			if len(values) > 0 {
+				model.Tags = strings.Split(values, ", ")
+			}
+		} else if err := athenaconv.SetColumnValue(&model.Tags, row.Data[i], idx.Type(7)); err != nil {
+			return nil, err
+		}
+	}
+	if i := idx.Index(8); i >= 0 {
+		if idx.Type(8) == "array" {
+			values := strings.Trim(util.SafeString(row.Data[i].VarCharValue), "[]")
+			model.Labels = make([]string, 0)
+			if len(values) > 0 {
+				model.Labels = strings.Split(values, ", ")
+			}
+		} else if err := athenaconv.SetColumnValue(&model.Labels, row.Data[i], idx.Type(8)); err != nil {
+			return nil, err
+		}
+	}
+	if i := idx.Index(9); i >= 0 {
+		if value := row.Data[i].VarCharValue; value != nil {
+			converted, err := strconv.ParseInt(*value, 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			model.ParentID = &converted
+		}
+	}
+	if i := idx.Index(10); i >= 0 {
+		if value := row.Data[i].VarCharValue; value != nil {
+			converted := *value
+			model.Comment = &converted
+		}
+	}
+	if i := idx.Index(11); i >= 0 {
+		if value := row.Data[i].VarCharValue; value != nil {
+			converted, err := strconv.ParseFloat(*value, 64)
+			if err != nil {
+				return nil, err
+			}
+			model.Rate = &converted
+		}
+	}
+	if i := idx.Index(12); i >= 0 {
+		if value := row.Data[i].VarCharValue; value != nil {
+			converted := strings.ToLower(*value) == "true"
+			model.Enabled = &converted
+		}
+	}
+	if i := idx.Index(13); i >= 0 {
+		if value := row.Data[i].VarCharValue; value != nil {
+			converted, err := strconv.Atoi(*value)
+			if err != nil {
+				return nil, err
+			}
+			model.Count = &converted
+		}
+	}
+	if i := idx.Index(14); i >= 0 {
+		if value := row.Data[i].VarCharValue; value != nil {
+			layout := "2006-01-02 15:04:05"
+			if idx.Type(14) == "date" {
+				layout = "2006-01-02"
+			}
+			converted, err := time.Parse(layout, *value)
+			if err != nil {
+				return nil, err
+			}
+			model.DeletedAt = &converted
+		}
+	}
+	if i := idx.Index(15); i >= 0 {
+		if err := athenaconv.SetColumnValue(&model.Location, row.Data[i], idx.Type(15)); err != nil {
+			return nil, err
+		}
+	}
+	if i := idx.Index(16); i >= 0 {
+		if err := athenaconv.SetColumnValue(&model.Counts, row.Data[i], idx.Type(16)); err != nil {
+			return nil, err
+		}
+	}
+	return model, nil
+}
@@ -0,0 +1,321 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MapperOptions configures the generated row mapper
+type MapperOptions struct {
+	// StructName is the name of the model struct declared in the package
+	StructName string
+	// FuncName is the name of the generated function, defaults to Map<StructName>AthenaRow
+	FuncName string
+}
+
+// mapperField is a field of the model converted by the generated row mapper
+type mapperField struct {
+	// index of the field in the struct, as bound by athenaconv.ColumnIndex
+	index int
+	name  string
+	// kind is the Go type of the field with inlined conversion, e.g. int64 or *time.Time, empty to convert with athenaconv.SetColumnValue
+	kind string
+}
+
+// inlinedKinds are the field types whose conversion is inlined in the generated row mapper
+var inlinedKinds = map[string]bool{
+	"string": true, "bool": true, "int": true, "int64": true, "float64": true, "time.Time": true, "[]string": true,
+	"*string": true, "*bool": true, "*int": true, "*int64": true, "*float64": true, "*time.Time": true,
+}
+
+// GenerateMapper returns the formatted Go source of the row mapper of the struct declared in the Go files of dir,
+// which converts the rows of a ResultSet with direct field assignments instead of reflection.
+// The generated init function registers it with athenaconv.RegisterRowMapper, so that athenaconv.NewMapperFor uses it.
+//
+// Example:
+//
+//	source, err := codegen.GenerateMapper("./models", codegen.MapperOptions{StructName: "Event"})
+func GenerateMapper(dir string, options MapperOptions) ([]byte, error) {
+	if !token.IsIdentifier(options.StructName) {
+		err := fmt.Errorf("invalid struct name: '%s'", options.StructName)
+		return nil, err
+	}
+	if options.FuncName == "" {
+		options.FuncName = "Map" + strings.Title(options.StructName) + "AthenaRow"
+	}
+	if !token.IsIdentifier(options.FuncName) {
+		err := fmt.Errorf("invalid func name: '%s'", options.FuncName)
+		return nil, err
+	}
+
+	file, structType, err := findStruct(dir, options.StructName)
+	if err != nil {
+		return nil, err
+	}
+	fields := mapperFields(file, structType)
+	return generateMapper(file.Name.Name, fields, options)
+}
+
+// findStruct returns the struct type declared with the name in the non-test Go files of dir, and the file declaring it
+func findStruct(dir string, structName string) (*ast.File, *ast.StructType, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fileSet := token.NewFileSet()
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+			continue
+		}
+		file, err := parser.ParseFile(fileSet, filepath.Join(dir, name), nil, 0)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				typeSpec := spec.(*ast.TypeSpec)
+				if typeSpec.Name.Name != structName {
+					continue
+				}
+				structType, ok := typeSpec.Type.(*ast.StructType)
+				if !ok {
+					err := fmt.Errorf("type %s is not a struct", structName)
+					return nil, nil, err
+				}
+				return file, structType, nil
+			}
+		}
+	}
+	err = fmt.Errorf("struct %s not found in %s", structName, dir)
+	return nil, nil, err
+}
+
+// mapperFields returns the settable fields of the struct in declaration order, with their index in the struct
+func mapperFields(file *ast.File, structType *ast.StructType) []mapperField {
+	timePackage := importName(file, "time")
+	fields := make([]mapperField, 0)
+	index := 0
+	for _, field := range structType.Fields.List {
+		kind := fieldKind(field.Type, timePackage)
+		if len(field.Names) == 0 {
+			// embedded field, named after its type
+			fields = append(fields, mapperField{index: index, name: embeddedFieldName(field.Type)})
+			index++
+			continue
+		}
+		for _, name := range field.Names {
+			if name.Name != "_" {
+				fields = append(fields, mapperField{index: index, name: name.Name, kind: kind})
+			}
+			index++
+		}
+	}
+	return fields
+}
+
+// fieldKind returns the Go type of the field if its conversion is inlined, otherwise empty string
+func fieldKind(expr ast.Expr, timePackage string) string {
+	kind := ""
+	switch t := expr.(type) {
+	case *ast.Ident:
+		kind = t.Name
+	case *ast.SelectorExpr:
+		if pkg, ok := t.X.(*ast.Ident); ok && timePackage != "" && pkg.Name == timePackage && t.Sel.Name == "Time" {
+			kind = "time.Time"
+		}
+	case *ast.StarExpr:
+		if elem := fieldKind(t.X, timePackage); elem != "" {
+			kind = "*" + elem
+		}
+	case *ast.ArrayType:
+		if elem, ok := t.Elt.(*ast.Ident); ok && t.Len == nil && elem.Name == "string" {
+			kind = "[]string"
+		}
+	}
+	if !inlinedKinds[kind] {
+		return ""
+	}
+	return kind
+}
+
+// importName returns the name of the imported package in the file, empty if not imported
+func importName(file *ast.File, path string) string {
+	for _, spec := range file.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil || importPath != path {
+			continue
+		}
+		if spec.Name != nil {
+			return spec.Name.Name
+		}
+		return filepath.Base(path)
+	}
+	return ""
+}
+
+func embeddedFieldName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedFieldName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// generateMapper returns the formatted source of the row mapper of the fields
+func generateMapper(packageName string, fields []mapperField, options MapperOptions) ([]byte, error) {
+	imports := map[string]bool{
+		"reflect": true,
+		"github.com/aws/aws-sdk-go-v2/service/athena/types": true,
+		"github.com/kent-id/athenaconv":                     true,
+	}
+	var body strings.Builder
+	for _, field := range fields {
+		if field.name == "" {
+			continue
+		}
+		fmt.Fprintf(&body, "if i := idx.Index(%d); i >= 0 {\n", field.index)
+		writeFieldConversion(&body, field, imports)
+		body.WriteString("}\n")
+	}
+
+	var source bytes.Buffer
+	source.WriteString("// Code generated by athenaconv-gen. DO NOT EDIT.\n\n")
+	source.WriteString("package " + packageName + "\n\n")
+	source.WriteString(importDecl(imports))
+	fmt.Fprintf(&source, `
+func init() {
+	athenaconv.RegisterRowMapper(reflect.TypeOf(%[1]s{}), func(row types.Row, idx athenaconv.ColumnIndex) (interface{}, error) {
+		return %[2]s(row, idx)
+	})
+}
+
+// %[2]s converts the row to %[1]s with the conversions of the athenaconv mappers, without reflection
+func %[2]s(row types.Row, idx athenaconv.ColumnIndex) (*%[1]s, error) {
+	model := &%[1]s{}
+%[3]s	return model, nil
+}
+`, options.StructName, options.FuncName, body.String())
+
+	formatted, err := format.Source(source.Bytes())
+	if err != nil {
+		err := fmt.Errorf("cannot format generated source: %v", err)
+		return nil, err
+	}
+	return formatted, nil
+}
+
+// writeFieldConversion writes the conversion of the value of the column bound to the field, at index i of the row data
+func writeFieldConversion(body *strings.Builder, field mapperField, imports map[string]bool) {
+	target := "model." + field.name
+	pointer := strings.HasPrefix(field.kind, "*")
+	kind := strings.TrimPrefix(field.kind, "*")
+
+	// text is the column value, NULL is converted to empty string unless the field is a pointer
+	text := "util.SafeString(row.Data[i].VarCharValue)"
+	if !pointer && field.kind != "" {
+		imports["github.com/kent-id/athenaconv/util"] = true
+	}
+	if pointer {
+		text = "*value"
+		body.WriteString("if value := row.Data[i].VarCharValue; value != nil {\n")
+	}
+	assign := func(value string) {
+		if pointer {
+			fmt.Fprintf(body, "converted := %s\n%s = &converted\n", value, target)
+			return
+		}
+		fmt.Fprintf(body, "%s = %s\n", target, value)
+	}
+	parse := func(call string) {
+		fmt.Fprintf(body, "converted, err := %s\nif err != nil {\nreturn nil, err\n}\n", call)
+		if pointer {
+			fmt.Fprintf(body, "%s = &converted\n", target)
+			return
+		}
+		fmt.Fprintf(body, "%s = converted\n", target)
+	}
+
+	switch kind {
+	case "string":
+		assign(text)
+	case "bool":
+		imports["strings"] = true
+		assign("strings.ToLower(" + text + ") == \"true\"")
+	case "int":
+		imports["strconv"] = true
+		parse("strconv.Atoi(" + text + ")")
+	case "int64":
+		imports["strconv"] = true
+		parse("strconv.ParseInt(" + text + ", 10, 64)")
+	case "float64":
+		imports["strconv"] = true
+		parse("strconv.ParseFloat(" + text + ", 64)")
+	case "time.Time":
+		imports["time"] = true
+		fmt.Fprintf(body, "layout := \"2006-01-02 15:04:05\"\nif idx.Type(%d) == \"date\" {\nlayout = \"2006-01-02\"\n}\n", field.index)
+		parse("time.Parse(layout, " + text + ")")
+	case "[]string":
+		// arrays are converted from text, complex columns selected as JSON are decoded by athenaconv
+		imports["strings"] = true
+		fmt.Fprintf(body, "if idx.Type(%d) == \"array\" {\n", field.index)
+		fmt.Fprintf(body, "values := strings.Trim(%s, \"[]\")\n%s = make([]string, 0)\nif len(values) > 0 {\n%s = strings.Split(values, \", \")\n}\n", text, target, target)
+		body.WriteString("} else ")
+		writeSetColumnValue(body, field)
+	default:
+		writeSetColumnValue(body, field)
+	}
+
+	if pointer {
+		body.WriteString("}\n")
+	}
+}
+
+func writeSetColumnValue(body *strings.Builder, field mapperField) {
+	fmt.Fprintf(body, "if err := athenaconv.SetColumnValue(&model.%s, row.Data[i], idx.Type(%d)); err != nil {\nreturn nil, err\n}\n", field.name, field.index)
+}
+
+// importDecl returns the import declaration of the paths, standard library packages first
+func importDecl(imports map[string]bool) string {
+	standard := make([]string, 0)
+	thirdParty := make([]string, 0)
+	for path := range imports {
+		if strings.Contains(path, ".") {
+			thirdParty = append(thirdParty, path)
+		} else {
+			standard = append(standard, path)
+		}
+	}
+	sort.Strings(standard)
+	sort.Strings(thirdParty)
+
+	var decl strings.Builder
+	decl.WriteString("import (\n")
+	for _, path := range standard {
+		decl.WriteString(strconv.Quote(path) + "\n")
+	}
+	decl.WriteString("\n")
+	for _, path := range thirdParty {
+		decl.WriteString(strconv.Quote(path) + "\n")
+	}
+	decl.WriteString(")\n")
+	return decl.String()
+}
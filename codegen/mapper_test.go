@@ -0,0 +1,154 @@
+package codegen
+
+import (
+	"context"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv"
+	"github.com/kent-id/athenaconv/codegen/internal/testmodel"
+	"github.com/kent-id/athenaconv/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GenerateMapper", func() {
+	const testModelDir = "internal/testmodel"
+
+	It("should generate the committed row mapper of the test model", func() {
+		expected, err := ioutil.ReadFile(filepath.Join(testModelDir, "event_mapper_gen.go"))
+		Expect(err).ToNot(HaveOccurred())
+
+		source, err := GenerateMapper(testModelDir, MapperOptions{StructName: "Event"})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(source)).To(Equal(string(expected)), "run go generate ./codegen/...")
+	})
+
+	It("should return error if struct is not found", func() {
+		_, err := GenerateMapper(testModelDir, MapperOptions{StructName: "Missing"})
+		Expect(err).To(MatchError("struct Missing not found in internal/testmodel"))
+
+		_, err = GenerateMapper(testModelDir, MapperOptions{StructName: "Event", FuncName: "map-event"})
+		Expect(err).To(MatchError("invalid func name: 'map-event'"))
+	})
+
+	Context("mapperFields", func() {
+		It("should index every field of the struct and inline the supported types", func() {
+			dir, err := ioutil.TempDir("", "codegen")
+			Expect(err).ToNot(HaveOccurred())
+			source := "package models\n\nimport t \"time\"\n\ntype Embedded struct{}\n\ntype Model struct {\n" +
+				"\tEmbedded\n\tA, _ int\n\tB t.Time\n\tC []int\n\tD *[]string\n\tE *bool\n}\n"
+			Expect(ioutil.WriteFile(filepath.Join(dir, "model.go"), []byte(source), 0644)).To(Succeed())
+
+			file, structType, err := findStruct(dir, "Model")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mapperFields(file, structType)).To(Equal([]mapperField{
+				{index: 0, name: "Embedded"},
+				{index: 1, name: "A", kind: "int"},
+				{index: 3, name: "B", kind: "time.Time"},
+				{index: 4, name: "C"},
+				{index: 5, name: "D"},
+				{index: 6, name: "E", kind: "*bool"},
+			}))
+
+			_, _, err = findStruct(dir, "Embedded")
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	Context("generated row mapper", func() {
+		var resultSet *types.ResultSet
+
+		column := func(name string, athenaType string) types.ColumnInfo {
+			return types.ColumnInfo{Name: util.RefString(name), Type: util.RefString(athenaType)}
+		}
+		row := func(values ...*string) types.Row {
+			data := make([]types.Datum, len(values))
+			for i, value := range values {
+				data[i] = types.Datum{VarCharValue: value}
+			}
+			return types.Row{Data: data}
+		}
+		mapBoth := func(opts ...athenaconv.MapperOption) ([]interface{}, []interface{}, error, error) {
+			generatedMapper, err := athenaconv.NewMapperFor(reflect.TypeOf(testmodel.Event{}), opts...)
+			Expect(err).ToNot(HaveOccurred())
+			reflectiveMapper, err := athenaconv.NewMapperFor(reflect.TypeOf(testmodel.Event{}), append(opts, athenaconv.WithReflection())...)
+			Expect(err).ToNot(HaveOccurred())
+
+			generated, generatedErr := generatedMapper.FromAthenaResultSetV2(context.Background(), resultSet)
+			reflective, reflectiveErr := reflectiveMapper.FromAthenaResultSetV2(context.Background(), resultSet)
+			return generated, reflective, generatedErr, reflectiveErr
+		}
+
+		BeforeEach(func() {
+			// columns in a different order than the fields, with labels selected as JSON
+			resultSet = &types.ResultSet{
+				ResultSetMetadata: &types.ResultSetMetadata{ColumnInfo: []types.ColumnInfo{
+					column("name", "varchar"), column("id", "integer"), column("total", "bigint"), column("score", "double"),
+					column("active", "boolean"), column("created_at", "timestamp"), column("day", "date"), column("tags", "array"),
+					column("labels", "varchar"), column("parent_id", "bigint"), column("comment", "varchar"), column("rate", "real"),
+					column("enabled", "boolean"), column("count", "integer"), column("deleted_at", "timestamp"),
+					column("location", "json"), column("counts", "varchar"),
+				}},
+				Rows: []types.Row{
+					row(util.RefString("first"), util.RefString("1"), util.RefString("10000000000"), util.RefString("1.5"),
+						util.RefString("TRUE"), util.RefString("2021-12-31 08:11:22.123"), util.RefString("2021-12-31"), util.RefString("[a, b]"),
+						util.RefString(`["x","y"]`), util.RefString("7"), util.RefString("hello"), util.RefString("0.25"),
+						util.RefString("false"), util.RefString("3"), util.RefString("2022-01-01 00:00:00"),
+						util.RefString(`{"city":"Paris","zip":75001}`), util.RefString(`{"a":1}`)),
+					row(util.RefString(""), util.RefString("2"), util.RefString("0"), util.RefString("-1"),
+						nil, util.RefString("2021-01-01 00:00:00"), util.RefString("2021-01-01"), util.RefString("[]"),
+						util.RefString("[]"), nil, nil, nil, nil, nil, nil, nil, nil),
+				},
+			}
+		})
+
+		It("should register the generated row mapper", func() {
+			mapped, err := testmodel.MapEventAthenaRow(resultSet.Rows[0], athenaconv.ColumnIndex{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mapped).To(Equal(&testmodel.Event{}))
+
+			generated, reflective, generatedErr, reflectiveErr := mapBoth()
+			Expect(generatedErr).ToNot(HaveOccurred())
+			Expect(reflectiveErr).ToNot(HaveOccurred())
+			Expect(generated).To(HaveLen(2))
+			Expect(generated[0].(*testmodel.Event).Location).To(Equal(&testmodel.Location{City: "Paris", Zip: 75001}))
+			Expect(generated).To(Equal(reflective))
+		})
+
+		It("should produce the same output as the reflective mapper", func() {
+			generated, reflective, generatedErr, reflectiveErr := mapBoth(athenaconv.WithHeaderRow(athenaconv.HeaderRowPresent))
+			Expect(generatedErr).ToNot(HaveOccurred())
+			Expect(reflectiveErr).ToNot(HaveOccurred())
+			Expect(generated).To(HaveLen(1))
+			Expect(generated).To(Equal(reflective))
+		})
+
+		It("should produce the same output with name matcher", func() {
+			for i, columnInfo := range resultSet.ResultSetMetadata.ColumnInfo {
+				resultSet.ResultSetMetadata.ColumnInfo[i].Name = util.RefString(strings.ToUpper(*columnInfo.Name))
+			}
+			generated, reflective, generatedErr, reflectiveErr := mapBoth(athenaconv.WithNameMatcher(athenaconv.CaseInsensitiveNameMatcher))
+			Expect(generatedErr).ToNot(HaveOccurred())
+			Expect(reflectiveErr).ToNot(HaveOccurred())
+			Expect(generated).To(HaveLen(2))
+			Expect(generated).To(Equal(reflective))
+		})
+
+		It("should return the same errors as the reflective mapper", func() {
+			resultSet.Rows[1].Data[1].VarCharValue = nil
+			_, _, generatedErr, reflectiveErr := mapBoth()
+			Expect(generatedErr).To(MatchError(`strconv.Atoi: parsing "": invalid syntax`))
+			Expect(generatedErr).To(Equal(reflectiveErr))
+
+			resultSet.Rows[1].Data[1].VarCharValue = util.RefString("2")
+			resultSet.Rows[1].Data[15].VarCharValue = util.RefString("{")
+			_, _, generatedErr, reflectiveErr = mapBoth()
+			Expect(generatedErr).To(HaveOccurred())
+			Expect(generatedErr).To(Equal(reflectiveErr))
+		})
+	})
+})
@@ -0,0 +1,98 @@
+package codegen
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// NamingStyle decides how the Go names of the generated fields and types are derived from the column names
+type NamingStyle string
+
+const (
+	// NamingGo capitalizes every word of the column name and upper cases common initialisms as golint, e.g. source_id as SourceID.
+	// This is the default.
+	NamingGo NamingStyle = "go"
+	// NamingPascal capitalizes every word of the column name, e.g. source_id as SourceId
+	NamingPascal NamingStyle = "pascal"
+)
+
+// commonInitialisms are upper cased by NamingGo, see https://github.com/golang/lint/blob/master/lint.go
+var commonInitialisms = map[string]bool{
+	"acl": true, "api": true, "ascii": true, "cpu": true, "css": true, "dns": true, "eof": true, "guid": true,
+	"html": true, "http": true, "https": true, "id": true, "ip": true, "json": true, "lhs": true, "qps": true,
+	"ram": true, "rhs": true, "rpc": true, "sla": true, "smtp": true, "sql": true, "ssh": true, "tcp": true,
+	"tls": true, "ttl": true, "udp": true, "ui": true, "uid": true, "uuid": true, "uri": true, "url": true,
+	"utf8": true, "vm": true, "xml": true, "xmpp": true, "xsrf": true, "xss": true,
+}
+
+// goName returns the exported Go name of the column name, e.g. source_computer_ids as SourceComputerIDs with NamingGo
+func goName(columnName string, style NamingStyle) string {
+	var builder strings.Builder
+	for _, word := range splitWords(columnName) {
+		lower := strings.ToLower(word)
+		switch {
+		case style != NamingPascal && commonInitialisms[lower]:
+			builder.WriteString(strings.ToUpper(lower))
+		case style != NamingPascal && strings.HasSuffix(lower, "s") && commonInitialisms[strings.TrimSuffix(lower, "s")]:
+			// plural initialisms, e.g. ids as IDs
+			builder.WriteString(strings.ToUpper(strings.TrimSuffix(lower, "s")) + "s")
+		default:
+			runes := []rune(word)
+			builder.WriteString(strings.ToUpper(string(runes[0])) + string(runes[1:]))
+		}
+	}
+
+	name := builder.String()
+	if name == "" {
+		return "Column"
+	}
+	if unicode.IsDigit([]rune(name)[0]) {
+		// e.g. _col0 of unnamed columns
+		return "Col" + name
+	}
+	return name
+}
+
+// splitWords splits the column name into words on non alphanumeric characters and camelCase boundaries
+func splitWords(columnName string) []string {
+	words := make([]string, 0)
+	var word []rune
+	runes := []rune(columnName)
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if len(word) > 0 {
+				words = append(words, string(word))
+				word = nil
+			}
+			continue
+		}
+		if unicode.IsUpper(r) && len(word) > 0 && unicode.IsLower(runes[i-1]) {
+			words = append(words, string(word))
+			word = nil
+		}
+		word = append(word, r)
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+	return words
+}
+
+// uniqueNames counts the names in use, so that duplicate names are suffixed with their occurrence, e.g. ID and ID2
+type uniqueNames map[string]int
+
+// add returns the name, suffixed if it is already in use
+func (names uniqueNames) add(name string) string {
+	names[name]++
+	if names[name] == 1 {
+		return name
+	}
+	unique := name + strconv.Itoa(names[name])
+	for names[unique] > 0 {
+		names[name]++
+		unique = name + strconv.Itoa(names[name])
+	}
+	names[unique]++
+	return unique
+}
@@ -0,0 +1,38 @@
+package codegen
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Naming", func() {
+	Context("goName", func() {
+		It("should upper case initialisms with go naming", func() {
+			Expect(goName("source_computer_ids", NamingGo)).To(Equal("SourceComputerIDs"))
+			Expect(goName("user_id", NamingGo)).To(Equal("UserID"))
+			Expect(goName("api-url", NamingGo)).To(Equal("APIURL"))
+			Expect(goName("createdAt", NamingGo)).To(Equal("CreatedAt"))
+		})
+
+		It("should only capitalize words with pascal naming", func() {
+			Expect(goName("source_computer_ids", NamingPascal)).To(Equal("SourceComputerIds"))
+			Expect(goName("user_id", NamingPascal)).To(Equal("UserId"))
+		})
+
+		It("should return valid identifiers for unnamed columns", func() {
+			Expect(goName("_col0", NamingGo)).To(Equal("Col0"))
+			Expect(goName("2nd", NamingGo)).To(Equal("Col2nd"))
+			Expect(goName("#", NamingGo)).To(Equal("Column"))
+		})
+	})
+
+	Context("uniqueNames", func() {
+		It("should suffix duplicates with their occurrence", func() {
+			names := make(uniqueNames)
+			Expect(names.add("ID")).To(Equal("ID"))
+			Expect(names.add("ID2")).To(Equal("ID2"))
+			Expect(names.add("ID")).To(Equal("ID3"))
+			Expect(names.add("ID")).To(Equal("ID4"))
+		})
+	})
+})
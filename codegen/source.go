@@ -0,0 +1,261 @@
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv"
+	"github.com/kent-id/athenaconv/util"
+)
+
+// Source is the kind of input the columns are read from
+type Source string
+
+const (
+	// SourceAuto detects the source: DDL if the input is not JSON, otherwise result set or table metadata depending on its keys
+	SourceAuto Source = "auto"
+	// SourceResultSet is the JSON output of GetQueryResults, e.g. of aws athena get-query-results
+	SourceResultSet Source = "results"
+	// SourceDDL is a CREATE TABLE or CREATE EXTERNAL TABLE statement
+	SourceDDL Source = "ddl"
+	// SourceTableMetadata is the JSON output of GetTableMetadata, e.g. of aws athena get-table-metadata, or of aws glue get-table
+	SourceTableMetadata Source = "table"
+)
+
+// resultSetJSON holds the column info of the JSON output of GetQueryResults, or of its ResultSet or ResultSetMetadata object
+type resultSetJSON struct {
+	ResultSet         *resultSetJSON `json:"ResultSet"`
+	ResultSetMetadata *struct {
+		ColumnInfo []struct {
+			Name     string `json:"Name"`
+			Type     string `json:"Type"`
+			Nullable string `json:"Nullable"`
+		} `json:"ColumnInfo"`
+	} `json:"ResultSetMetadata"`
+}
+
+// ReadColumns reads the columns of the input of the given source
+func ReadColumns(reader io.Reader, source Source) ([]Column, error) {
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	if source == "" || source == SourceAuto {
+		source = detectSource(data)
+	}
+
+	switch source {
+	case SourceResultSet:
+		return ColumnsFromResultSet(bytes.NewReader(data))
+	case SourceDDL:
+		return ColumnsFromDDL(bytes.NewReader(data))
+	case SourceTableMetadata:
+		return ColumnsFromTableMetadata(bytes.NewReader(data))
+	}
+	err = fmt.Errorf("unknown source: %s, expecting %s, %s, %s or %s", source, SourceAuto, SourceResultSet, SourceDDL, SourceTableMetadata)
+	return nil, err
+}
+
+// detectSource returns the source of the input: DDL if not JSON, result set if it has ResultSet or ResultSetMetadata, otherwise table metadata
+func detectSource(data []byte) Source {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(data, &object); err != nil {
+		if strings.HasPrefix(strings.TrimSpace(string(data)), "[") {
+			return SourceTableMetadata
+		}
+		return SourceDDL
+	}
+	if _, ok := object["ResultSet"]; ok {
+		return SourceResultSet
+	}
+	if _, ok := object["ResultSetMetadata"]; ok {
+		return SourceResultSet
+	}
+	return SourceTableMetadata
+}
+
+// ColumnsFromResultSet reads the columns of the JSON output of GetQueryResults, e.g. saved with aws athena get-query-results.
+// Columns are nullable unless their Nullable is NOT_NULL.
+func ColumnsFromResultSet(reader io.Reader) ([]Column, error) {
+	var output resultSetJSON
+	if err := json.NewDecoder(reader).Decode(&output); err != nil {
+		err := fmt.Errorf("invalid GetQueryResults JSON: %v", err)
+		return nil, err
+	}
+	resultSet := &output
+	if output.ResultSet != nil {
+		resultSet = output.ResultSet
+	}
+	if resultSet.ResultSetMetadata == nil || len(resultSet.ResultSetMetadata.ColumnInfo) == 0 {
+		err := fmt.Errorf("invalid GetQueryResults JSON: no column info found")
+		return nil, err
+	}
+
+	columns := make([]Column, 0, len(resultSet.ResultSetMetadata.ColumnInfo))
+	for _, columnInfo := range resultSet.ResultSetMetadata.ColumnInfo {
+		columns = append(columns, Column{
+			Name:     columnInfo.Name,
+			Type:     columnInfo.Type,
+			Nullable: columnInfo.Nullable != string(types.ColumnNullableNotNull),
+		})
+	}
+	return columns, nil
+}
+
+// ColumnsFromTableMetadata reads the columns followed by the partition keys of the JSON output of GetTableMetadata,
+// or of aws glue get-table, see athenaconv.ReadTableMetadata. Table columns are always nullable.
+func ColumnsFromTableMetadata(reader io.Reader) ([]Column, error) {
+	metadata, err := athenaconv.ReadTableMetadata(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]Column, 0, len(metadata.Columns)+len(metadata.PartitionKeys))
+	for _, column := range append(metadata.Columns, metadata.PartitionKeys...) {
+		columns = append(columns, Column{
+			Name:     util.SafeString(column.Name),
+			Type:     util.SafeString(column.Type),
+			Nullable: true,
+			Comment:  util.SafeString(column.Comment),
+		})
+	}
+	return columns, nil
+}
+
+// ColumnsFromDDL reads the columns followed by the partition columns of a CREATE TABLE or CREATE EXTERNAL TABLE statement.
+// Columns are nullable unless defined NOT NULL.
+func ColumnsFromDDL(reader io.Reader) ([]Column, error) {
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	ddl := string(data)
+
+	start := strings.Index(ddl, "(")
+	if start < 0 || !strings.Contains(strings.ToUpper(ddl[:start]), "TABLE") {
+		err := fmt.Errorf("invalid DDL: expecting CREATE TABLE statement with column definitions")
+		return nil, err
+	}
+	definitions, end, err := splitDefinitions(ddl, start)
+	if err != nil {
+		return nil, err
+	}
+
+	// partition columns of hive tables are defined in PARTITIONED BY, iceberg tables list column names or transforms only
+	rest := ddl[end:]
+	if index := strings.Index(strings.ToUpper(rest), "PARTITIONED BY"); index >= 0 {
+		if open := strings.Index(rest[index:], "("); open >= 0 {
+			partitions, _, err := splitDefinitions(rest, index+open)
+			if err != nil {
+				return nil, err
+			}
+			definitions = append(definitions, partitions...)
+		}
+	}
+
+	columns := make([]Column, 0, len(definitions))
+	for _, definition := range definitions {
+		column, ok, err := parseColumnDefinition(definition)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			columns = append(columns, column)
+		}
+	}
+	if len(columns) == 0 {
+		err := fmt.Errorf("invalid DDL: no column definitions found")
+		return nil, err
+	}
+	return columns, nil
+}
+
+// splitDefinitions splits the comma separated definitions in the parentheses opened at start, returns the index after the closing parenthesis
+func splitDefinitions(ddl string, start int) ([]string, int, error) {
+	definitions := make([]string, 0)
+	depth := 0
+	definitionStart := start + 1
+	for i := start; i < len(ddl); i++ {
+		switch c := ddl[i]; c {
+		case '\'', '"', '`':
+			end := strings.IndexByte(ddl[i+1:], c)
+			if end < 0 {
+				err := fmt.Errorf("invalid DDL: unterminated quote at %d", i)
+				return nil, 0, err
+			}
+			i += end + 1
+		case '(', '<':
+			depth++
+		case ')', '>':
+			depth--
+			if depth == 0 {
+				definitions = append(definitions, strings.TrimSpace(ddl[definitionStart:i]))
+				return definitions, i + 1, nil
+			}
+		case ',':
+			if depth == 1 {
+				definitions = append(definitions, strings.TrimSpace(ddl[definitionStart:i]))
+				definitionStart = i + 1
+			}
+		}
+	}
+	err := fmt.Errorf("invalid DDL: missing closing parenthesis of definitions at %d", start)
+	return nil, 0, err
+}
+
+// parseColumnDefinition parses "name type [NOT NULL] [COMMENT 'comment']", returns false for definitions without type,
+// e.g. partition columns of iceberg tables
+func parseColumnDefinition(definition string) (Column, bool, error) {
+	name, rest := definition, ""
+	if strings.HasPrefix(definition, "`") || strings.HasPrefix(definition, `"`) {
+		end := strings.IndexByte(definition[1:], definition[0])
+		name, rest = definition[1:end+1], definition[end+2:]
+	} else if index := strings.IndexAny(definition, " \t\r\n"); index >= 0 {
+		name, rest = definition[:index], definition[index+1:]
+	}
+	rest = strings.TrimSpace(rest)
+	if name == "" || rest == "" || strings.Contains(name, "(") {
+		return Column{}, false, nil
+	}
+
+	column := Column{Name: name, Nullable: true}
+	if index := indexKeyword(rest, "COMMENT"); index >= 0 {
+		comment := strings.TrimSpace(rest[index+len("COMMENT"):])
+		column.Comment = strings.Trim(comment, `'"`)
+		rest = rest[:index]
+	}
+	if index := indexKeyword(rest, "NOT NULL"); index >= 0 {
+		column.Nullable = false
+		rest = rest[:index]
+	}
+	column.Type = strings.TrimSpace(rest)
+	if _, err := parseAthenaType(column.Type); err != nil {
+		err := fmt.Errorf("invalid DDL type of column %s: %v", name, err)
+		return Column{}, false, err
+	}
+	return column, true, nil
+}
+
+// indexKeyword returns the index of the keyword ignoring case outside of type brackets, -1 if not found
+func indexKeyword(definition string, keyword string) int {
+	upper := strings.ToUpper(definition)
+	depth := 0
+	for i := 0; i < len(upper); i++ {
+		switch upper[i] {
+		case '(', '<':
+			depth++
+		case ')', '>':
+			depth--
+		default:
+			if depth == 0 && strings.HasPrefix(upper[i:], keyword) && (i == 0 || upper[i-1] == ' ') {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,114 @@
+package codegen
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Source", func() {
+	const resultSetJSON = `{
+		"UpdateCount": 0,
+		"ResultSet": {
+			"Rows": [{"Data": [{"VarCharValue": "id"}, {"VarCharValue": "name"}]}],
+			"ResultSetMetadata": {"ColumnInfo": [
+				{"CatalogName": "hive", "Name": "id", "Type": "integer", "Nullable": "NOT_NULL"},
+				{"CatalogName": "hive", "Name": "name", "Type": "varchar", "Nullable": "UNKNOWN"}
+			]}
+		}
+	}`
+	const tableMetadataJSON = `{"TableMetadata": {
+		"Name": "events",
+		"Columns": [{"Name": "id", "Type": "bigint", "Comment": "event id"}],
+		"PartitionKeys": [{"Name": "day", "Type": "string"}]
+	}}`
+	const ddl = "CREATE EXTERNAL TABLE IF NOT EXISTS `my_db`.`events` (\n" +
+		"  `id` bigint NOT NULL COMMENT 'event, id',\n" +
+		"  `location` struct<city:string, zip:int>,\n" +
+		"  amount decimal(10,2)\n" +
+		")\n" +
+		"PARTITIONED BY (`day` string)\n" +
+		"STORED AS PARQUET\n" +
+		"LOCATION 's3://bucket/events/'"
+
+	Context("ColumnsFromResultSet", func() {
+		It("should read the column info", func() {
+			columns, err := ColumnsFromResultSet(strings.NewReader(resultSetJSON))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(columns).To(Equal([]Column{
+				{Name: "id", Type: "integer"},
+				{Name: "name", Type: "varchar", Nullable: true},
+			}))
+		})
+
+		It("should return error without column info", func() {
+			_, err := ColumnsFromResultSet(strings.NewReader(`{"ResultSet": {}}`))
+			Expect(err).To(MatchError("invalid GetQueryResults JSON: no column info found"))
+		})
+	})
+
+	Context("ColumnsFromTableMetadata", func() {
+		It("should read the columns and partition keys", func() {
+			columns, err := ColumnsFromTableMetadata(strings.NewReader(tableMetadataJSON))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(columns).To(Equal([]Column{
+				{Name: "id", Type: "bigint", Nullable: true, Comment: "event id"},
+				{Name: "day", Type: "string", Nullable: true},
+			}))
+		})
+	})
+
+	Context("ColumnsFromDDL", func() {
+		It("should read the columns and partition columns", func() {
+			columns, err := ColumnsFromDDL(strings.NewReader(ddl))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(columns).To(Equal([]Column{
+				{Name: "id", Type: "bigint", Comment: "event, id"},
+				{Name: "location", Type: "struct<city:string, zip:int>", Nullable: true},
+				{Name: "amount", Type: "decimal(10,2)", Nullable: true},
+				{Name: "day", Type: "string", Nullable: true},
+			}))
+		})
+
+		It("should skip partition transforms of iceberg tables", func() {
+			columns, err := ColumnsFromDDL(strings.NewReader("CREATE TABLE t (id bigint, ts timestamp) PARTITIONED BY (day(ts), id) TBLPROPERTIES ('table_type'='ICEBERG')"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(columns).To(Equal([]Column{
+				{Name: "id", Type: "bigint", Nullable: true},
+				{Name: "ts", Type: "timestamp", Nullable: true},
+			}))
+		})
+
+		It("should return error for invalid DDL", func() {
+			_, err := ColumnsFromDDL(strings.NewReader("SELECT 1"))
+			Expect(err).To(MatchError("invalid DDL: expecting CREATE TABLE statement with column definitions"))
+			_, err = ColumnsFromDDL(strings.NewReader("CREATE TABLE t (id bigint"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("ReadColumns", func() {
+		It("should detect the source", func() {
+			Expect(detectSource([]byte(resultSetJSON))).To(Equal(SourceResultSet))
+			Expect(detectSource([]byte(`{"ResultSetMetadata": {}}`))).To(Equal(SourceResultSet))
+			Expect(detectSource([]byte(tableMetadataJSON))).To(Equal(SourceTableMetadata))
+			Expect(detectSource([]byte(`[{"Name": "id", "Type": "int"}]`))).To(Equal(SourceTableMetadata))
+			Expect(detectSource([]byte(ddl))).To(Equal(SourceDDL))
+		})
+
+		It("should read the columns of the source", func() {
+			columns, err := ReadColumns(strings.NewReader(ddl), SourceAuto)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(columns).To(HaveLen(4))
+
+			_, err = ReadColumns(strings.NewReader(ddl), SourceResultSet)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should return error for unknown source", func() {
+			_, err := ReadColumns(strings.NewReader(ddl), Source("csv"))
+			Expect(err).To(MatchError("unknown source: csv, expecting auto, results, ddl or table"))
+		})
+	})
+})
@@ -0,0 +1,165 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+)
+
+// athenaType is a parsed athena type, e.g. array<struct<city:string>> or row(city varchar)
+type athenaType struct {
+	// name is the lower case base name of the type, e.g. varchar, array, map, struct
+	name string
+	// params are the element type of arrays, and the key and value types of maps
+	params []*athenaType
+	// fields are the fields of struct and row types
+	fields []athenaField
+}
+
+type athenaField struct {
+	name      string
+	fieldType *athenaType
+}
+
+// isComplex returns true for array, map and struct types with their element or field types,
+// as the result set metadata returned by athena only has the base name, e.g. array
+func (t *athenaType) isComplex() bool {
+	return len(t.params) > 0 || len(t.fields) > 0
+}
+
+// parseAthenaType parses the athena type in DDL syntax, e.g. map<string,array<bigint>>, or in SQL syntax, e.g. map(varchar, array(bigint))
+func parseAthenaType(typeName string) (*athenaType, error) {
+	parser := &typeParser{input: typeName}
+	parsed, err := parser.parseType()
+	if err != nil {
+		return nil, err
+	}
+	parser.skipSpaces()
+	if parser.pos != len(parser.input) {
+		return nil, parser.errorf("unexpected '%s'", parser.input[parser.pos:])
+	}
+	return parsed, nil
+}
+
+type typeParser struct {
+	input string
+	pos   int
+}
+
+func (p *typeParser) parseType() (*athenaType, error) {
+	p.skipSpaces()
+	start := p.pos
+	for p.pos < len(p.input) && !strings.ContainsRune("<>(),:", rune(p.input[p.pos])) {
+		p.pos++
+	}
+	name := strings.ToLower(strings.Join(strings.Fields(p.input[start:p.pos]), " "))
+	if name == "" {
+		return nil, p.errorf("missing type name")
+	}
+	parsed := &athenaType{name: name}
+
+	if p.pos == len(p.input) || (p.input[p.pos] != '<' && p.input[p.pos] != '(') {
+		return parsed, nil
+	}
+	open := p.input[p.pos]
+	close := byte('>')
+	if open == '(' {
+		close = ')'
+	}
+	p.pos++
+
+	switch name {
+	case "array", "map":
+		for {
+			param, err := p.parseType()
+			if err != nil {
+				return nil, err
+			}
+			parsed.params = append(parsed.params, param)
+			if done, err := p.next(close); done || err != nil {
+				return parsed, err
+			}
+		}
+	case "struct", "row":
+		for {
+			field, err := p.parseField(open == '<')
+			if err != nil {
+				return nil, err
+			}
+			parsed.fields = append(parsed.fields, field)
+			if done, err := p.next(close); done || err != nil {
+				return parsed, err
+			}
+		}
+	default:
+		// type parameters of scalar types, e.g. decimal(10,2) or varchar(255)
+		end := strings.IndexByte(p.input[p.pos:], close)
+		if end < 0 {
+			return nil, p.errorf("missing '%c'", close)
+		}
+		p.pos += end + 1
+		return parsed, nil
+	}
+}
+
+// parseField parses struct field name:type in DDL syntax, or row field name type in SQL syntax
+func (p *typeParser) parseField(ddlSyntax bool) (athenaField, error) {
+	p.skipSpaces()
+	start := p.pos
+	if p.pos < len(p.input) && (p.input[p.pos] == '`' || p.input[p.pos] == '"') {
+		quote := p.input[p.pos]
+		end := strings.IndexByte(p.input[p.pos+1:], quote)
+		if end < 0 {
+			return athenaField{}, p.errorf("unterminated quoted field name")
+		}
+		p.pos += end + 2
+	} else {
+		for p.pos < len(p.input) && !strings.ContainsRune(" \t\n:<>(),", rune(p.input[p.pos])) {
+			p.pos++
+		}
+	}
+	name := strings.Trim(p.input[start:p.pos], "`\"")
+	if name == "" {
+		return athenaField{}, p.errorf("missing field name")
+	}
+
+	p.skipSpaces()
+	if ddlSyntax {
+		if p.pos >= len(p.input) || p.input[p.pos] != ':' {
+			return athenaField{}, p.errorf("missing ':' after field name %s", name)
+		}
+		p.pos++
+	}
+	fieldType, err := p.parseType()
+	if err != nil {
+		return athenaField{}, err
+	}
+	return athenaField{name: name, fieldType: fieldType}, nil
+}
+
+// next consumes the separator after a type parameter or field, returns true if it is the closing bracket
+func (p *typeParser) next(close byte) (bool, error) {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return false, p.errorf("missing '%c'", close)
+	}
+	switch p.input[p.pos] {
+	case ',':
+		p.pos++
+		return false, nil
+	case close:
+		p.pos++
+		return true, nil
+	}
+	return false, p.errorf("unexpected '%c'", p.input[p.pos])
+}
+
+func (p *typeParser) skipSpaces() {
+	for p.pos < len(p.input) && strings.ContainsRune(" \t\r\n", rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+func (p *typeParser) errorf(format string, args ...interface{}) error {
+	err := fmt.Errorf("invalid athena type '%s' at %d: %s", p.input, p.pos, fmt.Sprintf(format, args...))
+	return err
+}
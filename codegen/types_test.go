@@ -0,0 +1,41 @@
+package codegen
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("parseAthenaType", func() {
+	It("should parse scalar types with parameters", func() {
+		Expect(parseAthenaType("DECIMAL(10, 2)")).To(Equal(&athenaType{name: "decimal"}))
+		Expect(parseAthenaType(" timestamp  with time zone ")).To(Equal(&athenaType{name: "timestamp with time zone"}))
+	})
+
+	It("should parse complex types in DDL syntax", func() {
+		parsed, err := parseAthenaType("map<string,array<struct<`city`:string, zip:int>>>")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parsed).To(Equal(&athenaType{name: "map", params: []*athenaType{
+			{name: "string"},
+			{name: "array", params: []*athenaType{{name: "struct", fields: []athenaField{
+				{name: "city", fieldType: &athenaType{name: "string"}},
+				{name: "zip", fieldType: &athenaType{name: "int"}},
+			}}}},
+		}}))
+	})
+
+	It("should parse complex types in SQL syntax", func() {
+		parsed, err := parseAthenaType("array(row(city varchar, zip integer))")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parsed).To(Equal(&athenaType{name: "array", params: []*athenaType{{name: "row", fields: []athenaField{
+			{name: "city", fieldType: &athenaType{name: "varchar"}},
+			{name: "zip", fieldType: &athenaType{name: "integer"}},
+		}}}}))
+	})
+
+	It("should return error for invalid types", func() {
+		for _, invalid := range []string{"", "array<string", "struct<city string>", "map<string,int>>", "decimal(10"} {
+			_, err := parseAthenaType(invalid)
+			Expect(err).To(HaveOccurred(), invalid)
+		}
+	})
+})
@@ -0,0 +1,71 @@
+package athenaconv
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// columnOccurrenceSeparator separates the column name from its 1-based occurrence, e.g. id#2 for the second column named id.
+// A column key with no name, e.g. #0, binds the column at the given 0-based position.
+const columnOccurrenceSeparator = "#"
+
+// columnKey is a parsed athenaconv column key
+type columnKey struct {
+	name       string
+	occurrence int
+	position   int
+}
+
+// isPositional returns true if the key binds a column by its position in the result set
+func (k columnKey) isPositional() bool {
+	return k.position >= 0
+}
+
+// parseColumnKey parses athenaconv column key: "name", "name#occurrence" or "#position"
+func parseColumnKey(key string) (columnKey, error) {
+	separatorIndex := strings.LastIndex(key, columnOccurrenceSeparator)
+	if separatorIndex < 0 {
+		return columnKey{name: key, occurrence: 1, position: -1}, nil
+	}
+
+	name := key[:separatorIndex]
+	number, err := strconv.Atoi(key[separatorIndex+len(columnOccurrenceSeparator):])
+	if err != nil {
+		err := fmt.Errorf("invalid column key '%s', expecting 'name', 'name#occurrence' or '#position'", key)
+		return columnKey{}, err
+	}
+
+	if name == "" {
+		if number < 0 {
+			err := fmt.Errorf("invalid column position in key '%s', position should be zero or positive", key)
+			return columnKey{}, err
+		}
+		return columnKey{position: number}, nil
+	}
+
+	if number < 1 {
+		err := fmt.Errorf("invalid column occurrence in key '%s', occurrence should start at 1", key)
+		return columnKey{}, err
+	}
+	return columnKey{name: name, occurrence: number, position: -1}, nil
+}
+
+// occurrenceKey returns the key of the given occurrence of a column name, the first occurrence is keyed by the name itself
+func occurrenceKey(name string, occurrence int) string {
+	if occurrence <= 1 {
+		return name
+	}
+	return name + columnOccurrenceSeparator + strconv.Itoa(occurrence)
+}
+
+// newColumnKeys returns unique keys for the given ordered column names, duplicate names are keyed by their occurrence
+func newColumnKeys(names []string) []string {
+	occurrences := make(map[string]int, len(names))
+	keys := make([]string, len(names))
+	for i, name := range names {
+		occurrences[name]++
+		keys[i] = occurrenceKey(name, occurrences[name])
+	}
+	return keys
+}
@@ -0,0 +1,57 @@
+package athenaconv
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Column binding", func() {
+	Context("parseColumnKey", func() {
+		It("should parse plain column name as first occurrence", func() {
+			key, err := parseColumnKey("my_id_col")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(key).To(Equal(columnKey{name: "my_id_col", occurrence: 1, position: -1}))
+			Expect(key.isPositional()).To(BeFalse())
+		})
+
+		It("should parse column occurrence", func() {
+			key, err := parseColumnKey("id#2")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(key).To(Equal(columnKey{name: "id", occurrence: 2, position: -1}))
+		})
+
+		It("should parse column position", func() {
+			key, err := parseColumnKey("#0")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(key.isPositional()).To(BeTrue())
+			Expect(key.position).To(Equal(0))
+		})
+
+		It("should return error on invalid number", func() {
+			_, err := parseColumnKey("id#abc")
+			Expect(err).To(HaveOccurred())
+			Expect(strings.ToLower(err.Error())).To(ContainSubstring("invalid column key"))
+		})
+
+		It("should return error on zero occurrence", func() {
+			_, err := parseColumnKey("id#0")
+			Expect(err).To(HaveOccurred())
+			Expect(strings.ToLower(err.Error())).To(ContainSubstring("occurrence should start at 1"))
+		})
+
+		It("should return error on negative position", func() {
+			_, err := parseColumnKey("#-1")
+			Expect(err).To(HaveOccurred())
+			Expect(strings.ToLower(err.Error())).To(ContainSubstring("position should be zero or positive"))
+		})
+	})
+
+	Context("newColumnKeys", func() {
+		It("should key duplicate names by occurrence", func() {
+			keys := newColumnKeys([]string{"id", "name", "id", "id"})
+			Expect(keys).To(Equal([]string{"id", "name", "id#2", "id#3"}))
+		})
+	})
+})
@@ -0,0 +1,312 @@
+package athenaconv
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+)
+
+// ColumnKind is the type of the vector holding a column of ColumnarResult
+type ColumnKind int
+
+const (
+	// ColumnKindString is stored as []string, used for varchar and any type without specific conversion
+	ColumnKindString ColumnKind = iota
+	// ColumnKindInt64 is stored as []int64, used for tinyint, smallint, integer and bigint
+	ColumnKindInt64
+	// ColumnKindFloat64 is stored as []float64, used for double, float and real
+	ColumnKindFloat64
+	// ColumnKindBool is stored as []bool, used for boolean
+	ColumnKindBool
+	// ColumnKindTime is stored as []time.Time, used for timestamp and date
+	ColumnKindTime
+	// ColumnKindValue is stored as []interface{}, used for array
+	ColumnKindValue
+)
+
+// columnKindFor returns the vector kind for the given athena type, matching the result of castAthenaRowData
+func columnKindFor(athenaType string) ColumnKind {
+	switch athenaType {
+	case "tinyint", "smallint", "integer", "bigint":
+		return ColumnKindInt64
+	case "double", "float", "real":
+		return ColumnKindFloat64
+	case "boolean":
+		return ColumnKindBool
+	case "timestamp", "date":
+		return ColumnKindTime
+	case "array":
+		return ColumnKindValue
+	default:
+		return ColumnKindString
+	}
+}
+
+// columnVector holds all values of a single column, NULL values are stored as zero value and flagged in nulls bitmap
+type columnVector struct {
+	kind     ColumnKind
+	int64s   []int64
+	float64s []float64
+	strings  []string
+	bools    []bool
+	times    []time.Time
+	values   []interface{}
+	nulls    []uint64
+}
+
+func (v *columnVector) append(value interface{}, row int) {
+	if value == nil {
+		v.setNull(row)
+	}
+
+	switch v.kind {
+	case ColumnKindInt64:
+		var casted int64
+		switch typed := value.(type) {
+		case int:
+			casted = int64(typed)
+		case int64:
+			casted = typed
+		}
+		v.int64s = append(v.int64s, casted)
+	case ColumnKindFloat64:
+		casted, _ := value.(float64)
+		v.float64s = append(v.float64s, casted)
+	case ColumnKindBool:
+		casted, _ := value.(bool)
+		v.bools = append(v.bools, casted)
+	case ColumnKindTime:
+		casted, _ := value.(time.Time)
+		v.times = append(v.times, casted)
+	case ColumnKindValue:
+		v.values = append(v.values, value)
+	default:
+		casted, _ := value.(string)
+		v.strings = append(v.strings, casted)
+	}
+}
+
+func (v *columnVector) setNull(row int) {
+	word := row / 64
+	for len(v.nulls) <= word {
+		v.nulls = append(v.nulls, 0)
+	}
+	v.nulls[word] |= 1 << uint(row%64)
+}
+
+func (v *columnVector) isNull(row int) bool {
+	word := row / 64
+	if word >= len(v.nulls) {
+		return false
+	}
+	return v.nulls[word]&(1<<uint(row%64)) != 0
+}
+
+// ColumnarResult holds athena results as one typed vector per column, e.g. for aggregation-heavy code.
+// Values are converted using the same conversions as the struct mapper.
+type ColumnarResult struct {
+	Columns  []ColumnMetadata
+	options  mapperOptions
+	vectors  []*columnVector
+	rowCount int
+}
+
+// NewColumnarResult creates new ColumnarResult from zero or more ResultSet pages of the same query.
+// The header row, i.e. first row of your athena ResultSet in page 1, is detected and skipped, see WithHeaderRow.
+//
+// Example:
+//
+// columnar, err := athenaconv.NewColumnarResult(ctx, []*types.ResultSet{queryResultOutput.ResultSet})
+// columnar, err := athenaconv.NewColumnarResult(ctx, pages, athenaconv.WithHeaderRow(athenaconv.HeaderRowAbsent))
+// totals, err := columnar.Float64s("total")
+func NewColumnarResult(ctx context.Context, pages []*types.ResultSet, opts ...MapperOption) (*ColumnarResult, error) {
+	result := &ColumnarResult{options: newMapperOptions(opts)}
+	for _, page := range pages {
+		if err := result.Append(ctx, page); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// Append converts the rows of the next ResultSet page and appends them to the column vectors.
+// Returns error if the page metadata does not match the columns of previously appended pages.
+func (c *ColumnarResult) Append(ctx context.Context, resultSet *types.ResultSet) error {
+	columns, err := ResultSetColumns(ctx, resultSet.ResultSetMetadata)
+	if err != nil {
+		return err
+	}
+
+	if c.vectors == nil {
+		c.initColumns(columns)
+	} else if err := c.validateColumns(columns); err != nil {
+		return err
+	}
+
+	// convert the whole page first so that a conversion error does not leave partially appended vectors
+	rows := skipHeaderRow(resultSet.Rows, resultSet.ResultSetMetadata, c.options.headerRow)
+	converted := make([][]interface{}, 0, len(rows))
+	for rowIndex, row := range rows {
+		if len(row.Data) != len(c.Columns) {
+			err := fmt.Errorf("mismatched row data and result set columns count, row: %d, rowDataLength: %d, columnsLength: %d", rowIndex, len(row.Data), len(c.Columns))
+			return err
+		}
+
+		values := make([]interface{}, len(c.Columns))
+		for i, column := range c.Columns {
+			if row.Data[i].VarCharValue == nil {
+				continue
+			}
+			values[i], err = castAthenaRowData(ctx, row.Data[i], column.Type)
+			if err != nil {
+				return err
+			}
+		}
+		converted = append(converted, values)
+	}
+
+	for _, values := range converted {
+		for i, value := range values {
+			c.vectors[i].append(value, c.rowCount)
+		}
+		c.rowCount++
+	}
+	return nil
+}
+
+func (c *ColumnarResult) initColumns(columns []ColumnMetadata) {
+	c.Columns = columns
+	c.vectors = make([]*columnVector, len(columns))
+	for i, column := range columns {
+		c.vectors[i] = &columnVector{kind: columnKindFor(column.Type)}
+	}
+}
+
+func (c *ColumnarResult) validateColumns(columns []ColumnMetadata) error {
+	if len(columns) != len(c.Columns) {
+		err := fmt.Errorf("mismatched result set columns count across pages, expected: %d, actual: %d", len(c.Columns), len(columns))
+		return err
+	}
+	for i, column := range columns {
+		if column.Name != c.Columns[i].Name || column.Type != c.Columns[i].Type {
+			err := fmt.Errorf("mismatched result set column across pages, index: %d, expected: %s %s, actual: %s %s", i, c.Columns[i].Name, c.Columns[i].Type, column.Name, column.Type)
+			return err
+		}
+	}
+	return nil
+}
+
+// Len returns the number of rows appended so far
+func (c *ColumnarResult) Len() int {
+	return c.rowCount
+}
+
+// Kind returns the vector kind of the given column
+func (c *ColumnarResult) Kind(columnName string) (ColumnKind, error) {
+	vector, err := c.vector(columnName)
+	if err != nil {
+		return 0, err
+	}
+	return vector.kind, nil
+}
+
+// IsNull returns true if the value of the given column in the given row is NULL
+func (c *ColumnarResult) IsNull(columnName string, row int) (bool, error) {
+	vector, err := c.vector(columnName)
+	if err != nil {
+		return false, err
+	}
+	if row < 0 || row >= c.rowCount {
+		err := fmt.Errorf("row %d out of range, rows count: %d", row, c.rowCount)
+		return false, err
+	}
+	return vector.isNull(row), nil
+}
+
+// NullBitmap returns the NULL bitmap of the given column, bit (row % 64) of word (row / 64) is set for NULL values
+func (c *ColumnarResult) NullBitmap(columnName string) ([]uint64, error) {
+	vector, err := c.vector(columnName)
+	if err != nil {
+		return nil, err
+	}
+	bitmap := make([]uint64, (c.rowCount+63)/64)
+	copy(bitmap, vector.nulls)
+	return bitmap, nil
+}
+
+// Int64s returns the values of an integer or bigint column
+func (c *ColumnarResult) Int64s(columnName string) ([]int64, error) {
+	vector, err := c.vectorOfKind(columnName, ColumnKindInt64)
+	if err != nil {
+		return nil, err
+	}
+	return vector.int64s, nil
+}
+
+// Float64s returns the values of a double, float or real column
+func (c *ColumnarResult) Float64s(columnName string) ([]float64, error) {
+	vector, err := c.vectorOfKind(columnName, ColumnKindFloat64)
+	if err != nil {
+		return nil, err
+	}
+	return vector.float64s, nil
+}
+
+// Strings returns the values of a varchar column, or of any column type without specific conversion
+func (c *ColumnarResult) Strings(columnName string) ([]string, error) {
+	vector, err := c.vectorOfKind(columnName, ColumnKindString)
+	if err != nil {
+		return nil, err
+	}
+	return vector.strings, nil
+}
+
+// Bools returns the values of a boolean column
+func (c *ColumnarResult) Bools(columnName string) ([]bool, error) {
+	vector, err := c.vectorOfKind(columnName, ColumnKindBool)
+	if err != nil {
+		return nil, err
+	}
+	return vector.bools, nil
+}
+
+// Times returns the values of a timestamp or date column
+func (c *ColumnarResult) Times(columnName string) ([]time.Time, error) {
+	vector, err := c.vectorOfKind(columnName, ColumnKindTime)
+	if err != nil {
+		return nil, err
+	}
+	return vector.times, nil
+}
+
+// Values returns the values of an array column
+func (c *ColumnarResult) Values(columnName string) ([]interface{}, error) {
+	vector, err := c.vectorOfKind(columnName, ColumnKindValue)
+	if err != nil {
+		return nil, err
+	}
+	return vector.values, nil
+}
+
+func (c *ColumnarResult) vector(columnName string) (*columnVector, error) {
+	index, ok := LookupColumn(c.Columns, columnName)
+	if !ok {
+		err := fmt.Errorf("column '%s' not found in columnar result", columnName)
+		return nil, err
+	}
+	return c.vectors[index], nil
+}
+
+func (c *ColumnarResult) vectorOfKind(columnName string, kind ColumnKind) (*columnVector, error) {
+	vector, err := c.vector(columnName)
+	if err != nil {
+		return nil, err
+	}
+	if vector.kind != kind {
+		err := fmt.Errorf("column '%s' is not stored as the requested vector type", columnName)
+		return nil, err
+	}
+	return vector, nil
+}
@@ -0,0 +1,206 @@
+package athenaconv
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Columnar result", func() {
+	var ctx context.Context
+	var metadata types.ResultSetMetadata
+
+	newPage := func(from, to int) *types.ResultSet {
+		page := &types.ResultSet{
+			ResultSetMetadata: &metadata,
+			Rows:              make([]types.Row, 0),
+		}
+		for i := from; i < to; i++ {
+			page.Rows = append(page.Rows, types.Row{
+				Data: []types.Datum{
+					{VarCharValue: util.RefString(strconv.Itoa(i))},
+					{VarCharValue: util.RefString(strconv.Itoa(i) + ".5")},
+					{VarCharValue: util.RefString("name " + strconv.Itoa(i))},
+					{VarCharValue: util.RefString("2021-12-31")},
+					{VarCharValue: util.RefString("true")},
+					{VarCharValue: util.RefString("[a, b]")},
+				},
+			})
+		}
+		return page
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		metadata = types.ResultSetMetadata{
+			ColumnInfo: []types.ColumnInfo{
+				{Name: util.RefString("id"), Type: util.RefString("integer")},
+				{Name: util.RefString("amount"), Type: util.RefString("double")},
+				{Name: util.RefString("name"), Type: util.RefString("varchar")},
+				{Name: util.RefString("day"), Type: util.RefString("date")},
+				{Name: util.RefString("flag"), Type: util.RefString("boolean")},
+				{Name: util.RefString("tags"), Type: util.RefString("array")},
+			},
+		}
+	})
+
+	When("pages are valid", func() {
+		It("should append all pages into typed column vectors", func() {
+			columnar, err := NewColumnarResult(ctx, []*types.ResultSet{newPage(0, 50), newPage(50, 70)})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(columnar.Len()).To(Equal(70))
+			Expect(len(columnar.Columns)).To(Equal(6))
+
+			ids, err := columnar.Int64s("id")
+			Expect(err).ToNot(HaveOccurred())
+			amounts, err := columnar.Float64s("amount")
+			Expect(err).ToNot(HaveOccurred())
+			names, err := columnar.Strings("name")
+			Expect(err).ToNot(HaveOccurred())
+			days, err := columnar.Times("day")
+			Expect(err).ToNot(HaveOccurred())
+			flags, err := columnar.Bools("flag")
+			Expect(err).ToNot(HaveOccurred())
+			tags, err := columnar.Values("tags")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(len(ids)).To(Equal(70))
+			for i := 0; i < 70; i++ {
+				Expect(ids[i]).To(Equal(int64(i)))
+				Expect(amounts[i]).To(Equal(float64(i) + 0.5))
+				Expect(names[i]).To(Equal("name " + strconv.Itoa(i)))
+				Expect(days[i]).To(Equal(time.Date(2021, 12, 31, 0, 0, 0, 0, time.UTC)))
+				Expect(flags[i]).To(BeTrue())
+				Expect(tags[i]).To(Equal([]string{"a", "b"}))
+			}
+
+			kind, err := columnar.Kind("amount")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(kind).To(Equal(ColumnKindFloat64))
+		})
+
+		It("should flag null values in the null bitmap", func() {
+			page := newPage(0, 100)
+			page.Rows[1].Data[0].VarCharValue = nil
+			page.Rows[65].Data[0].VarCharValue = nil
+
+			columnar, err := NewColumnarResult(ctx, []*types.ResultSet{page})
+			Expect(err).ToNot(HaveOccurred())
+
+			ids, err := columnar.Int64s("id")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ids[1]).To(BeZero())
+
+			isNull, err := columnar.IsNull("id", 1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(isNull).To(BeTrue())
+			isNull, err = columnar.IsNull("id", 2)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(isNull).To(BeFalse())
+
+			bitmap, err := columnar.NullBitmap("id")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(bitmap).To(Equal([]uint64{1 << 1, 1 << 1}))
+			bitmap, err = columnar.NullBitmap("name")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(bitmap).To(Equal([]uint64{0, 0}))
+		})
+	})
+
+	When("columns are tinyint or smallint", func() {
+		It("should store them as int64 vectors", func() {
+			metadata.ColumnInfo[0].Type = util.RefString("tinyint")
+			metadata.ColumnInfo[2] = types.ColumnInfo{Name: util.RefString("name"), Type: util.RefString("smallint")}
+			page := newPage(0, 2)
+			page.Rows[0].Data[2].VarCharValue = util.RefString("300")
+			page.Rows[1].Data[2].VarCharValue = util.RefString("-300")
+
+			columnar, err := NewColumnarResult(ctx, []*types.ResultSet{page})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(columnar.Kind("id")).To(Equal(ColumnKindInt64))
+			ids, err := columnar.Int64s("id")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ids).To(Equal([]int64{0, 1}))
+			values, err := columnar.Int64s("name")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(values).To(Equal([]int64{300, -300}))
+		})
+	})
+
+	When("header row mode is set", func() {
+		It("should skip the header row of the first page as the mappers", func() {
+			columnar, err := NewColumnarResult(ctx, []*types.ResultSet{newPage(0, 3), newPage(3, 5)}, WithHeaderRow(HeaderRowPresent))
+			Expect(err).ToNot(HaveOccurred())
+			ids, err := columnar.Int64s("id")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ids).To(Equal([]int64{1, 2, 4}))
+
+			header := newPage(0, 1)
+			header.Rows[0].Data = []types.Datum{
+				{VarCharValue: util.RefString("id")}, {VarCharValue: util.RefString("amount")}, {VarCharValue: util.RefString("name")},
+				{VarCharValue: util.RefString("day")}, {VarCharValue: util.RefString("flag")}, {VarCharValue: util.RefString("tags")},
+			}
+			columnar, err = NewColumnarResult(ctx, []*types.ResultSet{header})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(columnar.Len()).To(Equal(0))
+
+			_, err = NewColumnarResult(ctx, []*types.ResultSet{header}, WithHeaderRow(HeaderRowAbsent))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("column is accessed with the wrong vector type", func() {
+		It("should return error", func() {
+			columnar, err := NewColumnarResult(ctx, []*types.ResultSet{newPage(0, 1)})
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = columnar.Strings("id")
+			Expect(err).To(HaveOccurred())
+			Expect(strings.ToLower(err.Error())).To(ContainSubstring("not stored as the requested vector type"))
+
+			_, err = columnar.Int64s("unknown")
+			Expect(err).To(HaveOccurred())
+			Expect(strings.ToLower(err.Error())).To(ContainSubstring("not found"))
+		})
+	})
+
+	When("pages have mismatched metadata", func() {
+		It("should return error", func() {
+			columnar, err := NewColumnarResult(ctx, []*types.ResultSet{newPage(0, 1)})
+			Expect(err).ToNot(HaveOccurred())
+
+			otherMetadata := types.ResultSetMetadata{
+				ColumnInfo: []types.ColumnInfo{
+					{Name: util.RefString("id"), Type: util.RefString("integer")},
+				},
+			}
+			err = columnar.Append(ctx, &types.ResultSet{ResultSetMetadata: &otherMetadata})
+			Expect(err).To(HaveOccurred())
+			Expect(strings.ToLower(err.Error())).To(ContainSubstring("mismatched result set columns count"))
+		})
+	})
+
+	When("page contains invalid row data", func() {
+		It("should return error and keep previously appended rows", func() {
+			columnar, err := NewColumnarResult(ctx, []*types.ResultSet{newPage(0, 2)})
+			Expect(err).ToNot(HaveOccurred())
+
+			page := newPage(2, 4)
+			page.Rows[1].Data[0].VarCharValue = util.RefString("id")
+			err = columnar.Append(ctx, page)
+			Expect(err).To(HaveOccurred())
+			Expect(strings.ToLower(err.Error())).To(MatchRegexp("parsing .* invalid syntax"))
+
+			Expect(columnar.Len()).To(Equal(2))
+			ids, err := columnar.Int64s("id")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ids).To(Equal([]int64{0, 1}))
+		})
+	})
+})
@@ -3,6 +3,7 @@ package athenaconv
 import (
 	"context"
 	"log"
+	"reflect"
 	"strconv"
 	"strings"
 	"time"
@@ -11,6 +12,8 @@ import (
 	"github.com/kent-id/athenaconv/util"
 )
 
+// castAthenaRowData converts the text value of the column to the Go type of its athena type.
+// double, float and real columns are converted to float64, string fields of the struct mapper keep the text value, see setFieldValue.
 func castAthenaRowData(ctx context.Context, rowData types.Datum, athenaType string) (interface{}, error) {
 	data := util.SafeString(rowData.VarCharValue)
 
@@ -23,10 +26,12 @@ func castAthenaRowData(ctx context.Context, rowData types.Datum, athenaType stri
 		castedData = strings.ToLower(data) == "true"
 	case "varchar":
 		castedData = data
-	case "integer":
+	case "integer", "tinyint", "smallint":
 		castedData, err = strconv.Atoi(data)
 	case "bigint":
 		castedData, err = strconv.ParseInt(data, 10, 64)
+	case "double", "float", "real":
+		castedData, err = strconv.ParseFloat(data, 64)
 	case "array":
 		arrayValueString := strings.Trim(data, "[]")
 		newStringSlice := make([]string, 0)
@@ -46,3 +51,33 @@ func castAthenaRowData(ctx context.Context, rowData types.Datum, athenaType stri
 
 	return castedData, err
 }
+
+// castAthenaType returns the Go type of the values converted by castAthenaRowData from the athena type
+func castAthenaType(athenaType string) reflect.Type {
+	switch athenaType {
+	case "boolean":
+		return reflect.TypeOf(false)
+	case "integer", "tinyint", "smallint":
+		return reflect.TypeOf(0)
+	case "bigint":
+		return reflect.TypeOf(int64(0))
+	case "double", "float", "real":
+		return reflect.TypeOf(float64(0))
+	case "array":
+		return reflect.TypeOf([]string{})
+	case "timestamp", "date":
+		return timeType
+	}
+	return reflect.TypeOf("")
+}
+
+// canSetColumnValue returns true if setFieldValue can set the values of the athena type to the field type
+func canSetColumnValue(fieldType reflect.Type, athenaType string) bool {
+	if isJSONFieldType(fieldType) && isJSONColumnType(athenaType) {
+		return true
+	}
+	if fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	return fieldType.Kind() == reflect.String || castAthenaType(athenaType).AssignableTo(fieldType)
+}
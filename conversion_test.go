@@ -16,6 +16,7 @@ const (
 	athenaTypeString    = "varchar"
 	athenaTypeInt       = "integer"
 	athenaTypeBigInt    = "bigint"
+	athenaTypeDouble    = "double"
 	athenaTypeArray     = "array"
 	athenaTypeTimestamp = "timestamp"
 	athenaTypeDate      = "date"
@@ -72,6 +73,15 @@ var _ = Describe("Conversion", func() {
 			Expect(strings.ToLower(err.Error())).To(MatchRegexp("parsing .* invalid syntax"))
 		})
 
+		It("should return int for tinyint and smallint values", func() {
+			result, err := castAthenaRowData(ctx, types.Datum{VarCharValue: util.RefString("-128")}, "tinyint")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(-128))
+			result, err = castAthenaRowData(ctx, types.Datum{VarCharValue: util.RefString("32767")}, "smallint")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(32767))
+		})
+
 		// anything above int64 range will overflow
 		It("should return error if overflow", func() {
 			rowData := types.Datum{VarCharValue: util.RefString("9223372036854775807123213122")}
@@ -105,6 +115,29 @@ var _ = Describe("Conversion", func() {
 		})
 	})
 
+	Context("Double", func() {
+		It("should return value if valid", func() {
+			rowData := types.Datum{VarCharValue: util.RefString("-12.5")}
+			result, err := castAthenaRowData(ctx, rowData, athenaTypeDouble)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(float64(-12.5)))
+		})
+
+		It("should return value for real type", func() {
+			rowData := types.Datum{VarCharValue: util.RefString("1.0E-4")}
+			result, err := castAthenaRowData(ctx, rowData, "real")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(float64(0.0001)))
+		})
+
+		It("should return error if not valid", func() {
+			rowData := types.Datum{VarCharValue: util.RefString("12.5.1")}
+			_, err := castAthenaRowData(ctx, rowData, athenaTypeDouble)
+			Expect(err).To(HaveOccurred())
+			Expect(strings.ToLower(err.Error())).To(MatchRegexp("parsing .* invalid syntax"))
+		})
+	})
+
 	Context("Array", func() {
 		When("array has no items", func() {
 			It("should return expected array value", func() {
@@ -0,0 +1,171 @@
+package athenaconv
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv/util"
+)
+
+// CSVResultReader reads the CSV result file written by athena to the query output location, e.g. s3://bucket/path/<query execution ID>.csv,
+// into ResultSet pages that can be converted by DataMapper and DynamicMapper the same way as pages returned by GetQueryResults.
+// Like GetQueryResults, the first page starts with the header row. Quoted empty fields are empty strings and unquoted empty fields are NULL.
+type CSVResultReader struct {
+	reader   *bufio.Reader
+	metadata *types.ResultSetMetadata
+	builder  strings.Builder
+}
+
+// NewCSVResultReader creates new CSVResultReader, column names and types are taken from the metadata,
+// e.g. the ResultSetMetadata of the first GetQueryResults page
+func NewCSVResultReader(reader io.Reader, metadata *types.ResultSetMetadata) *CSVResultReader {
+	return &CSVResultReader{
+		reader:   bufio.NewReader(reader),
+		metadata: metadata,
+	}
+}
+
+// Next reads up to maxRows rows into a ResultSet with the metadata of the reader, returns io.EOF once all rows are read
+func (r *CSVResultReader) Next(maxRows int) (*types.ResultSet, error) {
+	if r.metadata == nil {
+		err := errors.New("result set metadata is missing")
+		return nil, err
+	}
+	if maxRows <= 0 {
+		err := fmt.Errorf("max rows should be positive, got %d", maxRows)
+		return nil, err
+	}
+
+	resultSet := &types.ResultSet{
+		ResultSetMetadata: r.metadata,
+		Rows:              make([]types.Row, 0),
+	}
+	for len(resultSet.Rows) < maxRows {
+		data, err := r.readRecord()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		resultSet.Rows = append(resultSet.Rows, types.Row{Data: data})
+	}
+	if len(resultSet.Rows) == 0 {
+		return nil, io.EOF
+	}
+	return resultSet, nil
+}
+
+// Skip discards the next rows, including the header row if it is not read yet, returns io.EOF if there are fewer rows left
+func (r *CSVResultReader) Skip(rows int) error {
+	for i := 0; i < rows; i++ {
+		if _, err := r.readRecord(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readRecord reads the fields of the next CSV record, returns io.EOF if there is no more record
+func (r *CSVResultReader) readRecord() ([]types.Datum, error) {
+	columnsCount := len(r.metadata.ColumnInfo)
+	data := make([]types.Datum, 0, columnsCount)
+	for {
+		value, quoted, last, err := r.readField(len(data) == 0)
+		if err != nil {
+			return nil, err
+		}
+		if quoted || value != "" {
+			data = append(data, types.Datum{VarCharValue: util.RefString(value)})
+		} else {
+			data = append(data, types.Datum{})
+		}
+		if last {
+			break
+		}
+	}
+
+	if len(data) != columnsCount {
+		err := fmt.Errorf("mismatched csv record fields count: %d, expected: %d", len(data), columnsCount)
+		return nil, err
+	}
+	return data, nil
+}
+
+// readField reads the next field of the current record, returns true if it is the last field of the record
+func (r *CSVResultReader) readField(firstField bool) (string, bool, bool, error) {
+	r.builder.Reset()
+	b, err := r.reader.ReadByte()
+	if err == io.EOF && firstField {
+		return "", false, false, io.EOF
+	}
+	if err == io.EOF {
+		return "", false, true, nil
+	}
+	if err != nil {
+		return "", false, false, err
+	}
+
+	if b != '"' {
+		for {
+			switch b {
+			case ',':
+				return r.builder.String(), false, false, nil
+			case '\n':
+				return strings.TrimSuffix(r.builder.String(), "\r"), false, true, nil
+			}
+			r.builder.WriteByte(b)
+			b, err = r.reader.ReadByte()
+			if err == io.EOF {
+				return r.builder.String(), false, true, nil
+			}
+			if err != nil {
+				return "", false, false, err
+			}
+		}
+	}
+
+	for {
+		b, err = r.reader.ReadByte()
+		if err == io.EOF {
+			err := errors.New("unterminated quoted field in csv record")
+			return "", false, false, err
+		}
+		if err != nil {
+			return "", false, false, err
+		}
+		if b != '"' {
+			r.builder.WriteByte(b)
+			continue
+		}
+
+		// closing quote or escaped quote
+		b, err = r.reader.ReadByte()
+		if err == io.EOF {
+			return r.builder.String(), true, true, nil
+		}
+		if err != nil {
+			return "", false, false, err
+		}
+		switch b {
+		case '"':
+			r.builder.WriteByte('"')
+		case ',':
+			return r.builder.String(), true, false, nil
+		case '\n':
+			return r.builder.String(), true, true, nil
+		case '\r':
+			if next, err := r.reader.ReadByte(); err == nil && next != '\n' {
+				r.reader.UnreadByte()
+			}
+			return r.builder.String(), true, true, nil
+		default:
+			err := fmt.Errorf("unexpected character %q after quoted field in csv record", b)
+			return "", false, false, err
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package athenaconv
+
+import (
+	"context"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CSV result reader", func() {
+	var ctx context.Context
+	var metadata types.ResultSetMetadata
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		metadata = types.ResultSetMetadata{
+			ColumnInfo: []types.ColumnInfo{
+				{Name: util.RefString("id"), Type: util.RefString("integer")},
+				{Name: util.RefString("name"), Type: util.RefString("varchar")},
+				{Name: util.RefString("tags"), Type: util.RefString("array")},
+			},
+		}
+	})
+
+	When("csv is valid", func() {
+		It("should read pages starting with the header row", func() {
+			// arrange
+			csv := "\"id\",\"name\",\"tags\"\n\"1\",\"one\",\"[a, b]\"\n\"2\",\"two\",\"[]\"\n\"3\",\"three\",\"[c]\"\n"
+			reader := NewCSVResultReader(strings.NewReader(csv), &metadata)
+
+			// act
+			first, err := reader.Next(2)
+			Expect(err).ToNot(HaveOccurred())
+			second, err := reader.Next(2)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = reader.Next(2)
+
+			// assert
+			Expect(err).To(Equal(io.EOF))
+			Expect(len(first.Rows)).To(Equal(2))
+			Expect(len(second.Rows)).To(Equal(2))
+			Expect(first.ResultSetMetadata).To(Equal(&metadata))
+			Expect(*first.Rows[0].Data[1].VarCharValue).To(Equal("name"))
+			Expect(*second.Rows[1].Data[1].VarCharValue).To(Equal("three"))
+		})
+
+		It("should read quoted, escaped and null fields", func() {
+			csv := "\"id\",\"name\",\"tags\"\r\n\"1\",\"say \"\"hi\"\",\nbye\",\r\n\"2\",\"\",\"[]\""
+			reader := NewCSVResultReader(strings.NewReader(csv), &metadata)
+
+			resultSet, err := reader.Next(10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(resultSet.Rows)).To(Equal(3))
+			Expect(*resultSet.Rows[1].Data[1].VarCharValue).To(Equal("say \"hi\",\nbye"))
+			Expect(resultSet.Rows[1].Data[2].VarCharValue).To(BeNil())
+			Expect(*resultSet.Rows[2].Data[1].VarCharValue).To(Equal(""))
+			Expect(*resultSet.Rows[2].Data[2].VarCharValue).To(Equal("[]"))
+		})
+
+		It("should feed rows through the mappers", func() {
+			type model struct {
+				ID   int      `athenaconv:"id"`
+				Name string   `athenaconv:"name"`
+				Tags []string `athenaconv:"tags"`
+			}
+			csv := "\"id\",\"name\",\"tags\"\n\"1\",\"one\",\"[a, b]\"\n"
+			reader := NewCSVResultReader(strings.NewReader(csv), &metadata)
+			resultSet, err := reader.Next(10)
+			Expect(err).ToNot(HaveOccurred())
+
+			mapper, err := NewMapperFor(reflect.TypeOf(model{}))
+			Expect(err).ToNot(HaveOccurred())
+			mapped, err := mapper.FromAthenaResultSetV2(ctx, resultSet)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mapped).To(Equal([]interface{}{&model{ID: 1, Name: "one", Tags: []string{"a", "b"}}}))
+		})
+
+		It("should skip rows including the header row", func() {
+			csv := "\"id\",\"name\",\"tags\"\n\"1\",\"one\",\"[]\"\n\"2\",\"two\",\"[]\"\n"
+			reader := NewCSVResultReader(strings.NewReader(csv), &metadata)
+
+			Expect(reader.Skip(2)).To(Succeed())
+			resultSet, err := reader.Next(10)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(len(resultSet.Rows)).To(Equal(1))
+			Expect(*resultSet.Rows[0].Data[0].VarCharValue).To(Equal("2"))
+			Expect(reader.Skip(1)).To(Equal(io.EOF))
+		})
+	})
+
+	When("csv is invalid", func() {
+		It("should return error for mismatched fields count", func() {
+			reader := NewCSVResultReader(strings.NewReader("\"id\",\"name\"\n"), &metadata)
+			_, err := reader.Next(10)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("mismatched csv record fields count: 2, expected: 3"))
+		})
+
+		It("should return error for malformed quoted fields", func() {
+			reader := NewCSVResultReader(strings.NewReader("\"id\"x,\"name\",\"tags\"\n"), &metadata)
+			_, err := reader.Next(10)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("after quoted field"))
+
+			reader = NewCSVResultReader(strings.NewReader("\"id,\"name\",\"tags\"\n"), &metadata)
+			_, err = reader.Next(10)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should return error if metadata is missing", func() {
+			reader := NewCSVResultReader(strings.NewReader(""), nil)
+			_, err := reader.Next(10)
+			Expect(err).To(MatchError("result set metadata is missing"))
+		})
+	})
+})
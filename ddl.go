@@ -0,0 +1,317 @@
+package athenaconv
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// StorageFormat is the storage format of the data files of the table generated by GenerateDDL
+type StorageFormat string
+
+const (
+	// FormatParquet stores the data in Parquet files, this is the default
+	FormatParquet StorageFormat = "PARQUET"
+	// FormatORC stores the data in ORC files
+	FormatORC StorageFormat = "ORC"
+	// FormatJSON stores the data in JSON lines files read with the OpenX JSON SerDe, not supported by Iceberg tables
+	FormatJSON StorageFormat = "JSON"
+	// FormatCSV stores the data in comma separated text files, not supported by Iceberg tables
+	FormatCSV StorageFormat = "CSV"
+)
+
+// DDLOptions defines the table generated by GenerateDDL
+type DDLOptions struct {
+	// Table is the name of the table, optionally qualified by the database, e.g. my_db.my_table
+	Table string
+	// Format of the data files, defaults to FormatParquet
+	Format StorageFormat
+	// Location is the S3 location of the data files, e.g. s3://bucket/path/, optional
+	Location string
+	// TableProperties are added to TBLPROPERTIES in key order, e.g. parquet.compression
+	TableProperties map[string]string
+	// IfNotExists adds IF NOT EXISTS to the statement
+	IfNotExists bool
+	// Iceberg generates CREATE TABLE of an Iceberg table instead of CREATE EXTERNAL TABLE of a Hive table.
+	// Partition columns of Iceberg tables are part of the table columns, and only Parquet and ORC formats are supported.
+	Iceberg bool
+	// NameMatcher derives the column names of fields without athenaconv tag, defaults to ExactNameMatcher
+	NameMatcher NameMatcher
+}
+
+// GenerateDDL generates the CREATE EXTERNAL TABLE statement of the table of modelType, with the athena types inferred from the Go types
+// of the fields, e.g. []string as array<string>, map[string]int64 as map<string,bigint> and nested structs as struct<...>.
+// Fields marked with the partition tag option are the partition columns, in field order.
+//
+// Example:
+//
+//	type Event struct {
+//		ID   int64    `athenaconv:"id"`
+//		Tags []string `athenaconv:"tags"`
+//		Day  string   `athenaconv:"day,partition"`
+//	}
+//	ddl, err := athenaconv.GenerateDDL(reflect.TypeOf(Event{}), athenaconv.DDLOptions{Table: "my_db.events", Location: "s3://bucket/events/"})
+func GenerateDDL(modelType reflect.Type, options DDLOptions) (string, error) {
+	if options.Format == "" {
+		options.Format = FormatParquet
+	}
+	if options.NameMatcher == nil {
+		options.NameMatcher = ExactNameMatcher
+	}
+	if options.Iceberg && options.Format != FormatParquet && options.Format != FormatORC {
+		err := fmt.Errorf("storage format %s is not supported by iceberg tables", options.Format)
+		return "", err
+	}
+
+	table, err := quoteTableName(options.Table)
+	if err != nil {
+		return "", err
+	}
+	columns, partitions, err := ddlColumns(modelType, options)
+	if err != nil {
+		return "", err
+	}
+
+	var builder strings.Builder
+	if options.Iceberg {
+		builder.WriteString("CREATE TABLE ")
+	} else {
+		builder.WriteString("CREATE EXTERNAL TABLE ")
+	}
+	if options.IfNotExists {
+		builder.WriteString("IF NOT EXISTS ")
+	}
+	builder.WriteString(table + " (\n  " + strings.Join(columns, ",\n  ") + "\n)")
+	if len(partitions) > 0 {
+		builder.WriteString("\nPARTITIONED BY (\n  " + strings.Join(partitions, ",\n  ") + "\n)")
+	}
+	if !options.Iceberg {
+		builder.WriteString("\n" + storageClause(options.Format))
+	}
+	if options.Location != "" {
+		builder.WriteString("\nLOCATION " + quoteDDLString(options.Location))
+	}
+
+	properties := tableProperties(options)
+	if len(properties) > 0 {
+		builder.WriteString("\nTBLPROPERTIES (\n  " + strings.Join(properties, ",\n  ") + "\n)")
+	}
+	return builder.String(), nil
+}
+
+// ddlColumn is a table column of the model
+type ddlColumn struct {
+	name       string
+	columnType string
+	partition  bool
+}
+
+// ddlColumns returns the column definitions and partition column definitions of modelType in field order.
+// Partition columns of iceberg tables are defined with the other columns and listed by name only.
+func ddlColumns(modelType reflect.Type, options DDLOptions) ([]string, []string, error) {
+	modelColumns, err := modelDDLColumns(modelType, options.NameMatcher, options.Iceberg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	columns := make([]string, 0, len(modelColumns))
+	partitions := make([]string, 0)
+	for _, modelColumn := range modelColumns {
+		name, err := quoteDDLIdentifier(modelColumn.name)
+		if err != nil {
+			return nil, nil, err
+		}
+		column := name + " " + modelColumn.columnType
+		switch {
+		case modelColumn.partition && options.Iceberg:
+			columns = append(columns, column)
+			partitions = append(partitions, name)
+		case modelColumn.partition:
+			partitions = append(partitions, column)
+		default:
+			columns = append(columns, column)
+		}
+	}
+	if len(columns) == 0 {
+		err := fmt.Errorf("at least one column should not be a partition column of table of type: %s", modelType.String())
+		return nil, nil, err
+	}
+	return columns, partitions, nil
+}
+
+// modelDDLColumns returns the table columns of modelType in field order
+func modelDDLColumns(modelType reflect.Type, matcher NameMatcher, iceberg bool) ([]ddlColumn, error) {
+	modelDefinitionSchema, err := newModelDefinitionMap(modelType, matcher)
+	if err != nil {
+		return nil, err
+	}
+	definitions := make([]modelDefinitionColInfo, 0, len(modelDefinitionSchema))
+	for _, colInfo := range modelDefinitionSchema {
+		definitions = append(definitions, colInfo)
+	}
+	sort.Slice(definitions, func(i, j int) bool {
+		return definitions[i].fieldIndex < definitions[j].fieldIndex
+	})
+
+	columns := make([]ddlColumn, 0, len(definitions))
+	for _, colInfo := range definitions {
+		key, err := parseColumnKey(colInfo.columnName)
+		if err != nil {
+			return nil, err
+		}
+		if key.isPositional() || key.occurrence > 1 {
+			err := fmt.Errorf("column key '%s' of field %s is not a table column name", colInfo.columnName, colInfo.fieldName)
+			return nil, err
+		}
+		columnType, err := ddlTypeOf(modelType.Field(colInfo.fieldIndex).Type, iceberg)
+		if err != nil {
+			err := fmt.Errorf("invalid type of field %s: %v", colInfo.fieldName, err)
+			return nil, err
+		}
+		columns = append(columns, ddlColumn{name: key.name, columnType: columnType, partition: colInfo.partition})
+	}
+	return columns, nil
+}
+
+// ddlTypeOf returns the athena DDL type of the Go type, iceberg tables have no tinyint and smallint
+func ddlTypeOf(goType reflect.Type, iceberg bool) (string, error) {
+	switch goType {
+	case timeType:
+		return "timestamp", nil
+	case dateType:
+		return "date", nil
+	case decimalType:
+		return "decimal(38,18)", nil
+	case bytesType:
+		return "binary", nil
+	}
+
+	switch goType.Kind() {
+	case reflect.Ptr:
+		return ddlTypeOf(goType.Elem(), iceberg)
+	case reflect.String:
+		return "string", nil
+	case reflect.Bool:
+		return "boolean", nil
+	case reflect.Int8:
+		if iceberg {
+			return "int", nil
+		}
+		return "tinyint", nil
+	case reflect.Int16, reflect.Uint8:
+		if iceberg {
+			return "int", nil
+		}
+		return "smallint", nil
+	case reflect.Int32, reflect.Uint16:
+		return "int", nil
+	case reflect.Int, reflect.Int64, reflect.Uint32:
+		return "bigint", nil
+	case reflect.Uint, reflect.Uint64:
+		return "decimal(20,0)", nil
+	case reflect.Float32:
+		return "float", nil
+	case reflect.Float64:
+		return "double", nil
+	case reflect.Slice, reflect.Array:
+		elemType, err := ddlTypeOf(goType.Elem(), iceberg)
+		if err != nil {
+			return "", err
+		}
+		return "array<" + elemType + ">", nil
+	case reflect.Map:
+		keyType, err := ddlTypeOf(goType.Key(), iceberg)
+		if err != nil {
+			return "", err
+		}
+		elemType, err := ddlTypeOf(goType.Elem(), iceberg)
+		if err != nil {
+			return "", err
+		}
+		return "map<" + keyType + "," + elemType + ">", nil
+	case reflect.Struct:
+		fields := rowFields(goType)
+		if len(fields) == 0 {
+			err := fmt.Errorf("struct %s has no exported fields", goType)
+			return "", err
+		}
+		fieldTypes := make([]string, 0, len(fields))
+		for _, field := range fields {
+			fieldType, err := ddlTypeOf(field.Type, iceberg)
+			if err != nil {
+				return "", err
+			}
+			fieldTypes = append(fieldTypes, rowFieldName(field)+":"+fieldType)
+		}
+		return "struct<" + strings.Join(fieldTypes, ",") + ">", nil
+	}
+	err := fmt.Errorf("unsupported DDL type: %s", goType)
+	return "", err
+}
+
+// storageClause returns the ROW FORMAT and STORED AS clauses of the storage format of hive tables
+func storageClause(format StorageFormat) string {
+	switch format {
+	case FormatJSON:
+		return "ROW FORMAT SERDE 'org.openx.data.jsonserde.JsonSerDe'\nSTORED AS TEXTFILE"
+	case FormatCSV:
+		return "ROW FORMAT DELIMITED\n  FIELDS TERMINATED BY ','\nSTORED AS TEXTFILE"
+	}
+	return "STORED AS " + string(format)
+}
+
+// tableProperties returns the TBLPROPERTIES entries in key order, iceberg tables define their type and format
+func tableProperties(options DDLOptions) []string {
+	properties := make(map[string]string, len(options.TableProperties)+2)
+	for key, value := range options.TableProperties {
+		properties[key] = value
+	}
+	if options.Iceberg {
+		properties["table_type"] = "ICEBERG"
+		properties["format"] = strings.ToLower(string(options.Format))
+	}
+
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	entries := make([]string, 0, len(keys))
+	for _, key := range keys {
+		entries = append(entries, quoteDDLString(key)+"="+quoteDDLString(properties[key]))
+	}
+	return entries
+}
+
+// quoteTableName quotes every part of the table name qualified by the database, e.g. `my_db`.`my_table`
+func quoteTableName(table string) (string, error) {
+	if strings.TrimSpace(table) == "" {
+		err := fmt.Errorf("missing table name")
+		return "", err
+	}
+	parts := strings.Split(table, ".")
+	for i, part := range parts {
+		quoted, err := quoteDDLIdentifier(part)
+		if err != nil {
+			return "", err
+		}
+		parts[i] = quoted
+	}
+	return strings.Join(parts, "."), nil
+}
+
+// quoteDDLIdentifier quotes the identifier with backticks as required by DDL statements
+func quoteDDLIdentifier(name string) (string, error) {
+	if name == "" || strings.Contains(name, "`") {
+		err := fmt.Errorf("invalid DDL identifier: '%s'", name)
+		return "", err
+	}
+	return "`" + name + "`", nil
+}
+
+// quoteDDLString quotes the string literal of DDL statements, escaping quotes with backslash as in hive
+func quoteDDLString(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	return "'" + replacer.Replace(value) + "'"
+}
@@ -0,0 +1,172 @@
+package athenaconv
+
+import (
+	"reflect"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GenerateDDL", func() {
+	type location struct {
+		City    string  `athenaconv:"city"`
+		Lat     float64 `athenaconv:"lat"`
+		private string
+	}
+	type event struct {
+		ID        int64             `athenaconv:"id"`
+		Kind      int8              `athenaconv:"kind"`
+		Tags      []string          `athenaconv:"tags"`
+		Counts    map[string]int64  `athenaconv:"counts"`
+		Location  *location         `athenaconv:"location"`
+		Visits    []location        `athenaconv:"visits"`
+		Amount    Decimal           `athenaconv:"amount"`
+		Payload   []byte            `athenaconv:"payload"`
+		CreatedAt time.Time         `athenaconv:"created_at"`
+		Active    bool              `athenaconv:"active"`
+		Labels    map[int32]float32 `athenaconv:"labels"`
+		Day       Date              `athenaconv:"day,partition"`
+		Region    string            `athenaconv:"region,partition"`
+	}
+
+	When("table is a hive table", func() {
+		It("should generate CREATE EXTERNAL TABLE with inferred types", func() {
+			ddl, err := GenerateDDL(reflect.TypeOf(event{}), DDLOptions{
+				Table:           "my_db.events",
+				Location:        "s3://bucket/events/",
+				TableProperties: map[string]string{"parquet.compression": "SNAPPY", "classification": "parquet"},
+				IfNotExists:     true,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ddl).To(Equal("CREATE EXTERNAL TABLE IF NOT EXISTS `my_db`.`events` (\n" +
+				"  `id` bigint,\n" +
+				"  `kind` tinyint,\n" +
+				"  `tags` array<string>,\n" +
+				"  `counts` map<string,bigint>,\n" +
+				"  `location` struct<city:string,lat:double>,\n" +
+				"  `visits` array<struct<city:string,lat:double>>,\n" +
+				"  `amount` decimal(38,18),\n" +
+				"  `payload` binary,\n" +
+				"  `created_at` timestamp,\n" +
+				"  `active` boolean,\n" +
+				"  `labels` map<int,float>\n" +
+				")\n" +
+				"PARTITIONED BY (\n" +
+				"  `day` date,\n" +
+				"  `region` string\n" +
+				")\n" +
+				"STORED AS PARQUET\n" +
+				"LOCATION 's3://bucket/events/'\n" +
+				"TBLPROPERTIES (\n" +
+				"  'classification'='parquet',\n" +
+				"  'parquet.compression'='SNAPPY'\n" +
+				")"))
+		})
+
+		It("should generate the row format of JSON and CSV formats", func() {
+			type model struct {
+				ID int `athenaconv:"id"`
+			}
+			ddl, err := GenerateDDL(reflect.TypeOf(model{}), DDLOptions{Table: "t", Format: FormatJSON})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ddl).To(Equal("CREATE EXTERNAL TABLE `t` (\n  `id` bigint\n)\nROW FORMAT SERDE 'org.openx.data.jsonserde.JsonSerDe'\nSTORED AS TEXTFILE"))
+
+			ddl, err = GenerateDDL(reflect.TypeOf(model{}), DDLOptions{Table: "t", Format: FormatCSV})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ddl).To(ContainSubstring("ROW FORMAT DELIMITED\n  FIELDS TERMINATED BY ','\nSTORED AS TEXTFILE"))
+
+			ddl, err = GenerateDDL(reflect.TypeOf(model{}), DDLOptions{Table: "t", Format: FormatORC})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ddl).To(HaveSuffix("\nSTORED AS ORC"))
+		})
+
+		It("should derive untagged column names with the name matcher", func() {
+			type model struct {
+				SourceID int64
+			}
+			ddl, err := GenerateDDL(reflect.TypeOf(model{}), DDLOptions{Table: "t", NameMatcher: SnakeCaseNameMatcher})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ddl).To(HavePrefix("CREATE EXTERNAL TABLE `t` (\n  `source_id` bigint\n)"))
+		})
+
+		It("should escape quotes of string literals", func() {
+			type model struct {
+				ID int `athenaconv:"id"`
+			}
+			ddl, err := GenerateDDL(reflect.TypeOf(model{}), DDLOptions{Table: "t", TableProperties: map[string]string{"comment": `it's \ ok`}})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ddl).To(ContainSubstring(`'comment'='it\'s \\ ok'`))
+		})
+	})
+
+	When("table is an iceberg table", func() {
+		It("should generate CREATE TABLE with partition columns in the table columns", func() {
+			type model struct {
+				ID    int16     `athenaconv:"id"`
+				At    time.Time `athenaconv:"at"`
+				Shard string    `athenaconv:"shard,partition"`
+			}
+			ddl, err := GenerateDDL(reflect.TypeOf(model{}), DDLOptions{
+				Table:    "my_db.events",
+				Location: "s3://bucket/iceberg/events/",
+				Iceberg:  true,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ddl).To(Equal("CREATE TABLE `my_db`.`events` (\n" +
+				"  `id` int,\n" +
+				"  `at` timestamp,\n" +
+				"  `shard` string\n" +
+				")\n" +
+				"PARTITIONED BY (\n" +
+				"  `shard`\n" +
+				")\n" +
+				"LOCATION 's3://bucket/iceberg/events/'\n" +
+				"TBLPROPERTIES (\n" +
+				"  'format'='parquet',\n" +
+				"  'table_type'='ICEBERG'\n" +
+				")"))
+		})
+
+		It("should return error for text formats", func() {
+			_, err := GenerateDDL(reflect.TypeOf(event{}), DDLOptions{Table: "t", Iceberg: true, Format: FormatCSV})
+			Expect(err).To(MatchError("storage format CSV is not supported by iceberg tables"))
+		})
+	})
+
+	When("input is invalid", func() {
+		It("should return error for missing table name", func() {
+			_, err := GenerateDDL(reflect.TypeOf(event{}), DDLOptions{})
+			Expect(err).To(MatchError("missing table name"))
+		})
+
+		It("should return error for invalid identifier", func() {
+			_, err := GenerateDDL(reflect.TypeOf(event{}), DDLOptions{Table: "db..t"})
+			Expect(err).To(MatchError("invalid DDL identifier: ''"))
+		})
+
+		It("should return error for unsupported field type", func() {
+			type model struct {
+				Any interface{} `athenaconv:"any"`
+			}
+			_, err := GenerateDDL(reflect.TypeOf(model{}), DDLOptions{Table: "t"})
+			Expect(err).To(MatchError("invalid type of field Any: unsupported DDL type: interface {}"))
+		})
+
+		It("should return error for positional column key", func() {
+			type model struct {
+				First string `athenaconv:"#0"`
+			}
+			_, err := GenerateDDL(reflect.TypeOf(model{}), DDLOptions{Table: "t"})
+			Expect(err).To(MatchError("column key '#0' of field First is not a table column name"))
+		})
+
+		It("should return error if every column is a partition column", func() {
+			type model struct {
+				Day string `athenaconv:"day,partition"`
+			}
+			_, err := GenerateDDL(reflect.TypeOf(model{}), DDLOptions{Table: "t"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -5,10 +5,10 @@ import (
 	"fmt"
 	"log"
 	"reflect"
-	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/kent-id/athenaconv/client"
 )
 
 // MyModel defines a schema that corresponds with your testSQL above
@@ -48,49 +48,62 @@ limit 20
 		handleError(err)
 	}
 
-	client := NewAthenaClientV2(ctx, awsConfig, "datalab", "datalab", "AwsDataCatalog")
+	athenaClient := client.NewFromConfig(awsConfig, client.Config{
+		WorkGroup: "datalab",
+		Catalog:   "AwsDataCatalog",
+		Database:  "datalab",
+	})
 
 	log.Println("with channel:")
-	exampleWithChannel(ctx, client, sql)
+	exampleWithChannel(ctx, athenaClient, sql)
+
+	log.Println("with iterator:")
+	exampleWithIterator(ctx, athenaClient, sql)
 
 	log.Println("without channel:")
-	exampleWithoutChannel(ctx, client, sql)
+	exampleWithoutChannel(ctx, athenaClient, sql)
 
 	log.Println("program finished")
 }
 
-func exampleWithChannel(ctx context.Context, client AthenaClientV2, sql string) {
-	var wg sync.WaitGroup
-	resultsChan := make(chan interface{})
-	errorsChan := make(chan error)
+func exampleWithChannel(ctx context.Context, athenaClient *client.Client, sql string) {
+	rows, errs := athenaClient.QueryChannel(ctx, sql, reflect.TypeOf(MyModel{}))
+	for row := range rows {
+		nextRow := row.(*MyModel)
+		log.Println("msg", "received next row data", "data", fmt.Sprintf("%+v", nextRow))
+	}
+	if err := <-errs; err != nil {
+		handleError(err)
+	}
+}
 
-	wg.Add(2)
-	go func() {
-		for item := range resultsChan {
-			nextRow := item.(*MyModel)
-			log.Println("msg", "received next row data", "data", fmt.Sprintf("%+v", nextRow))
-		}
-		wg.Done()
-	}()
-	go func() {
-		for err := range errorsChan {
+func exampleWithIterator(ctx context.Context, athenaClient *client.Client, sql string) {
+	rows, err := athenaClient.QueryIterator(ctx, sql, reflect.TypeOf(MyModel{}))
+	if err != nil {
+		handleError(err)
+	}
+	defer rows.Close()
+
+	for rows.Next(ctx) {
+		var nextRow MyModel
+		if err := rows.Scan(&nextRow); err != nil {
 			handleError(err)
 		}
-		wg.Done()
-	}()
-
-	client.GetQueryResultsIntoChannel(ctx, sql, reflect.TypeOf(MyModel{}), resultsChan, errorsChan)
-	wg.Wait()
+		log.Println("msg", "received next row data", "data", fmt.Sprintf("%+v", nextRow))
+	}
+	if err := rows.Err(); err != nil {
+		handleError(err)
+	}
 }
 
-func exampleWithoutChannel(ctx context.Context, client AthenaClientV2, sql string) {
-	result, err := client.GetQueryResults(ctx, sql, reflect.TypeOf(MyModel{}))
+func exampleWithoutChannel(ctx context.Context, athenaClient *client.Client, sql string) {
+	var result []MyModel
+	err := athenaClient.QueryInto(ctx, sql, &result)
 	if err != nil {
 		handleError(err)
 	}
 	for _, item := range result {
-		nextRow := item.(*MyModel)
-		log.Println("msg", "row data", "data", fmt.Sprintf("%+v", nextRow))
+		log.Println("msg", "row data", "data", fmt.Sprintf("%+v", item))
 	}
 }
 
@@ -122,11 +122,6 @@ func main() {
 				handleError(err)
 			}
 
-			// skip header row if first page results
-			if page == 1 && len(queryResultOutput.ResultSet.Rows) > 0 {
-				queryResultOutput.ResultSet.Rows = queryResultOutput.ResultSet.Rows[1:]
-			}
-
 			mapped, err := mapper.FromAthenaResultSetV2(ctx, queryResultOutput.ResultSet)
 			if err != nil {
 				handleError(err)
@@ -3,9 +3,9 @@ module github.com/kent-id/athenaconv
 go 1.16
 
 require (
-	github.com/aws/aws-sdk-go-v2 v1.9.1
+	github.com/aws/aws-sdk-go-v2 v1.17.1
 	github.com/aws/aws-sdk-go-v2/config v1.8.2
-	github.com/aws/aws-sdk-go-v2/service/athena v1.6.1
+	github.com/aws/aws-sdk-go-v2/service/athena v1.20.0
 	github.com/fsnotify/fsnotify v1.5.1 // indirect
 	github.com/onsi/ginkgo v1.16.4
 	github.com/onsi/gomega v1.16.0
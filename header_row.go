@@ -0,0 +1,51 @@
+package athenaconv
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv/util"
+)
+
+// HeaderRow controls whether the first row of a ResultSet is treated as the header row
+type HeaderRow int
+
+const (
+	// HeaderRowAuto skips the first row of a ResultSet only if its values equal the ColumnInfo names, this is the default
+	HeaderRowAuto HeaderRow = iota
+	// HeaderRowPresent always skips the first row of a ResultSet, only use it when mapping the first page of results
+	HeaderRowPresent
+	// HeaderRowAbsent never skips any row, e.g. for DDL or SHOW results whose data may equal the column names
+	HeaderRowAbsent
+)
+
+// skipHeaderRow returns the rows without the header row according to the given mode
+func skipHeaderRow(rows []types.Row, metadata *types.ResultSetMetadata, headerRow HeaderRow) []types.Row {
+	if len(rows) == 0 {
+		return rows
+	}
+
+	switch headerRow {
+	case HeaderRowPresent:
+		return rows[1:]
+	case HeaderRowAbsent:
+		return rows
+	default:
+		if isHeaderRow(rows[0], metadata) {
+			return rows[1:]
+		}
+		return rows
+	}
+}
+
+// isHeaderRow returns true if the values of the row equal the ColumnInfo names of the result set metadata
+func isHeaderRow(row types.Row, metadata *types.ResultSetMetadata) bool {
+	if metadata == nil || len(metadata.ColumnInfo) == 0 || len(row.Data) != len(metadata.ColumnInfo) {
+		return false
+	}
+
+	for index, columnInfo := range metadata.ColumnInfo {
+		if row.Data[index].VarCharValue == nil || *row.Data[index].VarCharValue != util.SafeString(columnInfo.Name) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+package athenaconv
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Header row", func() {
+	var metadata types.ResultSetMetadata
+	var headerRow types.Row
+	var dataRow types.Row
+
+	BeforeEach(func() {
+		metadata = types.ResultSetMetadata{
+			ColumnInfo: []types.ColumnInfo{
+				{Name: util.RefString("my_id_col"), Type: util.RefString("integer")},
+				{Name: util.RefString("name_col"), Type: util.RefString("varchar")},
+			},
+		}
+		headerRow = types.Row{Data: []types.Datum{
+			{VarCharValue: util.RefString("my_id_col")},
+			{VarCharValue: util.RefString("name_col")},
+		}}
+		dataRow = types.Row{Data: []types.Datum{
+			{VarCharValue: util.RefString("1")},
+			{VarCharValue: util.RefString("name_col")},
+		}}
+	})
+
+	Context("isHeaderRow", func() {
+		It("should return true if values equal column names", func() {
+			Expect(isHeaderRow(headerRow, &metadata)).To(BeTrue())
+		})
+
+		It("should return false if any value differs from column names", func() {
+			Expect(isHeaderRow(dataRow, &metadata)).To(BeFalse())
+		})
+
+		It("should return false if any value is null", func() {
+			headerRow.Data[1].VarCharValue = nil
+			Expect(isHeaderRow(headerRow, &metadata)).To(BeFalse())
+		})
+
+		It("should return false if row length differs from column count", func() {
+			headerRow.Data = headerRow.Data[1:]
+			Expect(isHeaderRow(headerRow, &metadata)).To(BeFalse())
+		})
+
+		It("should return false if metadata is missing", func() {
+			Expect(isHeaderRow(headerRow, nil)).To(BeFalse())
+		})
+	})
+
+	Context("skipHeaderRow", func() {
+		When("mode is auto", func() {
+			It("should skip detected header row", func() {
+				rows := skipHeaderRow([]types.Row{headerRow, dataRow}, &metadata, HeaderRowAuto)
+				Expect(rows).To(Equal([]types.Row{dataRow}))
+			})
+
+			It("should not skip data row", func() {
+				rows := skipHeaderRow([]types.Row{dataRow, dataRow}, &metadata, HeaderRowAuto)
+				Expect(len(rows)).To(Equal(2))
+			})
+
+			It("should handle empty rows", func() {
+				rows := skipHeaderRow([]types.Row{}, &metadata, HeaderRowAuto)
+				Expect(len(rows)).To(Equal(0))
+			})
+		})
+
+		When("mode is present", func() {
+			It("should always skip first row", func() {
+				rows := skipHeaderRow([]types.Row{dataRow, dataRow}, &metadata, HeaderRowPresent)
+				Expect(len(rows)).To(Equal(1))
+			})
+		})
+
+		When("mode is absent", func() {
+			It("should never skip first row", func() {
+				rows := skipHeaderRow([]types.Row{headerRow, dataRow}, &metadata, HeaderRowAbsent)
+				Expect(rows).To(Equal([]types.Row{headerRow, dataRow}))
+			})
+		})
+	})
+})
@@ -0,0 +1,264 @@
+package athenaconv
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// jsonTimestampLayouts are the layouts of timestamps and dates formatted by athena in JSON
+var jsonTimestampLayouts = []string{
+	"2006-01-02 15:04:05.000",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	time.RFC3339Nano,
+}
+
+// isJSONFieldType returns true if the field type is a complex type, i.e. slice, array, map or struct other than time.Time,
+// which is decoded from JSON when the column is selected as json_format(cast(... as json)), see CastComplexToJSON
+func isJSONFieldType(fieldType reflect.Type) bool {
+	switch fieldType.Kind() {
+	case reflect.Ptr:
+		return isJSONFieldType(fieldType.Elem())
+	case reflect.Slice:
+		return fieldType.Elem().Kind() != reflect.Uint8
+	case reflect.Array, reflect.Map:
+		return true
+	case reflect.Struct:
+		return fieldType != timeType && fieldType != dateType
+	}
+	return false
+}
+
+// isJSONColumnType returns true if the athena column type holds JSON text of complex fields
+func isJSONColumnType(athenaType string) bool {
+	return athenaType == "varchar" || athenaType == "json"
+}
+
+// decodeJSONColumn decodes the JSON text of a complex column into a new value of the field type.
+// ROW values are decoded from JSON objects keyed by field name, or from JSON arrays in field order as cast by older athena engines.
+func decodeJSONColumn(data *string, fieldType reflect.Type) (reflect.Value, error) {
+	value := reflect.New(fieldType).Elem()
+	if data == nil {
+		return value, nil
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(*data))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		err := fmt.Errorf("invalid JSON column of type %s: %v", fieldType, err)
+		return reflect.Value{}, err
+	}
+	if err := assignJSONValue(value, decoded); err != nil {
+		err := fmt.Errorf("cannot decode JSON column into %s: %v", fieldType, err)
+		return reflect.Value{}, err
+	}
+	return value, nil
+}
+
+// assignJSONValue assigns the decoded JSON value to the settable value, converting athena JSON formatting of timestamps, decimals and keys
+func assignJSONValue(value reflect.Value, decoded interface{}) error {
+	if decoded == nil {
+		value.Set(reflect.Zero(value.Type()))
+		return nil
+	}
+
+	switch value.Type() {
+	case timeType, dateType:
+		text, ok := decoded.(string)
+		if !ok {
+			return jsonTypeError(decoded, value.Type())
+		}
+		parsed, err := parseJSONTimestamp(text)
+		if err != nil {
+			return err
+		}
+		value.Set(reflect.ValueOf(parsed).Convert(value.Type()))
+		return nil
+	case bytesType:
+		text, ok := decoded.(string)
+		if !ok {
+			return jsonTypeError(decoded, value.Type())
+		}
+		bytes, err := base64.StdEncoding.DecodeString(text)
+		if err != nil {
+			return err
+		}
+		value.SetBytes(bytes)
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(value.Type().Elem())
+		if err := assignJSONValue(elem.Elem(), decoded); err != nil {
+			return err
+		}
+		value.Set(elem)
+	case reflect.Interface:
+		value.Set(reflect.ValueOf(decoded))
+	case reflect.String:
+		switch typed := decoded.(type) {
+		case string:
+			value.SetString(typed)
+		case json.Number:
+			// decimals are formatted as JSON numbers
+			value.SetString(typed.String())
+		default:
+			return jsonTypeError(decoded, value.Type())
+		}
+	case reflect.Bool:
+		typed, ok := decoded.(bool)
+		if !ok {
+			return jsonTypeError(decoded, value.Type())
+		}
+		value.SetBool(typed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number, err := jsonNumberText(decoded, value.Type())
+		if err != nil {
+			return err
+		}
+		parsed, err := strconv.ParseInt(number, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		number, err := jsonNumberText(decoded, value.Type())
+		if err != nil {
+			return err
+		}
+		parsed, err := strconv.ParseUint(number, 10, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		number, err := jsonNumberText(decoded, value.Type())
+		if err != nil {
+			return err
+		}
+		parsed, err := strconv.ParseFloat(number, value.Type().Bits())
+		if err != nil {
+			return err
+		}
+		value.SetFloat(parsed)
+	case reflect.Slice, reflect.Array:
+		items, ok := decoded.([]interface{})
+		if !ok {
+			return jsonTypeError(decoded, value.Type())
+		}
+		if value.Kind() == reflect.Slice {
+			value.Set(reflect.MakeSlice(value.Type(), len(items), len(items)))
+		} else if len(items) > value.Len() {
+			err := fmt.Errorf("JSON array of length %d exceeds %s", len(items), value.Type())
+			return err
+		}
+		for i, item := range items {
+			if err := assignJSONValue(value.Index(i), item); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		object, ok := decoded.(map[string]interface{})
+		if !ok {
+			return jsonTypeError(decoded, value.Type())
+		}
+		value.Set(reflect.MakeMapWithSize(value.Type(), len(object)))
+		for key, item := range object {
+			mapKey := reflect.New(value.Type().Key()).Elem()
+			// athena formats map keys of any type as JSON object keys
+			if err := assignJSONValue(mapKey, jsonKeyValue(key, mapKey.Kind())); err != nil {
+				return err
+			}
+			mapValue := reflect.New(value.Type().Elem()).Elem()
+			if err := assignJSONValue(mapValue, item); err != nil {
+				return err
+			}
+			value.SetMapIndex(mapKey, mapValue)
+		}
+	case reflect.Struct:
+		return assignJSONRow(value, decoded)
+	default:
+		err := fmt.Errorf("unsupported JSON field type: %s", value.Type())
+		return err
+	}
+	return nil
+}
+
+// assignJSONRow assigns the JSON object, or JSON array in field order, to the exported fields of the struct value
+func assignJSONRow(value reflect.Value, decoded interface{}) error {
+	fields := rowFields(value.Type())
+	switch typed := decoded.(type) {
+	case map[string]interface{}:
+		for _, field := range fields {
+			item, ok := typed[rowFieldName(field)]
+			if !ok {
+				// athena lower cases the field names of ROW types
+				item, ok = typed[strings.ToLower(rowFieldName(field))]
+			}
+			if !ok {
+				continue
+			}
+			if err := assignJSONValue(value.FieldByIndex(field.Index), item); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		if len(typed) != len(fields) {
+			err := fmt.Errorf("mismatched JSON array length %d and %s fields count %d", len(typed), value.Type(), len(fields))
+			return err
+		}
+		for i, field := range fields {
+			if err := assignJSONValue(value.FieldByIndex(field.Index), typed[i]); err != nil {
+				return err
+			}
+		}
+	default:
+		return jsonTypeError(decoded, value.Type())
+	}
+	return nil
+}
+
+// jsonKeyValue returns the JSON object key as JSON value of the map key kind
+func jsonKeyValue(key string, kind reflect.Kind) interface{} {
+	switch kind {
+	case reflect.String:
+		return key
+	case reflect.Bool:
+		return key == "true"
+	}
+	return json.Number(key)
+}
+
+// jsonNumberText returns the text of JSON number, or of JSON string holding a number
+func jsonNumberText(decoded interface{}, targetType reflect.Type) (string, error) {
+	switch typed := decoded.(type) {
+	case json.Number:
+		return typed.String(), nil
+	case string:
+		return typed, nil
+	}
+	return "", jsonTypeError(decoded, targetType)
+}
+
+// parseJSONTimestamp parses timestamps and dates formatted by athena in JSON
+func parseJSONTimestamp(text string) (time.Time, error) {
+	for _, layout := range jsonTimestampLayouts {
+		if parsed, err := time.Parse(layout, text); err == nil {
+			return parsed, nil
+		}
+	}
+	err := fmt.Errorf("invalid JSON timestamp: %s", text)
+	return time.Time{}, err
+}
+
+func jsonTypeError(decoded interface{}, targetType reflect.Type) error {
+	err := fmt.Errorf("cannot assign JSON %T to %s", decoded, targetType)
+	return err
+}
@@ -0,0 +1,170 @@
+package athenaconv
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JSON column", func() {
+	type address struct {
+		City    string `athenaconv:"city"`
+		ZipCode int
+		private string
+	}
+	type complexModel struct {
+		ID        int                 `athenaconv:"id"`
+		Tags      []string            `athenaconv:"tags"`
+		Scores    map[string]float64  `athenaconv:"scores"`
+		Counts    map[int]int64       `athenaconv:"counts"`
+		Address   address             `athenaconv:"address"`
+		Addresses []*address          `athenaconv:"addresses"`
+		Events    []time.Time         `athenaconv:"events"`
+		Nested    map[string][]string `athenaconv:"nested"`
+	}
+
+	Context("isJSONFieldType", func() {
+		It("should return true for complex types", func() {
+			Expect(isJSONFieldType(reflect.TypeOf([]string{}))).To(BeTrue())
+			Expect(isJSONFieldType(reflect.TypeOf([2]int{}))).To(BeTrue())
+			Expect(isJSONFieldType(reflect.TypeOf(map[string]int{}))).To(BeTrue())
+			Expect(isJSONFieldType(reflect.TypeOf(address{}))).To(BeTrue())
+			Expect(isJSONFieldType(reflect.TypeOf(&address{}))).To(BeTrue())
+		})
+
+		It("should return false for scalar types", func() {
+			Expect(isJSONFieldType(reflect.TypeOf(""))).To(BeFalse())
+			Expect(isJSONFieldType(reflect.TypeOf(0))).To(BeFalse())
+			Expect(isJSONFieldType(reflect.TypeOf([]byte{}))).To(BeFalse())
+			Expect(isJSONFieldType(reflect.TypeOf(time.Time{}))).To(BeFalse())
+			Expect(isJSONFieldType(reflect.TypeOf(Date{}))).To(BeFalse())
+		})
+	})
+
+	Context("decodeJSONColumn", func() {
+		It("should decode values containing commas and equal signs exactly", func() {
+			value, err := decodeJSONColumn(util.RefString(`["a, b", "c=d", "[e]"]`), reflect.TypeOf([]string{}))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value.Interface()).To(Equal([]string{"a, b", "c=d", "[e]"}))
+		})
+
+		It("should decode maps with non-string keys", func() {
+			value, err := decodeJSONColumn(util.RefString(`{"1": 10, "2": null}`), reflect.TypeOf(map[int]int64{}))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value.Interface()).To(Equal(map[int]int64{1: 10, 2: 0}))
+		})
+
+		It("should decode rows from JSON objects and JSON arrays", func() {
+			value, err := decodeJSONColumn(util.RefString(`{"city": "Paris, FR", "zipcode": 75001}`), reflect.TypeOf(address{}))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value.Interface()).To(Equal(address{City: "Paris, FR", ZipCode: 75001}))
+
+			value, err = decodeJSONColumn(util.RefString(`["Paris", 75001]`), reflect.TypeOf(address{}))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value.Interface()).To(Equal(address{City: "Paris", ZipCode: 75001}))
+		})
+
+		It("should decode timestamps, dates and decimals formatted by athena", func() {
+			type row struct {
+				At     time.Time
+				Day    Date
+				Amount Decimal
+			}
+			value, err := decodeJSONColumn(util.RefString(`{"at": "2021-12-31 08:11:22.123", "day": "2021-12-31", "amount": 12.30}`), reflect.TypeOf(row{}))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value.Interface()).To(Equal(row{
+				At:     time.Date(2021, 12, 31, 8, 11, 22, 123000000, time.UTC),
+				Day:    Date(time.Date(2021, 12, 31, 0, 0, 0, 0, time.UTC)),
+				Amount: Decimal("12.30"),
+			}))
+		})
+
+		It("should return zero value for NULL", func() {
+			value, err := decodeJSONColumn(nil, reflect.TypeOf([]string{}))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value.IsNil()).To(BeTrue())
+		})
+
+		It("should return error for invalid JSON", func() {
+			_, err := decodeJSONColumn(util.RefString(`[a, b]`), reflect.TypeOf([]string{}))
+			Expect(err).To(MatchError(HavePrefix("invalid JSON column of type []string")))
+		})
+
+		It("should return error for mismatched JSON type", func() {
+			_, err := decodeJSONColumn(util.RefString(`{"a": "b"}`), reflect.TypeOf([]string{}))
+			Expect(err).To(MatchError("cannot decode JSON column into []string: cannot assign JSON map[string]interface {} to []string"))
+		})
+	})
+
+	Context("mapper", func() {
+		It("should decode complex columns selected as JSON", func() {
+			// arrange
+			sql, err := BuildSelect(reflect.TypeOf(complexModel{}), "t", CastComplexToJSON())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sql).To(Equal(`SELECT "id", json_format(cast("tags" as json)) AS "tags", json_format(cast("scores" as json)) AS "scores", ` +
+				`json_format(cast("counts" as json)) AS "counts", json_format(cast("address" as json)) AS "address", ` +
+				`json_format(cast("addresses" as json)) AS "addresses", json_format(cast("events" as json)) AS "events", ` +
+				`json_format(cast("nested" as json)) AS "nested" FROM t`))
+
+			columns := []string{"id", "tags", "scores", "counts", "address", "addresses", "events", "nested"}
+			metadata := &types.ResultSetMetadata{}
+			for i, column := range columns {
+				columnType := "varchar"
+				if i == 0 {
+					columnType = "integer"
+				}
+				metadata.ColumnInfo = append(metadata.ColumnInfo, types.ColumnInfo{Name: util.RefString(column), Type: util.RefString(columnType)})
+			}
+			resultSet := &types.ResultSet{
+				ResultSetMetadata: metadata,
+				Rows: []types.Row{{Data: []types.Datum{
+					{VarCharValue: util.RefString("1")},
+					{VarCharValue: util.RefString(`["x, y", "z"]`)},
+					{VarCharValue: util.RefString(`{"a=b": 1.5}`)},
+					{VarCharValue: util.RefString(`{"7": 3}`)},
+					{VarCharValue: util.RefString(`{"city": "Oslo", "zipcode": 150}`)},
+					{VarCharValue: util.RefString(`[{"city": "Bergen", "zipcode": 5003}, null]`)},
+					{VarCharValue: util.RefString(`["2021-01-02 03:04:05.000"]`)},
+					{},
+				}}},
+			}
+			mapper, err := NewMapperFor(reflect.TypeOf(complexModel{}), WithHeaderRow(HeaderRowAbsent))
+			Expect(err).ToNot(HaveOccurred())
+
+			// act
+			result, err := mapper.FromAthenaResultSetV2(context.Background(), resultSet)
+
+			// assert
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal([]interface{}{&complexModel{
+				ID:        1,
+				Tags:      []string{"x, y", "z"},
+				Scores:    map[string]float64{"a=b": 1.5},
+				Counts:    map[int]int64{7: 3},
+				Address:   address{City: "Oslo", ZipCode: 150},
+				Addresses: []*address{{City: "Bergen", ZipCode: 5003}, nil},
+				Events:    []time.Time{time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC)},
+			}}))
+		})
+
+		It("should keep converting array columns as text", func() {
+			type arrayModel struct {
+				Tags []string `athenaconv:"tags"`
+			}
+			resultSet := &types.ResultSet{
+				ResultSetMetadata: &types.ResultSetMetadata{ColumnInfo: []types.ColumnInfo{{Name: util.RefString("tags"), Type: util.RefString("array")}}},
+				Rows:              []types.Row{{Data: []types.Datum{{VarCharValue: util.RefString("[a, b]")}}}},
+			}
+			mapper, err := NewMapperFor(reflect.TypeOf(arrayModel{}), WithHeaderRow(HeaderRowAbsent))
+			Expect(err).ToNot(HaveOccurred())
+			result, err := mapper.FromAthenaResultSetV2(context.Background(), resultSet)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal([]interface{}{&arrayModel{Tags: []string{"a", "b"}}}))
+		})
+	})
+})
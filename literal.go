@@ -0,0 +1,297 @@
+package athenaconv
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	timestampLiteralLayout = "2006-01-02 15:04:05.000"
+	dateLiteralLayout      = "2006-01-02"
+	// decimalRowFieldType is the type of Decimal fields of ROW literals, as the precision and scale are not known from the Go type
+	decimalRowFieldType = "DECIMAL(38, 18)"
+)
+
+// Date is a calendar date formatted as DATE literal by FormatLiteral, the time of day and location are ignored.
+// time.Time values are formatted as TIMESTAMP literal.
+type Date time.Time
+
+// Decimal is a decimal number formatted as DECIMAL literal by FormatLiteral, e.g. Decimal("12.34")
+type Decimal string
+
+var (
+	decimalPattern = regexp.MustCompile(`^[+-]?([0-9]+(\.[0-9]*)?|\.[0-9]+)$`)
+
+	timeType    = reflect.TypeOf(time.Time{})
+	dateType    = reflect.TypeOf(Date{})
+	decimalType = reflect.TypeOf(Decimal(""))
+	bytesType   = reflect.TypeOf([]byte{})
+)
+
+// FormatLiteral formats the Go value as athena SQL literal, the inverse of the conversion of result set data by the mappers:
+//   - nil and nil pointers: NULL
+//   - string: 'text', single quotes in the text are escaped by doubling them
+//   - bool and integers: true, 42
+//   - float64: 1.5E+00, float32: REAL '1.5E+00', so that they are not parsed as DECIMAL
+//   - Decimal: DECIMAL '12.34'
+//   - time.Time: timestamp '2021-12-31 08:11:22.000' in UTC, Date: date '2021-12-31'
+//   - []byte: X'cafe'
+//   - slices and arrays: ARRAY[...], maps: MAP(ARRAY[...], ARRAY[...]), with casts to the element types if empty
+//   - structs: CAST(ROW(...) AS ROW(...)), fields are named by their athenaconv tag, or their name if not tagged
+func FormatLiteral(value interface{}) (string, error) {
+	if value == nil {
+		return "NULL", nil
+	}
+	return formatValueLiteral(reflect.ValueOf(value))
+}
+
+func formatValueLiteral(value reflect.Value) (string, error) {
+	switch value.Type() {
+	case timeType:
+		return "timestamp '" + value.Interface().(time.Time).UTC().Format(timestampLiteralLayout) + "'", nil
+	case dateType:
+		return "date '" + time.Time(value.Interface().(Date)).Format(dateLiteralLayout) + "'", nil
+	case decimalType:
+		decimal := value.String()
+		if !decimalPattern.MatchString(decimal) {
+			err := fmt.Errorf("invalid decimal: %s", decimal)
+			return "", err
+		}
+		return "DECIMAL '" + decimal + "'", nil
+	case bytesType:
+		return "X'" + hex.EncodeToString(value.Bytes()) + "'", nil
+	}
+
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return "NULL", nil
+		}
+		return formatValueLiteral(value.Elem())
+	case reflect.String:
+		return quoteLiteral(value.String()), nil
+	case reflect.Bool:
+		return strconv.FormatBool(value.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(value.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if value.Uint() > math.MaxInt64 {
+			return "DECIMAL '" + strconv.FormatUint(value.Uint(), 10) + "'", nil
+		}
+		return strconv.FormatUint(value.Uint(), 10), nil
+	case reflect.Float32:
+		return formatRealLiteral(value.Float()), nil
+	case reflect.Float64:
+		return formatDoubleLiteral(value.Float()), nil
+	case reflect.Slice, reflect.Array:
+		return formatArrayLiteral(value)
+	case reflect.Map:
+		return formatMapLiteral(value)
+	case reflect.Struct:
+		return formatRowLiteral(value)
+	}
+	err := fmt.Errorf("unsupported literal type: %s", value.Type())
+	return "", err
+}
+
+// quoteLiteral returns the varchar literal of the string, single quotes are escaped by doubling them
+func quoteLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// quoteIdentifier returns the quoted identifier, double quotes are escaped by doubling them
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// formatDoubleLiteral returns the double literal in exponent notation, decimal notation like 1.5 would be a DECIMAL literal
+func formatDoubleLiteral(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "nan()"
+	case math.IsInf(value, 1):
+		return "infinity()"
+	case math.IsInf(value, -1):
+		return "-infinity()"
+	}
+	return strconv.FormatFloat(value, 'E', -1, 64)
+}
+
+func formatRealLiteral(value float64) string {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return "CAST(" + formatDoubleLiteral(value) + " AS REAL)"
+	}
+	return "REAL '" + strconv.FormatFloat(value, 'E', -1, 32) + "'"
+}
+
+func formatArrayLiteral(value reflect.Value) (string, error) {
+	if value.Len() == 0 {
+		athenaType, err := athenaTypeOf(value.Type())
+		if err != nil {
+			return "", err
+		}
+		return "CAST(ARRAY[] AS " + athenaType + ")", nil
+	}
+
+	elements, err := formatLiterals(value.Len(), value.Index)
+	if err != nil {
+		return "", err
+	}
+	return "ARRAY[" + strings.Join(elements, ", ") + "]", nil
+}
+
+func formatMapLiteral(value reflect.Value) (string, error) {
+	if value.Len() == 0 {
+		athenaType, err := athenaTypeOf(value.Type())
+		if err != nil {
+			return "", err
+		}
+		return "CAST(MAP() AS " + athenaType + ")", nil
+	}
+
+	mapKeys := value.MapKeys()
+	keys, err := formatLiterals(len(mapKeys), func(i int) reflect.Value { return mapKeys[i] })
+	if err != nil {
+		return "", err
+	}
+	values, err := formatLiterals(len(mapKeys), func(i int) reflect.Value { return value.MapIndex(mapKeys[i]) })
+	if err != nil {
+		return "", err
+	}
+
+	// sort entries by key literal so that the literal is deterministic
+	indexes := make([]int, len(keys))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.Slice(indexes, func(a, b int) bool { return keys[indexes[a]] < keys[indexes[b]] })
+	sortedKeys := make([]string, 0, len(keys))
+	sortedValues := make([]string, 0, len(values))
+	for _, i := range indexes {
+		sortedKeys = append(sortedKeys, keys[i])
+		sortedValues = append(sortedValues, values[i])
+	}
+	return "MAP(ARRAY[" + strings.Join(sortedKeys, ", ") + "], ARRAY[" + strings.Join(sortedValues, ", ") + "])", nil
+}
+
+func formatRowLiteral(value reflect.Value) (string, error) {
+	athenaType, err := athenaTypeOf(value.Type())
+	if err != nil {
+		return "", err
+	}
+
+	fields := rowFields(value.Type())
+	elements, err := formatLiterals(len(fields), func(i int) reflect.Value { return value.Field(fields[i].Index[0]) })
+	if err != nil {
+		return "", err
+	}
+	return "CAST(ROW(" + strings.Join(elements, ", ") + ") AS " + athenaType + ")", nil
+}
+
+func formatLiterals(count int, valueAt func(i int) reflect.Value) ([]string, error) {
+	literals := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		literal, err := formatValueLiteral(valueAt(i))
+		if err != nil {
+			return nil, err
+		}
+		literals = append(literals, literal)
+	}
+	return literals, nil
+}
+
+// rowFields returns the exported fields of the struct formatted as ROW literal
+func rowFields(structType reflect.Type) []reflect.StructField {
+	fields := make([]reflect.StructField, 0, structType.NumField())
+	for i := 0; i < structType.NumField(); i++ {
+		if field := structType.Field(i); field.PkgPath == "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// rowFieldName returns the name of the struct field in ROW literal, the athenaconv tag if defined
+func rowFieldName(field reflect.StructField) string {
+	if name := tagColumnName(field); name != "" {
+		return name
+	}
+	return field.Name
+}
+
+// athenaTypeOf returns the athena type of the Go type as used in casts of FormatLiteral
+func athenaTypeOf(goType reflect.Type) (string, error) {
+	switch goType {
+	case timeType:
+		return "TIMESTAMP", nil
+	case dateType:
+		return "DATE", nil
+	case decimalType:
+		return decimalRowFieldType, nil
+	case bytesType:
+		return "VARBINARY", nil
+	}
+
+	switch goType.Kind() {
+	case reflect.Ptr:
+		return athenaTypeOf(goType.Elem())
+	case reflect.String:
+		return "VARCHAR", nil
+	case reflect.Bool:
+		return "BOOLEAN", nil
+	case reflect.Int8:
+		return "TINYINT", nil
+	case reflect.Int16, reflect.Uint8:
+		return "SMALLINT", nil
+	case reflect.Int32, reflect.Uint16:
+		return "INTEGER", nil
+	case reflect.Int, reflect.Int64, reflect.Uint32:
+		return "BIGINT", nil
+	case reflect.Uint, reflect.Uint64:
+		return "DECIMAL(20, 0)", nil
+	case reflect.Float32:
+		return "REAL", nil
+	case reflect.Float64:
+		return "DOUBLE", nil
+	case reflect.Slice, reflect.Array:
+		elemType, err := athenaTypeOf(goType.Elem())
+		if err != nil {
+			return "", err
+		}
+		return "ARRAY(" + elemType + ")", nil
+	case reflect.Map:
+		keyType, err := athenaTypeOf(goType.Key())
+		if err != nil {
+			return "", err
+		}
+		elemType, err := athenaTypeOf(goType.Elem())
+		if err != nil {
+			return "", err
+		}
+		return "MAP(" + keyType + ", " + elemType + ")", nil
+	case reflect.Struct:
+		fields := rowFields(goType)
+		if len(fields) == 0 {
+			err := fmt.Errorf("struct %s has no exported fields", goType)
+			return "", err
+		}
+		fieldTypes := make([]string, 0, len(fields))
+		for _, field := range fields {
+			fieldType, err := athenaTypeOf(field.Type)
+			if err != nil {
+				return "", err
+			}
+			fieldTypes = append(fieldTypes, quoteIdentifier(rowFieldName(field))+" "+fieldType)
+		}
+		return "ROW(" + strings.Join(fieldTypes, ", ") + ")", nil
+	}
+	err := fmt.Errorf("unsupported literal type: %s", goType)
+	return "", err
+}
@@ -0,0 +1,205 @@
+package athenaconv
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"testing/quick"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+// evaluateLiteral returns the athena type and the result set data athena returns for the scalar or varchar array literal
+func evaluateLiteral(literal string) (string, string, error) {
+	switch {
+	case strings.HasPrefix(literal, "'"):
+		value, err := unquoteLiteral(literal)
+		return "varchar", value, err
+	case strings.HasPrefix(literal, "timestamp "):
+		value, err := unquoteLiteral(strings.TrimPrefix(literal, "timestamp "))
+		return "timestamp", value, err
+	case strings.HasPrefix(literal, "date "):
+		value, err := unquoteLiteral(strings.TrimPrefix(literal, "date "))
+		return "date", value, err
+	case literal == "true" || literal == "false":
+		return "boolean", literal, nil
+	case strings.HasPrefix(literal, "ARRAY[") && strings.HasSuffix(literal, "]"):
+		elements := make([]string, 0)
+		for _, element := range strings.Split(strings.TrimSuffix(strings.TrimPrefix(literal, "ARRAY["), "]"), ", ") {
+			value, err := unquoteLiteral(element)
+			if err != nil {
+				return "", "", err
+			}
+			elements = append(elements, value)
+		}
+		return "array", "[" + strings.Join(elements, ", ") + "]", nil
+	case strings.ContainsAny(literal, "E"):
+		return "double", literal, nil
+	}
+	if _, err := strconv.ParseInt(literal, 10, 64); err != nil {
+		return "", "", err
+	}
+	return "bigint", literal, nil
+}
+
+// unquoteLiteral returns the text of the varchar literal, every single quote in the text should be escaped by doubling it
+func unquoteLiteral(literal string) (string, error) {
+	if len(literal) < 2 || literal[0] != '\'' || literal[len(literal)-1] != '\'' {
+		return "", fmt.Errorf("not a quoted literal: %s", literal)
+	}
+	text := literal[1 : len(literal)-1]
+	if strings.Contains(strings.ReplaceAll(text, "''", ""), "'") {
+		return "", fmt.Errorf("unescaped quote in literal: %s", literal)
+	}
+	return strings.ReplaceAll(text, "''", "'"), nil
+}
+
+// roundTrip formats the value as literal, evaluates it and converts the result set data back like the mappers
+func roundTrip(value interface{}) (interface{}, error) {
+	literal, err := FormatLiteral(value)
+	if err != nil {
+		return nil, err
+	}
+	athenaType, data, err := evaluateLiteral(literal)
+	if err != nil {
+		return nil, err
+	}
+	return castAthenaRowData(context.Background(), types.Datum{VarCharValue: util.RefString(data)}, athenaType)
+}
+
+var _ = Describe("Literal", func() {
+	type address struct {
+		City   string `athenaconv:"city"`
+		Zip    *int32
+		hidden bool
+	}
+
+	Context("FormatLiteral", func() {
+		It("should format scalar values", func() {
+			name := "o'neil"
+			var nilName *string
+			day := time.Date(2021, 12, 31, 8, 11, 22, 123000000, time.FixedZone("UTC+1", 3600))
+			literals := []struct {
+				value    interface{}
+				expected string
+			}{
+				{nil, "NULL"},
+				{nilName, "NULL"},
+				{&name, "'o''neil'"},
+				{"", "''"},
+				{true, "true"},
+				{int8(-8), "-8"},
+				{uint64(math.MaxUint64), "DECIMAL '18446744073709551615'"},
+				{1.5, "1.5E+00"},
+				{float32(0.25), "REAL '2.5E-01'"},
+				{math.NaN(), "nan()"},
+				{float32(math.Inf(1)), "CAST(infinity() AS REAL)"},
+				{Decimal("-12.340"), "DECIMAL '-12.340'"},
+				{day, "timestamp '2021-12-31 07:11:22.123'"},
+				{Date(day), "date '2021-12-31'"},
+				{[]byte("hi"), "X'6869'"},
+			}
+			for _, literal := range literals {
+				formatted, err := FormatLiteral(literal.value)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(formatted).To(Equal(literal.expected))
+			}
+		})
+
+		It("should format arrays, maps and rows with casts", func() {
+			zip := int32(12345)
+			literals := []struct {
+				value    interface{}
+				expected string
+			}{
+				{[]string{"a", "b"}, "ARRAY['a', 'b']"},
+				{[2]int64{1, 2}, "ARRAY[1, 2]"},
+				{[]*string{nil}, "ARRAY[NULL]"},
+				{[]string{}, "CAST(ARRAY[] AS ARRAY(VARCHAR))"},
+				{[][]float64(nil), "CAST(ARRAY[] AS ARRAY(ARRAY(DOUBLE)))"},
+				{map[string]int{"b": 2, "a": 1}, "MAP(ARRAY['a', 'b'], ARRAY[1, 2])"},
+				{map[string][]bool{}, "CAST(MAP() AS MAP(VARCHAR, ARRAY(BOOLEAN)))"},
+				{address{City: "x", Zip: &zip}, `CAST(ROW('x', 12345) AS ROW("city" VARCHAR, "Zip" INTEGER))`},
+				{[]address{{City: "y"}}, `ARRAY[CAST(ROW('y', NULL) AS ROW("city" VARCHAR, "Zip" INTEGER))]`},
+				{[]interface{}{1, "a", nil}, "ARRAY[1, 'a', NULL]"},
+			}
+			for _, literal := range literals {
+				formatted, err := FormatLiteral(literal.value)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(formatted).To(Equal(literal.expected))
+			}
+		})
+
+		It("should return error for unsupported values", func() {
+			_, err := FormatLiteral(make(chan int))
+			Expect(err).To(MatchError("unsupported literal type: chan int"))
+			_, err = FormatLiteral(Decimal("1e5"))
+			Expect(err).To(MatchError("invalid decimal: 1e5"))
+			_, err = FormatLiteral([]interface{}{})
+			Expect(err).To(MatchError("unsupported literal type: interface {}"))
+			_, err = FormatLiteral(struct{ hidden int }{})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("round trip with result set conversion", func() {
+		It("should hold for strings", func() {
+			Expect(quick.Check(func(value string) bool {
+				result, err := roundTrip(value)
+				return err == nil && result == value
+			}, nil)).To(Succeed())
+			Expect(roundTrip("it's '' ''' quoted")).To(Equal("it's '' ''' quoted"))
+		})
+
+		It("should hold for integers and booleans", func() {
+			Expect(quick.Check(func(value int64, flag bool) bool {
+				result, err := roundTrip(value)
+				if err != nil || result != value {
+					return false
+				}
+				result, err = roundTrip(flag)
+				return err == nil && result == flag
+			}, nil)).To(Succeed())
+		})
+
+		It("should hold for doubles", func() {
+			Expect(quick.Check(func(value float64) bool {
+				result, err := roundTrip(value)
+				return err == nil && result == value
+			}, nil)).To(Succeed())
+		})
+
+		It("should hold for timestamps and dates", func() {
+			Expect(quick.Check(func(seconds uint32, millis uint16) bool {
+				timestamp := time.Unix(int64(seconds), int64(millis%1000)*int64(time.Millisecond))
+				result, err := roundTrip(timestamp)
+				if err != nil || !result.(time.Time).Equal(timestamp) {
+					return false
+				}
+				day := time.Date(timestamp.Year(), timestamp.Month(), timestamp.Day(), 0, 0, 0, 0, time.UTC)
+				result, err = roundTrip(Date(day))
+				return err == nil && result.(time.Time).Equal(day)
+			}, nil)).To(Succeed())
+		})
+
+		It("should hold for arrays of words", func() {
+			Expect(quick.Check(func(numbers []uint32) bool {
+				words := make([]string, 0, len(numbers))
+				for _, number := range numbers {
+					words = append(words, "w'"+strconv.FormatUint(uint64(number), 36))
+				}
+				if len(words) == 0 {
+					return true
+				}
+				result, err := roundTrip(words)
+				return err == nil && strings.Join(result.([]string), "|") == strings.Join(words, "|")
+			}, nil)).To(Succeed())
+		})
+	})
+})
@@ -2,14 +2,19 @@ package athenaconv
 
 import (
 	"context"
+	"fmt"
 	"reflect"
 
 	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv/util"
 )
 
 type dataMapper struct {
 	modelType             reflect.Type
 	modelDefinitionSchema modelDefinitionMap
+	options               mapperOptions
+	// rowMapper is the generated row mapper of the model type, nil to convert rows with reflection
+	rowMapper RowMapperFunc
 }
 
 // DataMapper provides abstraction to convert athena ResultSet object to arbitrary user-defined struct
@@ -19,12 +24,15 @@ type DataMapper interface {
 
 // NewMapperFor creates new DataMapper for given reflect.Type
 // reflect.Type should be of struct value type, not pointer to struct.
+// Rows are converted by the generated row mapper of the type if registered, see RegisterRowMapper, otherwise with reflection.
 //
 // Example:
 //
 // mapper, err := athenaconv.NewMapperFor(reflect.TypeOf(MyStruct{}))
-func NewMapperFor(modelType reflect.Type) (DataMapper, error) {
-	modelDefinitionSchema, err := newModelDefinitionMap(modelType)
+// mapper, err := athenaconv.NewMapperFor(reflect.TypeOf(MyStruct{}), athenaconv.WithNameMatcher(athenaconv.SnakeCaseNameMatcher))
+func NewMapperFor(modelType reflect.Type, opts ...MapperOption) (DataMapper, error) {
+	options := newMapperOptions(opts)
+	modelDefinitionSchema, err := newModelDefinitionMap(modelType, options.nameMatcher)
 	if err != nil {
 		return nil, err
 	}
@@ -32,18 +40,19 @@ func NewMapperFor(modelType reflect.Type) (DataMapper, error) {
 	mapper := &dataMapper{
 		modelType:             modelType,
 		modelDefinitionSchema: modelDefinitionSchema,
+		options:               options,
+	}
+	if !options.reflection {
+		mapper.rowMapper = registeredRowMapper(modelType)
 	}
 	return mapper, nil
 }
 
 // FromAthenaResultSetV2 converts ResultSet from aws-sdk-go-v2/service/athena/types into strongly-typed array[mapper.modelType]
-// Returns conversion error if header values are passed, i.e. first row of your athena ResultSet in page 1.
+// The header row, i.e. first row of your athena ResultSet in page 1, is detected and skipped, see WithHeaderRow.
 // Returns error if the athena ResultSetMetadata does not match the mapper definition.
 //
 // Example:
-// if page == 1 && len(queryResultOutput.ResultSet.Rows) > 0 {
-// 		queryResultOutput.ResultSet.Rows = queryResultOutput.ResultSet.Rows[1:]		// skip header row
-// }
 // mapped, err := mapper.FromAthenaResultSetV2(ctx, queryResultOutput.ResultSet)
 func (m *dataMapper) FromAthenaResultSetV2(ctx context.Context, resultSet *types.ResultSet) ([]interface{}, error) {
 	resultSetSchema, err := newResultSetDefinitionMap(ctx, resultSet.ResultSetMetadata)
@@ -51,24 +60,35 @@ func (m *dataMapper) FromAthenaResultSetV2(ctx context.Context, resultSet *types
 		return nil, err
 	}
 
-	err = validateResultSetSchema(ctx, resultSetSchema, m.modelDefinitionSchema)
+	resultSetSchema, err = resultSetSchema.normalize(m.options.nameMatcher)
+	if err != nil {
+		return nil, err
+	}
+
+	bindings, err := bindResultSetSchema(ctx, resultSetSchema, m.modelDefinitionSchema)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := validateBindings(m.modelType, bindings); err != nil {
+		return nil, err
+	}
+
+	rows := skipHeaderRow(resultSet.Rows, resultSet.ResultSetMetadata, m.options.headerRow)
+	if m.rowMapper != nil {
+		return m.mapRows(rows, newColumnIndex(bindings, m.modelType.NumField()))
+	}
+
 	result := make([]interface{}, 0)
-	for _, row := range resultSet.Rows {
+	for _, row := range rows {
 		model := reflect.New(m.modelType)
-		for athenaColName, modelDefColInfo := range m.modelDefinitionSchema {
-			mappedColumnInfo := resultSetSchema[athenaColName]
-			fieldName := modelDefColInfo.fieldName
-
-			// log.Printf("SET model.%s = row.Data[%d] with athena col name = '%s'", fieldName, mappedColumnInfo.index, athenaColName)
-			colData, err := castAthenaRowData(ctx, row.Data[mappedColumnInfo.index], mappedColumnInfo.athenaColumnType)
-			if err != nil {
+		for _, binding := range bindings {
+			mappedColumnInfo := binding.colInfo
+			field := model.Elem().Field(binding.fieldIndex)
+			// log.Printf("SET model.%s = row.Data[%d] with athena col name = '%s'", binding.fieldName, mappedColumnInfo.index, mappedColumnInfo.name)
+			if err := setFieldValue(ctx, field, row.Data[mappedColumnInfo.index], mappedColumnInfo.athenaColumnType); err != nil {
 				return nil, err
 			}
-			model.Elem().FieldByName(fieldName).Set(reflect.ValueOf(colData))
 		}
 
 		result = append(result, model.Interface())
@@ -76,3 +96,76 @@ func (m *dataMapper) FromAthenaResultSetV2(ctx context.Context, resultSet *types
 
 	return result, nil
 }
+
+// mapRows converts the rows with the generated row mapper
+func (m *dataMapper) mapRows(rows []types.Row, idx ColumnIndex) ([]interface{}, error) {
+	result := make([]interface{}, 0, len(rows))
+	for _, row := range rows {
+		model, err := m.rowMapper(row, idx)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, model)
+	}
+	return result, nil
+}
+
+// validateBindings returns error if a field cannot be set from the athena type of its column, see canSetColumnValue
+func validateBindings(modelType reflect.Type, bindings []resultSetBinding) error {
+	for _, binding := range bindings {
+		fieldType := modelType.Field(binding.fieldIndex).Type
+		athenaType := binding.colInfo.athenaColumnType
+		if canSetColumnValue(fieldType, athenaType) {
+			continue
+		}
+		hint := ""
+		if isJSONFieldType(fieldType) {
+			hint = ", complex columns should be selected as JSON, see CastComplexToJSON"
+		}
+		err := fmt.Errorf("cannot map column '%s' of athena type %s to field %s of type %s%s", binding.colInfo.name, athenaType, binding.fieldName, fieldType, hint)
+		return err
+	}
+	return nil
+}
+
+// setFieldValue converts the athena column value to the type of the model field and sets it
+func setFieldValue(ctx context.Context, field reflect.Value, datum types.Datum, athenaType string) error {
+	if isJSONFieldType(field.Type()) && isJSONColumnType(athenaType) {
+		// complex column selected as JSON text, see CastComplexToJSON
+		colValue, err := decodeJSONColumn(datum.VarCharValue, field.Type())
+		if err != nil {
+			return err
+		}
+		field.Set(colValue)
+		return nil
+	}
+
+	target := field
+	if field.Kind() == reflect.Ptr {
+		// nullable column, NULL is converted to nil pointer
+		if datum.VarCharValue == nil {
+			field.Set(reflect.Zero(field.Type()))
+			return nil
+		}
+		field.Set(reflect.New(field.Type().Elem()))
+		target = field.Elem()
+	}
+
+	if target.Kind() == reflect.String {
+		// string fields keep the column text for every athena type, e.g. double columns, which were mapped to string
+		// before castAthenaRowData converted them to float64
+		target.Set(reflect.ValueOf(util.SafeString(datum.VarCharValue)).Convert(target.Type()))
+		return nil
+	}
+
+	if !castAthenaType(athenaType).AssignableTo(target.Type()) {
+		err := fmt.Errorf("cannot convert athena type %s to %s", athenaType, target.Type())
+		return err
+	}
+	colData, err := castAthenaRowData(ctx, datum, athenaType)
+	if err != nil {
+		return err
+	}
+	target.Set(reflect.ValueOf(colData))
+	return nil
+}
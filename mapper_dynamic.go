@@ -0,0 +1,176 @@
+package athenaconv
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+)
+
+// ColumnMetadata describes a single column of an athena result set
+type ColumnMetadata struct {
+	Index     int
+	Name      string
+	Type      string
+	Nullable  types.ColumnNullable
+	Precision int32
+	Scale     int32
+}
+
+// Record is a single result set row converted without a predeclared struct.
+// Values are ordered as returned by athena and typed using the same conversions as the struct mapper, NULL values are nil.
+type Record struct {
+	Columns []ColumnMetadata
+	Values  []interface{}
+}
+
+// Len returns the number of columns in the record
+func (r *Record) Len() int {
+	return len(r.Values)
+}
+
+// Get returns the value of the column bound by the given key: "name", "name#occurrence" or "#position"
+func (r *Record) Get(columnKey string) (interface{}, bool) {
+	index, ok := LookupColumn(r.Columns, columnKey)
+	if !ok {
+		return nil, false
+	}
+	return r.Values[index], true
+}
+
+// Map returns the record as a map of athena column name to value, duplicate column names are keyed by their occurrence, e.g. id#2
+func (r *Record) Map() map[string]interface{} {
+	result := make(map[string]interface{}, len(r.Values))
+	for i, key := range ColumnKeys(r.Columns) {
+		result[key] = r.Values[i]
+	}
+	return result
+}
+
+type dynamicMapper struct {
+	options mapperOptions
+}
+
+// DynamicMapper provides abstraction to convert athena ResultSet object without a predeclared struct, e.g. for ad hoc queries
+type DynamicMapper interface {
+	RecordsFromAthenaResultSetV2(ctx context.Context, input *types.ResultSet) ([]*Record, error)
+	MapsFromAthenaResultSetV2(ctx context.Context, input *types.ResultSet) ([]map[string]interface{}, error)
+}
+
+// NewDynamicMapper creates new DynamicMapper that converts rows to Record or map[string]interface{}
+//
+// Example:
+//
+// mapper := athenaconv.NewDynamicMapper()
+func NewDynamicMapper(opts ...MapperOption) DynamicMapper {
+	return &dynamicMapper{
+		options: newMapperOptions(opts),
+	}
+}
+
+// RecordsFromAthenaResultSetV2 converts ResultSet from aws-sdk-go-v2/service/athena/types into ordered records with typed values.
+// The header row, i.e. first row of your athena ResultSet in page 1, is detected and skipped, see WithHeaderRow.
+func (m *dynamicMapper) RecordsFromAthenaResultSetV2(ctx context.Context, resultSet *types.ResultSet) ([]*Record, error) {
+	columns, err := ResultSetColumns(ctx, resultSet.ResultSetMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := skipHeaderRow(resultSet.Rows, resultSet.ResultSetMetadata, m.options.headerRow)
+	result := make([]*Record, 0, len(rows))
+	for rowIndex, row := range rows {
+		if len(row.Data) != len(columns) {
+			err := fmt.Errorf("mismatched row data and result set columns count, row: %d, rowDataLength: %d, columnsLength: %d", rowIndex, len(row.Data), len(columns))
+			return nil, err
+		}
+
+		record := &Record{
+			Columns: columns,
+			Values:  make([]interface{}, len(columns)),
+		}
+		for i, column := range columns {
+			if row.Data[i].VarCharValue == nil {
+				continue
+			}
+			record.Values[i], err = castAthenaRowData(ctx, row.Data[i], column.Type)
+			if err != nil {
+				return nil, err
+			}
+		}
+		result = append(result, record)
+	}
+
+	return result, nil
+}
+
+// MapsFromAthenaResultSetV2 converts ResultSet from aws-sdk-go-v2/service/athena/types into maps of athena column name to typed value.
+// The header row, i.e. first row of your athena ResultSet in page 1, is detected and skipped, see WithHeaderRow.
+func (m *dynamicMapper) MapsFromAthenaResultSetV2(ctx context.Context, resultSet *types.ResultSet) ([]map[string]interface{}, error) {
+	records, err := m.RecordsFromAthenaResultSetV2(ctx, resultSet)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]map[string]interface{}, 0, len(records))
+	for _, record := range records {
+		result = append(result, record.Map())
+	}
+	return result, nil
+}
+
+// ResultSetColumns reads the ordered column metadata from result set metadata, as set to Record.Columns
+func ResultSetColumns(ctx context.Context, resultSetMetadata *types.ResultSetMetadata) ([]ColumnMetadata, error) {
+	resultSetSchema, err := newResultSetDefinitionMap(ctx, resultSetMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]ColumnMetadata, 0, len(resultSetSchema))
+	for _, colInfo := range resultSetSchema {
+		columnInfo := resultSetMetadata.ColumnInfo[colInfo.index]
+		columns = append(columns, ColumnMetadata{
+			Index:     colInfo.index,
+			Name:      colInfo.name,
+			Type:      colInfo.athenaColumnType,
+			Nullable:  columnInfo.Nullable,
+			Precision: columnInfo.Precision,
+			Scale:     columnInfo.Scale,
+		})
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		return columns[i].Index < columns[j].Index
+	})
+	return columns, nil
+}
+
+// ColumnKeys returns unique keys of the ordered columns, duplicate column names are keyed by their occurrence, e.g. id#2, as in Record.Map
+func ColumnKeys(columns []ColumnMetadata) []string {
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = column.Name
+	}
+	return newColumnKeys(names)
+}
+
+// LookupColumn returns the index of the column bound by the given key: "name", "name#occurrence" or "#position", as in Record.Get
+func LookupColumn(columns []ColumnMetadata, key string) (int, bool) {
+	parsedKey, err := parseColumnKey(key)
+	if err != nil {
+		return 0, false
+	}
+	if parsedKey.isPositional() {
+		return parsedKey.position, parsedKey.position < len(columns)
+	}
+
+	occurrence := 0
+	for i, column := range columns {
+		if column.Name == parsedKey.name {
+			occurrence++
+			if occurrence == parsedKey.occurrence {
+				return i, true
+			}
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,209 @@
+package athenaconv
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Dynamic mapper", func() {
+	var ctx context.Context
+	var mapper DynamicMapper
+	var metadata types.ResultSetMetadata
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		mapper = NewDynamicMapper()
+
+		metadata = types.ResultSetMetadata{
+			ColumnInfo: make([]types.ColumnInfo, 0),
+		}
+		metadata.ColumnInfo = append(metadata.ColumnInfo, types.ColumnInfo{
+			Name:     util.RefString("my_id_col"),
+			Type:     util.RefString("integer"),
+			Nullable: types.ColumnNullableNotNull,
+		})
+		metadata.ColumnInfo = append(metadata.ColumnInfo, types.ColumnInfo{
+			Name:     util.RefString("name_col"),
+			Type:     util.RefString("varchar"),
+			Nullable: types.ColumnNullableNullable,
+		})
+		metadata.ColumnInfo = append(metadata.ColumnInfo, types.ColumnInfo{
+			Name: util.RefString("date_col"),
+			Type: util.RefString("date"),
+		})
+	})
+
+	Context("RecordsFromAthenaResultSetV2", func() {
+		When("result set is valid", func() {
+			It("should return ordered records with typed values", func() {
+				resultSet := types.ResultSet{
+					ResultSetMetadata: &metadata,
+					Rows:              make([]types.Row, 0),
+				}
+				for i := 0; i < 10; i++ {
+					resultSet.Rows = append(resultSet.Rows, types.Row{
+						Data: []types.Datum{
+							{VarCharValue: util.RefString(strconv.Itoa(i))},
+							{VarCharValue: util.RefString("name " + strconv.Itoa(i))},
+							{VarCharValue: util.RefString("2021-12-31")},
+						},
+					})
+				}
+
+				records, err := mapper.RecordsFromAthenaResultSetV2(ctx, &resultSet)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(records)).To(Equal(10))
+				for index, record := range records {
+					Expect(record.Len()).To(Equal(3))
+					Expect(record.Columns[0].Name).To(Equal("my_id_col"))
+					Expect(record.Columns[0].Type).To(Equal("integer"))
+					Expect(record.Columns[0].Nullable).To(Equal(types.ColumnNullableNotNull))
+					Expect(record.Columns[1].Name).To(Equal("name_col"))
+					Expect(record.Columns[2].Index).To(Equal(2))
+					Expect(record.Values[0]).To(Equal(index))
+					Expect(record.Values[1]).To(Equal("name " + strconv.Itoa(index)))
+					Expect(record.Values[2]).To(Equal(time.Date(2021, 12, 31, 0, 0, 0, 0, time.UTC)))
+
+					value, ok := record.Get("name_col")
+					Expect(ok).To(BeTrue())
+					Expect(value).To(Equal("name " + strconv.Itoa(index)))
+					_, ok = record.Get("unknown_col")
+					Expect(ok).To(BeFalse())
+				}
+			})
+
+			It("should return nil value for null data", func() {
+				resultSet := types.ResultSet{
+					ResultSetMetadata: &metadata,
+					Rows: []types.Row{
+						{Data: []types.Datum{{VarCharValue: util.RefString("1")}, {}, {}}},
+					},
+				}
+
+				records, err := mapper.RecordsFromAthenaResultSetV2(ctx, &resultSet)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(records)).To(Equal(1))
+				Expect(records[0].Values[0]).To(Equal(1))
+				Expect(records[0].Values[1]).To(BeNil())
+				Expect(records[0].Values[2]).To(BeNil())
+			})
+		})
+
+		When("row data does not match the result set metadata", func() {
+			It("should return error", func() {
+				resultSet := types.ResultSet{
+					ResultSetMetadata: &metadata,
+					Rows: []types.Row{
+						{Data: []types.Datum{{VarCharValue: util.RefString("1")}}},
+					},
+				}
+
+				_, err := mapper.RecordsFromAthenaResultSetV2(ctx, &resultSet)
+				Expect(err).To(HaveOccurred())
+				Expect(strings.ToLower(err.Error())).To(ContainSubstring("mismatched row data"))
+			})
+		})
+
+		When("row data cannot be casted", func() {
+			It("should return error", func() {
+				resultSet := types.ResultSet{
+					ResultSetMetadata: &metadata,
+					Rows: []types.Row{
+						{Data: []types.Datum{{VarCharValue: util.RefString("my_id_col")}, {}, {}}},
+					},
+				}
+
+				_, err := mapper.RecordsFromAthenaResultSetV2(ctx, &resultSet)
+				Expect(err).To(HaveOccurred())
+				Expect(strings.ToLower(err.Error())).To(MatchRegexp("parsing .* invalid syntax"))
+			})
+		})
+
+		When("result set definition contains invalid metadata", func() {
+			It("should return error", func() {
+				metadata.ColumnInfo[1].Type = nil
+				resultSet := types.ResultSet{
+					ResultSetMetadata: &metadata,
+					Rows:              make([]types.Row, 0),
+				}
+
+				_, err := mapper.RecordsFromAthenaResultSetV2(ctx, &resultSet)
+				Expect(err).To(HaveOccurred())
+				Expect(strings.ToLower(err.Error())).To(ContainSubstring("column type from result set is empty"))
+			})
+		})
+	})
+
+	Context("Record", func() {
+		It("should get and map duplicate columns by occurrence and position", func() {
+			record := &Record{
+				Columns: []ColumnMetadata{
+					{Index: 0, Name: "id", Type: "integer"},
+					{Index: 1, Name: "id", Type: "integer"},
+				},
+				Values: []interface{}{1, 2},
+			}
+
+			value, ok := record.Get("id")
+			Expect(ok).To(BeTrue())
+			Expect(value).To(Equal(1))
+			value, ok = record.Get("id#2")
+			Expect(ok).To(BeTrue())
+			Expect(value).To(Equal(2))
+			value, ok = record.Get("#1")
+			Expect(ok).To(BeTrue())
+			Expect(value).To(Equal(2))
+			_, ok = record.Get("#2")
+			Expect(ok).To(BeFalse())
+			Expect(record.Map()).To(Equal(map[string]interface{}{"id": 1, "id#2": 2}))
+		})
+	})
+
+	Context("ResultSetColumns", func() {
+		It("should return the ordered columns with their keys and lookup", func() {
+			metadata.ColumnInfo[2].Name = util.RefString("my_id_col")
+			columns, err := ResultSetColumns(ctx, &metadata)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(columns).To(Equal([]ColumnMetadata{
+				{Index: 0, Name: "my_id_col", Type: "integer", Nullable: types.ColumnNullableNotNull},
+				{Index: 1, Name: "name_col", Type: "varchar", Nullable: types.ColumnNullableNullable},
+				{Index: 2, Name: "my_id_col", Type: "date"},
+			}))
+			Expect(ColumnKeys(columns)).To(Equal([]string{"my_id_col", "name_col", "my_id_col#2"}))
+
+			for key, expected := range map[string]int{"my_id_col": 0, "my_id_col#1": 0, "my_id_col#2": 2, "#1": 1} {
+				index, ok := LookupColumn(columns, key)
+				Expect(ok).To(BeTrue(), key)
+				Expect(index).To(Equal(expected), key)
+			}
+			for _, key := range []string{"missing", "my_id_col#3", "#3", "#"} {
+				_, ok := LookupColumn(columns, key)
+				Expect(ok).To(BeFalse(), key)
+			}
+		})
+	})
+
+	Context("MapsFromAthenaResultSetV2", func() {
+		It("should return maps keyed by athena column name", func() {
+			resultSet := types.ResultSet{
+				ResultSetMetadata: &metadata,
+				Rows: []types.Row{
+					{Data: []types.Datum{{VarCharValue: util.RefString("7")}, {VarCharValue: util.RefString("seven")}, {}}},
+				},
+			}
+
+			maps, err := mapper.MapsFromAthenaResultSetV2(ctx, &resultSet)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(maps).To(Equal([]map[string]interface{}{
+				{"my_id_col": 7, "name_col": "seven", "date_col": nil},
+			}))
+		})
+	})
+})
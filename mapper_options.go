@@ -0,0 +1,42 @@
+package athenaconv
+
+// MapperOption configures the behaviour of DataMapper and DynamicMapper
+type MapperOption func(*mapperOptions)
+
+type mapperOptions struct {
+	headerRow   HeaderRow
+	nameMatcher NameMatcher
+	reflection  bool
+}
+
+func newMapperOptions(opts []MapperOption) mapperOptions {
+	options := mapperOptions{
+		headerRow:   HeaderRowAuto,
+		nameMatcher: ExactNameMatcher,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// WithHeaderRow sets how the header row of the ResultSet is detected and skipped, defaults to HeaderRowAuto
+func WithHeaderRow(headerRow HeaderRow) MapperOption {
+	return func(options *mapperOptions) {
+		options.headerRow = headerRow
+	}
+}
+
+// WithNameMatcher sets how struct fields are matched to athena column names, defaults to ExactNameMatcher
+func WithNameMatcher(nameMatcher NameMatcher) MapperOption {
+	return func(options *mapperOptions) {
+		options.nameMatcher = nameMatcher
+	}
+}
+
+// WithReflection makes NewMapperFor convert rows with reflection even if a generated row mapper is registered, see RegisterRowMapper
+func WithReflection() MapperOption {
+	return func(options *mapperOptions) {
+		options.reflection = true
+	}
+}
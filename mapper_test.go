@@ -44,6 +44,17 @@ var _ = Describe("Mapper", func() {
 			})
 		})
 
+		When("model type/definition has invalid column key", func() {
+			It("should return error", func() {
+				type invalidKeyModel struct {
+					ID int `athenaconv:"id#first"`
+				}
+				_, err := NewMapperFor(reflect.TypeOf(invalidKeyModel{}))
+				Expect(err).To(HaveOccurred())
+				Expect(strings.ToLower(err.Error())).To(ContainSubstring("invalid column key"))
+			})
+		})
+
 		When("model type/definition is a pointer instead of struct value", func() {
 			It("should return error", func() {
 				_, err := NewMapperFor(reflect.TypeOf(&validModel{}))
@@ -138,6 +149,177 @@ var _ = Describe("Mapper", func() {
 			})
 		})
 
+		When("result set contains header row", func() {
+			var resultSet types.ResultSet
+
+			BeforeEach(func() {
+				resultSet = types.ResultSet{
+					ResultSetMetadata: &metadata,
+					Rows: []types.Row{
+						{Data: []types.Datum{{VarCharValue: util.RefString("my_id_col")}, {VarCharValue: util.RefString("name_col")}}},
+						{Data: []types.Datum{{VarCharValue: util.RefString("1")}, {VarCharValue: util.RefString("name 1")}}},
+					},
+				}
+			})
+
+			It("should detect and skip header row by default", func() {
+				mapped, err := mapper.FromAthenaResultSetV2(ctx, &resultSet)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(mapped)).To(Equal(1))
+				Expect(mapped[0].(*validModel).ID).To(Equal(1))
+			})
+
+			It("should return conversion error if header row detection is disabled", func() {
+				mapper, err = NewMapperFor(reflect.TypeOf(validModel{}), WithHeaderRow(HeaderRowAbsent))
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err := mapper.FromAthenaResultSetV2(ctx, &resultSet)
+				Expect(err).To(HaveOccurred())
+				Expect(strings.ToLower(err.Error())).To(MatchRegexp("parsing .* invalid syntax"))
+			})
+
+			It("should always skip first row if header row is present", func() {
+				mapper, err = NewMapperFor(reflect.TypeOf(validModel{}), WithHeaderRow(HeaderRowPresent))
+				Expect(err).ToNot(HaveOccurred())
+
+				mapped, err := mapper.FromAthenaResultSetV2(ctx, &types.ResultSet{
+					ResultSetMetadata: &metadata,
+					Rows:              resultSet.Rows[1:],
+				})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(mapped)).To(Equal(0))
+			})
+		})
+
+		When("result set contains duplicate and unnamed columns", func() {
+			It("should map values by occurrence and position", func() {
+				type joinedModel struct {
+					AID   int    `athenaconv:"id"`
+					BID   string `athenaconv:"id#2"`
+					Count int64  `athenaconv:"#2"`
+				}
+				mapper, err := NewMapperFor(reflect.TypeOf(joinedModel{}))
+				Expect(err).ToNot(HaveOccurred())
+
+				resultSet := types.ResultSet{
+					ResultSetMetadata: &types.ResultSetMetadata{
+						ColumnInfo: []types.ColumnInfo{
+							{Name: util.RefString("id"), Type: util.RefString("integer")},
+							{Name: util.RefString("id"), Type: util.RefString("varchar")},
+							{Name: util.RefString("_col2"), Type: util.RefString("bigint")},
+						},
+					},
+					Rows: []types.Row{
+						{Data: []types.Datum{{VarCharValue: util.RefString("1")}, {VarCharValue: util.RefString("b-1")}, {VarCharValue: util.RefString("10")}}},
+					},
+				}
+
+				mapped, err := mapper.FromAthenaResultSetV2(ctx, &resultSet)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(mapped).To(Equal([]interface{}{&joinedModel{AID: 1, BID: "b-1", Count: 10}}))
+			})
+		})
+
+		When("model has pointer fields for nullable columns", func() {
+			It("should map NULL to nil and values to pointers", func() {
+				type nullableModel struct {
+					ID    int      `athenaconv:"id"`
+					Score *float64 `athenaconv:"score"`
+					Count *int64   `athenaconv:"count"`
+				}
+				mapper, err := NewMapperFor(reflect.TypeOf(nullableModel{}), WithHeaderRow(HeaderRowAbsent))
+				Expect(err).ToNot(HaveOccurred())
+
+				resultSet := types.ResultSet{
+					ResultSetMetadata: &types.ResultSetMetadata{
+						ColumnInfo: []types.ColumnInfo{
+							{Name: util.RefString("id"), Type: util.RefString("integer")},
+							{Name: util.RefString("score"), Type: util.RefString("double")},
+							{Name: util.RefString("count"), Type: util.RefString("bigint")},
+						},
+					},
+					Rows: []types.Row{
+						{Data: []types.Datum{{VarCharValue: util.RefString("1")}, {VarCharValue: util.RefString("1.5")}, {}}},
+					},
+				}
+
+				mapped, err := mapper.FromAthenaResultSetV2(ctx, &resultSet)
+				Expect(err).ToNot(HaveOccurred())
+				score := 1.5
+				Expect(mapped).To(Equal([]interface{}{&nullableModel{ID: 1, Score: &score}}))
+			})
+		})
+
+		When("model has string fields for double columns", func() {
+			It("should map the text value of the columns", func() {
+				type textModel struct {
+					Score    string  `athenaconv:"score"`
+					Rate     *string `athenaconv:"rate"`
+					Previous string  `athenaconv:"previous"`
+				}
+				mapper, err := NewMapperFor(reflect.TypeOf(textModel{}), WithHeaderRow(HeaderRowAbsent))
+				Expect(err).ToNot(HaveOccurred())
+
+				resultSet := types.ResultSet{
+					ResultSetMetadata: &types.ResultSetMetadata{
+						ColumnInfo: []types.ColumnInfo{
+							{Name: util.RefString("score"), Type: util.RefString("double")},
+							{Name: util.RefString("rate"), Type: util.RefString("real")},
+							{Name: util.RefString("previous"), Type: util.RefString("double")},
+						},
+					},
+					Rows: []types.Row{
+						{Data: []types.Datum{{VarCharValue: util.RefString("1.50")}, {VarCharValue: util.RefString("NaN")}, {}}},
+					},
+				}
+
+				mapped, err := mapper.FromAthenaResultSetV2(ctx, &resultSet)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(mapped).To(Equal([]interface{}{&textModel{Score: "1.50", Rate: util.RefString("NaN")}}))
+			})
+		})
+
+		When("model field type cannot be set from the column type", func() {
+			newResultSet := func(name string, athenaType string, value string) *types.ResultSet {
+				return &types.ResultSet{
+					ResultSetMetadata: &types.ResultSetMetadata{
+						ColumnInfo: []types.ColumnInfo{{Name: util.RefString(name), Type: util.RefString(athenaType)}},
+					},
+					Rows: []types.Row{{Data: []types.Datum{{VarCharValue: util.RefString(value)}}}},
+				}
+			}
+
+			It("should return error naming the field, column and athena type of complex columns not selected as JSON", func() {
+				type location struct {
+					City string `json:"city"`
+				}
+				type complexModel struct {
+					Location location `athenaconv:"location"`
+				}
+				mapper, err := NewMapperFor(reflect.TypeOf(complexModel{}), WithHeaderRow(HeaderRowAbsent))
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = mapper.FromAthenaResultSetV2(ctx, newResultSet("location", "row", "{city=Paris}"))
+				Expect(err).To(MatchError("cannot map column 'location' of athena type row to field Location of type athenaconv.location, " +
+					"complex columns should be selected as JSON, see CastComplexToJSON"))
+
+				mapped, err := mapper.FromAthenaResultSetV2(ctx, newResultSet("location", "json", `{"city":"Paris"}`))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(mapped).To(Equal([]interface{}{&complexModel{Location: location{City: "Paris"}}}))
+			})
+
+			It("should return error for scalar types", func() {
+				type intModel struct {
+					ID *int `athenaconv:"id"`
+				}
+				mapper, err := NewMapperFor(reflect.TypeOf(intModel{}), WithHeaderRow(HeaderRowAbsent))
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = mapper.FromAthenaResultSetV2(ctx, newResultSet("id", "bigint", "1"))
+				Expect(err).To(MatchError("cannot map column 'id' of athena type bigint to field ID of type *int"))
+			})
+		})
+
 		When("result set definition contains invalid metadata", func() {
 			It("should return error", func() {
 				// arrange
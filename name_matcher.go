@@ -0,0 +1,93 @@
+package athenaconv
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// NameMatcher matches struct fields of the model to athena result set columns
+type NameMatcher interface {
+	// ColumnName returns the athena column name of a struct field without athenaconv tag, empty string if the tag is required
+	ColumnName(field reflect.StructField) string
+	// Normalize returns the key used to compare the column names of the model and the result set
+	Normalize(columnName string) string
+}
+
+var (
+	// ExactNameMatcher requires athenaconv tags on every field and matches column names exactly, this is the default
+	ExactNameMatcher NameMatcher = exactNameMatcher{}
+	// CaseInsensitiveNameMatcher requires athenaconv tags on every field and matches column names ignoring case
+	CaseInsensitiveNameMatcher NameMatcher = caseInsensitiveNameMatcher{}
+	// SnakeCaseNameMatcher derives snake_case column names from exported field names without athenaconv tags,
+	// e.g. SourceComputersCount matches source_computers_count, and matches tags written in camelCase the same way
+	SnakeCaseNameMatcher NameMatcher = snakeCaseNameMatcher{}
+)
+
+type exactNameMatcher struct{}
+
+func (exactNameMatcher) ColumnName(field reflect.StructField) string {
+	return ""
+}
+
+func (exactNameMatcher) Normalize(columnName string) string {
+	return columnName
+}
+
+type caseInsensitiveNameMatcher struct{}
+
+func (caseInsensitiveNameMatcher) ColumnName(field reflect.StructField) string {
+	return ""
+}
+
+func (caseInsensitiveNameMatcher) Normalize(columnName string) string {
+	return strings.ToLower(columnName)
+}
+
+type snakeCaseNameMatcher struct{}
+
+func (snakeCaseNameMatcher) ColumnName(field reflect.StructField) string {
+	if field.PkgPath != "" {
+		// unexported fields cannot be set by the mapper, require explicit tag
+		return ""
+	}
+	return toSnakeCase(field.Name)
+}
+
+func (snakeCaseNameMatcher) Normalize(columnName string) string {
+	return toSnakeCase(columnName)
+}
+
+// toSnakeCase converts camelCase and PascalCase names to snake_case, keeping acronyms together, e.g. SourceComputerIDs to source_computer_ids
+func toSnakeCase(name string) string {
+	runes := []rune(name)
+	var builder strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			previous := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(previous) || unicode.IsDigit(previous) || (unicode.IsUpper(previous) && nextIsLower && !isPluralSuffix(runes, i)) {
+				builder.WriteRune('_')
+			}
+		}
+		builder.WriteRune(unicode.ToLower(r))
+	}
+	return builder.String()
+}
+
+// isPluralSuffix returns true if the rune at index is the last upper case letter of an acronym followed by a plural "s", e.g. IDs
+func isPluralSuffix(runes []rune, index int) bool {
+	return index+2 == len(runes) && runes[index+1] == 's'
+}
+
+// normalizeColumnKey normalizes the name part of athenaconv column key, positional keys are returned as is
+func normalizeColumnKey(matcher NameMatcher, key string) (string, error) {
+	parsedKey, err := parseColumnKey(key)
+	if err != nil {
+		return "", err
+	}
+	if parsedKey.isPositional() {
+		return key, nil
+	}
+	return occurrenceKey(matcher.Normalize(parsedKey.name), parsedKey.occurrence), nil
+}
@@ -0,0 +1,146 @@
+package athenaconv
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Name matcher", func() {
+	Context("toSnakeCase", func() {
+		It("should convert field names to snake_case", func() {
+			Expect(toSnakeCase("ID")).To(Equal("id"))
+			Expect(toSnakeCase("Name")).To(Equal("name"))
+			Expect(toSnakeCase("SourceComputersCount")).To(Equal("source_computers_count"))
+			Expect(toSnakeCase("SourceComputerExternalIDs")).To(Equal("source_computer_external_ids"))
+			Expect(toSnakeCase("HTTPServerName")).To(Equal("http_server_name"))
+			Expect(toSnakeCase("sourceComputersCount")).To(Equal("source_computers_count"))
+			Expect(toSnakeCase("Test2Value")).To(Equal("test2_value"))
+			Expect(toSnakeCase("source_computers_count")).To(Equal("source_computers_count"))
+		})
+	})
+
+	Context("newModelDefinitionMap", func() {
+		When("matcher is case-insensitive", func() {
+			It("should key columns by lower case name", func() {
+				type test struct {
+					ID   int    `athenaconv:"myId"`
+					Name string `athenaconv:"Name#2"`
+				}
+				def, err := newModelDefinitionMap(reflect.TypeOf(test{}), CaseInsensitiveNameMatcher)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(def["myid"].fieldName).To(Equal("ID"))
+				Expect(def["name#2"].fieldName).To(Equal("Name"))
+			})
+
+			It("should still require tags", func() {
+				type test struct {
+					ID int
+				}
+				_, err := newModelDefinitionMap(reflect.TypeOf(test{}), CaseInsensitiveNameMatcher)
+				Expect(err).To(HaveOccurred())
+				Expect(strings.ToLower(err.Error())).To(ContainSubstring("missing athenacolname"))
+			})
+
+			It("should return error on duplicate tags differing only by case", func() {
+				type test struct {
+					ID      int `athenaconv:"id"`
+					OtherID int `athenaconv:"ID"`
+				}
+				_, err := newModelDefinitionMap(reflect.TypeOf(test{}), CaseInsensitiveNameMatcher)
+				Expect(err).To(HaveOccurred())
+				Expect(strings.ToLower(err.Error())).To(ContainSubstring("duplicate"))
+			})
+		})
+
+		When("matcher is snake_case", func() {
+			It("should derive column names from fields without tags", func() {
+				type test struct {
+					ID                   int
+					SourceComputersCount int64
+					Name                 string `athenaconv:"display_name"`
+					Position             int    `athenaconv:"#3"`
+				}
+				def, err := newModelDefinitionMap(reflect.TypeOf(test{}), SnakeCaseNameMatcher)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(def["id"].fieldName).To(Equal("ID"))
+				Expect(def["source_computers_count"].fieldName).To(Equal("SourceComputersCount"))
+				Expect(def["display_name"].fieldName).To(Equal("Name"))
+				Expect(def["#3"].fieldName).To(Equal("Position"))
+			})
+
+			It("should require tags on unexported fields", func() {
+				type test struct {
+					privateField string
+				}
+				_, err := newModelDefinitionMap(reflect.TypeOf(test{}), SnakeCaseNameMatcher)
+				Expect(err).To(HaveOccurred())
+				Expect(strings.ToLower(err.Error())).To(ContainSubstring("missing athenacolname"))
+			})
+		})
+	})
+
+	Context("NewMapperFor with name matcher", func() {
+		var ctx context.Context
+		var resultSet types.ResultSet
+
+		BeforeEach(func() {
+			ctx = context.Background()
+			resultSet = types.ResultSet{
+				ResultSetMetadata: &types.ResultSetMetadata{
+					ColumnInfo: []types.ColumnInfo{
+						{Name: util.RefString("id"), Type: util.RefString("integer")},
+						{Name: util.RefString("source_computers_count"), Type: util.RefString("bigint")},
+					},
+				},
+				Rows: []types.Row{
+					{Data: []types.Datum{{VarCharValue: util.RefString("1")}, {VarCharValue: util.RefString("20")}}},
+				},
+			}
+		})
+
+		It("should map camelCase tags with case-insensitive matcher", func() {
+			type test struct {
+				ID    int   `athenaconv:"ID"`
+				Count int64 `athenaconv:"SOURCE_COMPUTERS_COUNT"`
+			}
+			mapper, err := NewMapperFor(reflect.TypeOf(test{}), WithNameMatcher(CaseInsensitiveNameMatcher))
+			Expect(err).ToNot(HaveOccurred())
+
+			mapped, err := mapper.FromAthenaResultSetV2(ctx, &resultSet)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mapped).To(Equal([]interface{}{&test{ID: 1, Count: 20}}))
+		})
+
+		It("should map untagged and camelCase tagged fields with snake_case matcher", func() {
+			type test struct {
+				ID    int
+				Count int64 `athenaconv:"sourceComputersCount"`
+			}
+			mapper, err := NewMapperFor(reflect.TypeOf(test{}), WithNameMatcher(SnakeCaseNameMatcher))
+			Expect(err).ToNot(HaveOccurred())
+
+			mapped, err := mapper.FromAthenaResultSetV2(ctx, &resultSet)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mapped).To(Equal([]interface{}{&test{ID: 1, Count: 20}}))
+		})
+
+		It("should not match different case with default exact matcher", func() {
+			type test struct {
+				ID    int   `athenaconv:"ID"`
+				Count int64 `athenaconv:"source_computers_count"`
+			}
+			mapper, err := NewMapperFor(reflect.TypeOf(test{}))
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = mapper.FromAthenaResultSetV2(ctx, &resultSet)
+			Expect(err).To(HaveOccurred())
+			Expect(strings.ToLower(err.Error())).To(MatchRegexp("'id' .* not found"))
+		})
+	})
+})
@@ -0,0 +1,108 @@
+package athenaconv
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+)
+
+// queryResultsJSON is a page of the JSON output of aws athena get-query-results, or its ResultSet object
+type queryResultsJSON struct {
+	ResultSet *queryResultsJSON `json:"ResultSet"`
+	Rows      []struct {
+		Data []struct {
+			VarCharValue *string `json:"VarCharValue"`
+		} `json:"Data"`
+	} `json:"Rows"`
+	ResultSetMetadata *struct {
+		ColumnInfo []columnInfoJSON `json:"ColumnInfo"`
+	} `json:"ResultSetMetadata"`
+}
+
+// columnInfoJSON is a column of the ResultSetMetadata in the JSON output of the AWS CLI
+type columnInfoJSON struct {
+	CatalogName   *string `json:"CatalogName"`
+	SchemaName    *string `json:"SchemaName"`
+	TableName     *string `json:"TableName"`
+	Name          *string `json:"Name"`
+	Label         *string `json:"Label"`
+	Type          *string `json:"Type"`
+	Precision     int32   `json:"Precision"`
+	Scale         int32   `json:"Scale"`
+	Nullable      string  `json:"Nullable"`
+	CaseSensitive bool    `json:"CaseSensitive"`
+}
+
+// ReadQueryResults reads ResultSet pages from saved JSON outputs of GetQueryResults, e.g. of aws athena get-query-results,
+// which can be converted by DataMapper and DynamicMapper. The reader may contain several concatenated pages,
+// pages without ResultSetMetadata take the metadata of the previous page.
+//
+// Example:
+//
+//	// aws athena get-query-results --query-execution-id <id> > results.json
+//	pages, err := athenaconv.ReadQueryResults(file)
+func ReadQueryResults(reader io.Reader) ([]*types.ResultSet, error) {
+	decoder := json.NewDecoder(reader)
+	pages := make([]*types.ResultSet, 0)
+	var metadata *types.ResultSetMetadata
+	for {
+		var page queryResultsJSON
+		err := decoder.Decode(&page)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			err := fmt.Errorf("invalid GetQueryResults JSON, page: %d: %v", len(pages)+1, err)
+			return nil, err
+		}
+
+		resultSet := &page
+		if page.ResultSet != nil {
+			resultSet = page.ResultSet
+		}
+		if resultSet.ResultSetMetadata != nil {
+			metadata = newResultSetMetadata(resultSet.ResultSetMetadata.ColumnInfo)
+		}
+		if metadata == nil {
+			err := fmt.Errorf("invalid GetQueryResults JSON, page: %d: ResultSetMetadata is missing", len(pages)+1)
+			return nil, err
+		}
+
+		rows := make([]types.Row, 0, len(resultSet.Rows))
+		for _, row := range resultSet.Rows {
+			data := make([]types.Datum, 0, len(row.Data))
+			for _, datum := range row.Data {
+				data = append(data, types.Datum{VarCharValue: datum.VarCharValue})
+			}
+			rows = append(rows, types.Row{Data: data})
+		}
+		pages = append(pages, &types.ResultSet{ResultSetMetadata: metadata, Rows: rows})
+	}
+
+	if len(pages) == 0 {
+		err := fmt.Errorf("invalid GetQueryResults JSON: no result set found")
+		return nil, err
+	}
+	return pages, nil
+}
+
+func newResultSetMetadata(columns []columnInfoJSON) *types.ResultSetMetadata {
+	metadata := &types.ResultSetMetadata{ColumnInfo: make([]types.ColumnInfo, 0, len(columns))}
+	for _, column := range columns {
+		metadata.ColumnInfo = append(metadata.ColumnInfo, types.ColumnInfo{
+			CatalogName:   column.CatalogName,
+			SchemaName:    column.SchemaName,
+			TableName:     column.TableName,
+			Name:          column.Name,
+			Label:         column.Label,
+			Type:          column.Type,
+			Precision:     column.Precision,
+			Scale:         column.Scale,
+			Nullable:      types.ColumnNullable(column.Nullable),
+			CaseSensitive: column.CaseSensitive,
+		})
+	}
+	return metadata
+}
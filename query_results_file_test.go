@@ -0,0 +1,66 @@
+package athenaconv
+
+import (
+	"context"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReadQueryResults", func() {
+	const firstPage = `{
+		"UpdateCount": 0,
+		"ResultSet": {
+			"Rows": [
+				{"Data": [{"VarCharValue": "id"}, {"VarCharValue": "name"}]},
+				{"Data": [{"VarCharValue": "1"}, {}]}
+			],
+			"ResultSetMetadata": {"ColumnInfo": [
+				{"CatalogName": "hive", "Name": "id", "Label": "id", "Type": "integer", "Precision": 10, "Nullable": "NOT_NULL", "CaseSensitive": false},
+				{"CatalogName": "hive", "Name": "name", "Label": "name", "Type": "varchar", "Precision": 2147483647, "Nullable": "UNKNOWN", "CaseSensitive": true}
+			]}
+		},
+		"NextToken": "token"
+	}`
+	const secondPage = `{"Rows": [{"Data": [{"VarCharValue": "2"}, {"VarCharValue": "second"}]}]}`
+
+	It("should read the pages of GetQueryResults output", func() {
+		pages, err := ReadQueryResults(strings.NewReader(firstPage + "\n" + secondPage))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(pages).To(HaveLen(2))
+
+		metadata := pages[0].ResultSetMetadata
+		Expect(metadata.ColumnInfo).To(Equal([]types.ColumnInfo{
+			{CatalogName: util.RefString("hive"), Name: util.RefString("id"), Label: util.RefString("id"), Type: util.RefString("integer"), Precision: 10, Nullable: types.ColumnNullableNotNull},
+			{CatalogName: util.RefString("hive"), Name: util.RefString("name"), Label: util.RefString("name"), Type: util.RefString("varchar"), Precision: 2147483647, Nullable: types.ColumnNullableUnknown, CaseSensitive: true},
+		}))
+		Expect(pages[0].Rows).To(Equal([]types.Row{
+			{Data: []types.Datum{{VarCharValue: util.RefString("id")}, {VarCharValue: util.RefString("name")}}},
+			{Data: []types.Datum{{VarCharValue: util.RefString("1")}, {}}},
+		}))
+		Expect(pages[1].ResultSetMetadata).To(BeIdenticalTo(metadata))
+	})
+
+	It("should read pages convertible by the mappers", func() {
+		pages, err := ReadQueryResults(strings.NewReader(firstPage))
+		Expect(err).ToNot(HaveOccurred())
+
+		maps, err := NewDynamicMapper().MapsFromAthenaResultSetV2(context.Background(), pages[0])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(maps).To(Equal([]map[string]interface{}{{"id": 1, "name": nil}}))
+	})
+
+	It("should return error for invalid output", func() {
+		_, err := ReadQueryResults(strings.NewReader(""))
+		Expect(err).To(MatchError("invalid GetQueryResults JSON: no result set found"))
+
+		_, err = ReadQueryResults(strings.NewReader(secondPage))
+		Expect(err).To(MatchError("invalid GetQueryResults JSON, page: 1: ResultSetMetadata is missing"))
+
+		_, err = ReadQueryResults(strings.NewReader(firstPage + "{"))
+		Expect(err).To(MatchError(ContainSubstring("invalid GetQueryResults JSON, page: 2: ")))
+	})
+})
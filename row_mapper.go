@@ -0,0 +1,89 @@
+package athenaconv
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+)
+
+// ColumnIndex binds the fields of a model, by their index in the struct, to the result set columns they are converted from.
+// It is built by the mapper for every ResultSet and passed to the generated row mappers, see RegisterRowMapper.
+type ColumnIndex struct {
+	indexes []int
+	types   []string
+}
+
+// RowMapperFunc converts a row of the ResultSet to a pointer to the model, e.g. *MyModel,
+// as generated by athenaconv-gen -mapper
+type RowMapperFunc func(row types.Row, idx ColumnIndex) (interface{}, error)
+
+var (
+	rowMappersMu sync.RWMutex
+	rowMappers   = make(map[reflect.Type]RowMapperFunc)
+)
+
+// newColumnIndex returns the column index of the bindings of a model with numField fields
+func newColumnIndex(bindings []resultSetBinding, numField int) ColumnIndex {
+	idx := ColumnIndex{
+		indexes: make([]int, numField),
+		types:   make([]string, numField),
+	}
+	for i := range idx.indexes {
+		idx.indexes[i] = -1
+	}
+	for _, binding := range bindings {
+		idx.indexes[binding.fieldIndex] = binding.colInfo.index
+		idx.types[binding.fieldIndex] = binding.colInfo.athenaColumnType
+	}
+	return idx
+}
+
+// Index returns the index in the row data of the column bound to the field, -1 if the field is not bound
+func (idx ColumnIndex) Index(field int) int {
+	if field < 0 || field >= len(idx.indexes) {
+		return -1
+	}
+	return idx.indexes[field]
+}
+
+// Type returns the athena type of the column bound to the field as returned in the ResultSetMetadata, e.g. varchar
+func (idx ColumnIndex) Type(field int) string {
+	if field < 0 || field >= len(idx.types) {
+		return ""
+	}
+	return idx.types[field]
+}
+
+// RegisterRowMapper registers the generated row mapper of modelType, which NewMapperFor then uses instead of reflection.
+// It is called by the init function of the code generated by athenaconv-gen -mapper, see WithReflection to opt out.
+// modelType should be of struct value type, not pointer to struct.
+func RegisterRowMapper(modelType reflect.Type, mapper RowMapperFunc) {
+	rowMappersMu.Lock()
+	defer rowMappersMu.Unlock()
+	if mapper == nil {
+		delete(rowMappers, modelType)
+		return
+	}
+	rowMappers[modelType] = mapper
+}
+
+// registeredRowMapper returns the generated row mapper of modelType, nil if none is registered
+func registeredRowMapper(modelType reflect.Type) RowMapperFunc {
+	rowMappersMu.RLock()
+	defer rowMappersMu.RUnlock()
+	return rowMappers[modelType]
+}
+
+// SetColumnValue converts the athena column value as the reflective mapper does and sets it to the field pointed by target,
+// e.g. &model.Tags. It is used by the generated row mappers for the field types without inlined conversion.
+func SetColumnValue(target interface{}, datum types.Datum, athenaType string) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		err := fmt.Errorf("invalid target of type %T, expecting non-nil pointer to the field", target)
+		return err
+	}
+	return setFieldValue(context.Background(), value.Elem(), datum, athenaType)
+}
@@ -0,0 +1,134 @@
+package athenaconv
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+type registeredModel struct {
+	Name string `athenaconv:"name"`
+	ID   int    `athenaconv:"id"`
+}
+
+var _ = Describe("RowMapper", func() {
+	var ctx context.Context
+	var resultSet *types.ResultSet
+	var indexes []ColumnIndex
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		resultSet = &types.ResultSet{
+			ResultSetMetadata: &types.ResultSetMetadata{ColumnInfo: []types.ColumnInfo{
+				{Name: util.RefString("id"), Type: util.RefString("integer")},
+				{Name: util.RefString("name"), Type: util.RefString("varchar")},
+			}},
+			Rows: []types.Row{
+				{Data: []types.Datum{{VarCharValue: util.RefString("id")}, {VarCharValue: util.RefString("name")}}},
+				{Data: []types.Datum{{VarCharValue: util.RefString("1")}, {VarCharValue: util.RefString("first")}}},
+			},
+		}
+
+		indexes = make([]ColumnIndex, 0)
+		RegisterRowMapper(reflect.TypeOf(registeredModel{}), func(row types.Row, idx ColumnIndex) (interface{}, error) {
+			indexes = append(indexes, idx)
+			id, err := strconv.Atoi(*row.Data[idx.Index(1)].VarCharValue)
+			if err != nil {
+				return nil, err
+			}
+			return &registeredModel{Name: "generated " + *row.Data[idx.Index(0)].VarCharValue, ID: id}, nil
+		})
+	})
+
+	AfterEach(func() {
+		RegisterRowMapper(reflect.TypeOf(registeredModel{}), nil)
+	})
+
+	When("row mapper is registered for the model type", func() {
+		It("should map the rows with the registered row mapper", func() {
+			mapper, err := NewMapperFor(reflect.TypeOf(registeredModel{}))
+			Expect(err).ToNot(HaveOccurred())
+
+			mapped, err := mapper.FromAthenaResultSetV2(ctx, resultSet)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mapped).To(Equal([]interface{}{&registeredModel{Name: "generated first", ID: 1}}))
+
+			Expect(indexes).To(HaveLen(1))
+			Expect(indexes[0].Index(0)).To(Equal(1))
+			Expect(indexes[0].Type(0)).To(Equal("varchar"))
+			Expect(indexes[0].Index(1)).To(Equal(0))
+			Expect(indexes[0].Type(1)).To(Equal("integer"))
+			Expect(indexes[0].Index(2)).To(Equal(-1))
+			Expect(indexes[0].Type(-1)).To(Equal(""))
+		})
+
+		It("should return error of the registered row mapper", func() {
+			resultSet.Rows[1].Data[0].VarCharValue = util.RefString("abc")
+			mapper, err := NewMapperFor(reflect.TypeOf(registeredModel{}))
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = mapper.FromAthenaResultSetV2(ctx, resultSet)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should map the rows with reflection using WithReflection", func() {
+			mapper, err := NewMapperFor(reflect.TypeOf(registeredModel{}), WithReflection())
+			Expect(err).ToNot(HaveOccurred())
+
+			mapped, err := mapper.FromAthenaResultSetV2(ctx, resultSet)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(mapped).To(Equal([]interface{}{&registeredModel{Name: "first", ID: 1}}))
+			Expect(indexes).To(BeEmpty())
+		})
+
+		It("should still validate the result set against the model", func() {
+			resultSet.ResultSetMetadata.ColumnInfo[1].Name = util.RefString("other")
+			mapper, err := NewMapperFor(reflect.TypeOf(registeredModel{}))
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = mapper.FromAthenaResultSetV2(ctx, resultSet)
+			Expect(err).To(MatchError("column 'name' is defined in model schema but not found in result set"))
+			Expect(indexes).To(BeEmpty())
+		})
+
+		It("should validate the column types against the model", func() {
+			resultSet.ResultSetMetadata.ColumnInfo[0].Type = util.RefString("bigint")
+			mapper, err := NewMapperFor(reflect.TypeOf(registeredModel{}))
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = mapper.FromAthenaResultSetV2(ctx, resultSet)
+			Expect(err).To(MatchError("cannot map column 'id' of athena type bigint to field ID of type int"))
+			Expect(indexes).To(BeEmpty())
+		})
+	})
+
+	Context("SetColumnValue", func() {
+		It("should convert the value as the reflective mapper", func() {
+			var count *int
+			Expect(SetColumnValue(&count, types.Datum{VarCharValue: util.RefString("12")}, "integer")).To(Succeed())
+			Expect(*count).To(Equal(12))
+			Expect(SetColumnValue(&count, types.Datum{}, "integer")).To(Succeed())
+			Expect(count).To(BeNil())
+
+			var tags map[string]int64
+			Expect(SetColumnValue(&tags, types.Datum{VarCharValue: util.RefString(`{"a":1}`)}, "json")).To(Succeed())
+			Expect(tags).To(Equal(map[string]int64{"a": 1}))
+		})
+
+		It("should return error if target is not a pointer", func() {
+			err := SetColumnValue(1, types.Datum{}, "integer")
+			Expect(err).To(MatchError("invalid target of type int, expecting non-nil pointer to the field"))
+		})
+
+		It("should return error if the athena type cannot be converted to the target", func() {
+			var tags map[string]int64
+			err := SetColumnValue(&tags, types.Datum{VarCharValue: util.RefString("{a=1}")}, "map")
+			Expect(err).To(MatchError("cannot convert athena type map to map[string]int64"))
+		})
+	})
+})
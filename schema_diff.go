@@ -0,0 +1,241 @@
+package athenaconv
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv/util"
+)
+
+// ColumnChangeKind is the kind of difference between a model column and a table column
+type ColumnChangeKind string
+
+const (
+	// ColumnAdded is a column of the model missing in the table
+	ColumnAdded ColumnChangeKind = "added"
+	// ColumnRemoved is a column of the table missing in the model
+	ColumnRemoved ColumnChangeKind = "removed"
+	// ColumnTypeChanged is a column whose type in the model differs from its type in the table
+	ColumnTypeChanged ColumnChangeKind = "type_changed"
+)
+
+var (
+	// widenedColumnTypes are the model types to which the table types can be changed keeping the existing data files readable
+	widenedColumnTypes = map[string][]string{
+		"tinyint":  {"smallint", "int", "bigint"},
+		"smallint": {"int", "bigint"},
+		"int":      {"bigint"},
+		"float":    {"double"},
+	}
+
+	integerTypePattern = regexp.MustCompile(`\binteger\b`)
+)
+
+// ColumnChange is a difference between the columns of a model and of a table
+type ColumnChange struct {
+	Kind ColumnChangeKind
+	// Name of the column
+	Name string
+	// ModelType is the type of the column inferred from the model, empty if removed
+	ModelType string
+	// TableType is the type of the column in the table, empty if added
+	TableType string
+	// Incompatible is true if the existing data files cannot be read with the new type of the column
+	Incompatible bool
+}
+
+// SchemaDiff is the result of DiffSchema
+type SchemaDiff struct {
+	// Changes are the added and type changed columns in model order, followed by the removed columns in table order
+	Changes []ColumnChange
+	// columns are the table columns of the model, without the partition columns unless iceberg
+	columns []ddlColumn
+	iceberg bool
+}
+
+// DiffSchema compares the table columns of modelType, as generated by GenerateDDL with the same options, with the columns of the existing table,
+// e.g. TableMetadata.Columns of the GetTableMetadata response of athena, or the columns read by ReadTableColumns.
+// Only NameMatcher and Iceberg of the optional DDLOptions are used: partition columns are compared for iceberg tables only,
+// as they are part of their table columns. Column names are compared ignoring case and types ignoring case and spaces,
+// as normalized by the glue catalog.
+//
+// Example:
+//
+//	diff, err := athenaconv.DiffSchema(reflect.TypeOf(Event{}), output.TableMetadata.Columns, athenaconv.DDLOptions{Iceberg: true})
+//	statements, err := diff.AlterStatements("my_db.events")
+func DiffSchema(modelType reflect.Type, existing []types.Column, opts ...DDLOptions) (*SchemaDiff, error) {
+	var options DDLOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+	if options.NameMatcher == nil {
+		options.NameMatcher = ExactNameMatcher
+	}
+	modelColumns, err := modelDDLColumns(modelType, options.NameMatcher, options.Iceberg)
+	if err != nil {
+		return nil, err
+	}
+
+	tableTypes := make(map[string]string, len(existing))
+	for i, column := range existing {
+		name := strings.ToLower(util.SafeString(column.Name))
+		if name == "" {
+			err := fmt.Errorf("table column name is empty, index: %d", i)
+			return nil, err
+		}
+		tableTypes[name] = util.SafeString(column.Type)
+	}
+
+	diff := &SchemaDiff{iceberg: options.Iceberg}
+	modelNames := make(map[string]bool, len(modelColumns))
+	for _, column := range modelColumns {
+		if column.partition && !options.Iceberg {
+			continue
+		}
+		diff.columns = append(diff.columns, column)
+		name := strings.ToLower(column.name)
+		modelNames[name] = true
+
+		tableType, ok := tableTypes[name]
+		switch {
+		case !ok:
+			diff.Changes = append(diff.Changes, ColumnChange{Kind: ColumnAdded, Name: column.name, ModelType: column.columnType})
+		case normalizeColumnType(tableType) != normalizeColumnType(column.columnType):
+			diff.Changes = append(diff.Changes, ColumnChange{
+				Kind:         ColumnTypeChanged,
+				Name:         column.name,
+				ModelType:    column.columnType,
+				TableType:    tableType,
+				Incompatible: !isWidenedColumnType(tableType, column.columnType),
+			})
+		}
+	}
+	for _, column := range existing {
+		name := util.SafeString(column.Name)
+		if !modelNames[strings.ToLower(name)] {
+			diff.Changes = append(diff.Changes, ColumnChange{Kind: ColumnRemoved, Name: name, TableType: util.SafeString(column.Type)})
+		}
+	}
+	return diff, nil
+}
+
+// HasChanges returns true if the model and the table columns differ
+func (d *SchemaDiff) HasChanges() bool {
+	return len(d.Changes) > 0
+}
+
+// Incompatible returns the type changes which make the existing data files unreadable, e.g. string to bigint
+func (d *SchemaDiff) Incompatible() []ColumnChange {
+	incompatible := make([]ColumnChange, 0)
+	for _, change := range d.Changes {
+		if change.Incompatible {
+			incompatible = append(incompatible, change)
+		}
+	}
+	return incompatible
+}
+
+// AlterStatements returns the statements migrating the table to the model columns: ALTER TABLE ADD COLUMNS if columns are only added,
+// otherwise ALTER TABLE REPLACE COLUMNS with every column of the model. Iceberg tables, which do not support REPLACE COLUMNS,
+// get ALTER TABLE ADD COLUMNS for the added columns, then ALTER TABLE CHANGE COLUMN and ALTER TABLE DROP COLUMN for every type changed
+// and removed column. No statement is returned if there is no change.
+// Check Incompatible before running them, and note that removing columns of CSV tables shifts the columns read by position.
+func (d *SchemaDiff) AlterStatements(table string) ([]string, error) {
+	if !d.HasChanges() {
+		return []string{}, nil
+	}
+	quotedTable, err := quoteTableName(table)
+	if err != nil {
+		return nil, err
+	}
+	if d.iceberg {
+		return d.icebergAlterStatements(quotedTable)
+	}
+
+	columns := d.columns
+	action := "REPLACE"
+	if d.onlyAdded() {
+		action = "ADD"
+		columns = make([]ddlColumn, 0, len(d.Changes))
+		for _, change := range d.Changes {
+			columns = append(columns, ddlColumn{name: change.Name, columnType: change.ModelType})
+		}
+	}
+
+	statement, err := alterColumnsStatement(quotedTable, action, columns)
+	if err != nil {
+		return nil, err
+	}
+	return []string{statement}, nil
+}
+
+// icebergAlterStatements returns the statements migrating the iceberg table column by column, as iceberg has no REPLACE COLUMNS
+func (d *SchemaDiff) icebergAlterStatements(quotedTable string) ([]string, error) {
+	added := make([]ddlColumn, 0)
+	statements := make([]string, 0)
+	for _, change := range d.Changes {
+		if change.Kind == ColumnAdded {
+			added = append(added, ddlColumn{name: change.Name, columnType: change.ModelType})
+			continue
+		}
+		name, err := quoteDDLIdentifier(change.Name)
+		if err != nil {
+			return nil, err
+		}
+		if change.Kind == ColumnTypeChanged {
+			statements = append(statements, "ALTER TABLE "+quotedTable+" CHANGE COLUMN "+name+" "+name+" "+change.ModelType)
+		} else {
+			statements = append(statements, "ALTER TABLE "+quotedTable+" DROP COLUMN "+name)
+		}
+	}
+	if len(added) == 0 {
+		return statements, nil
+	}
+
+	statement, err := alterColumnsStatement(quotedTable, "ADD", added)
+	if err != nil {
+		return nil, err
+	}
+	return append([]string{statement}, statements...), nil
+}
+
+// alterColumnsStatement returns the ALTER TABLE ADD COLUMNS or REPLACE COLUMNS statement of the columns
+func alterColumnsStatement(quotedTable string, action string, columns []ddlColumn) (string, error) {
+	definitions := make([]string, 0, len(columns))
+	for _, column := range columns {
+		name, err := quoteDDLIdentifier(column.name)
+		if err != nil {
+			return "", err
+		}
+		definitions = append(definitions, name+" "+column.columnType)
+	}
+	return "ALTER TABLE " + quotedTable + " " + action + " COLUMNS (\n  " + strings.Join(definitions, ",\n  ") + "\n)", nil
+}
+
+func (d *SchemaDiff) onlyAdded() bool {
+	for _, change := range d.Changes {
+		if change.Kind != ColumnAdded {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeColumnType returns the column type in lower case without spaces, e.g. map<string, integer> as map<string,int>
+func normalizeColumnType(columnType string) string {
+	normalized := strings.ToLower(strings.Join(strings.Fields(columnType), ""))
+	return integerTypePattern.ReplaceAllString(normalized, "int")
+}
+
+// isWidenedColumnType returns true if the table column type can be changed to the model column type keeping the data readable
+func isWidenedColumnType(tableType string, modelType string) bool {
+	for _, widened := range widenedColumnTypes[normalizeColumnType(tableType)] {
+		if widened == normalizeColumnType(modelType) {
+			return true
+		}
+	}
+	return false
+}
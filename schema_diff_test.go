@@ -0,0 +1,169 @@
+package athenaconv
+
+import (
+	"reflect"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DiffSchema", func() {
+	type event struct {
+		ID     int64            `athenaconv:"id"`
+		Name   string           `athenaconv:"name"`
+		Counts map[string]int64 `athenaconv:"counts"`
+		Day    string           `athenaconv:"day,partition"`
+	}
+	column := func(name, columnType string) types.Column {
+		return types.Column{Name: util.RefString(name), Type: util.RefString(columnType)}
+	}
+
+	When("table matches the model", func() {
+		It("should report no change ignoring case and spaces", func() {
+			diff, err := DiffSchema(reflect.TypeOf(event{}), []types.Column{
+				column("ID", "BIGINT"),
+				column("name", "string"),
+				column("counts", "map<string, bigint>"),
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(diff.HasChanges()).To(BeFalse())
+
+			statements, err := diff.AlterStatements("my_db.events")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(statements).To(BeEmpty())
+		})
+	})
+
+	When("model has new columns", func() {
+		It("should add them", func() {
+			// arrange
+			existing := []types.Column{column("id", "bigint")}
+
+			// act
+			diff, err := DiffSchema(reflect.TypeOf(event{}), existing)
+			Expect(err).ToNot(HaveOccurred())
+			statements, err := diff.AlterStatements("my_db.events")
+
+			// assert
+			Expect(err).ToNot(HaveOccurred())
+			Expect(diff.Changes).To(Equal([]ColumnChange{
+				{Kind: ColumnAdded, Name: "name", ModelType: "string"},
+				{Kind: ColumnAdded, Name: "counts", ModelType: "map<string,bigint>"},
+			}))
+			Expect(diff.Incompatible()).To(BeEmpty())
+			Expect(statements).To(Equal([]string{"ALTER TABLE `my_db`.`events` ADD COLUMNS (\n  `name` string,\n  `counts` map<string,bigint>\n)"}))
+		})
+	})
+
+	When("columns are removed or their type changed", func() {
+		It("should replace the columns and flag the incompatible changes", func() {
+			// arrange
+			existing := []types.Column{
+				column("id", "int"),
+				column("name", "bigint"),
+				column("counts", "map<string,int>"),
+				column("legacy", "string"),
+			}
+
+			// act
+			diff, err := DiffSchema(reflect.TypeOf(event{}), existing)
+			Expect(err).ToNot(HaveOccurred())
+			statements, err := diff.AlterStatements("events")
+
+			// assert
+			Expect(err).ToNot(HaveOccurred())
+			Expect(diff.Changes).To(Equal([]ColumnChange{
+				{Kind: ColumnTypeChanged, Name: "id", ModelType: "bigint", TableType: "int"},
+				{Kind: ColumnTypeChanged, Name: "name", ModelType: "string", TableType: "bigint", Incompatible: true},
+				{Kind: ColumnTypeChanged, Name: "counts", ModelType: "map<string,bigint>", TableType: "map<string,int>", Incompatible: true},
+				{Kind: ColumnRemoved, Name: "legacy", TableType: "string"},
+			}))
+			Expect(diff.Incompatible()).To(HaveLen(2))
+			Expect(statements).To(Equal([]string{"ALTER TABLE `events` REPLACE COLUMNS (\n  `id` bigint,\n  `name` string,\n  `counts` map<string,bigint>\n)"}))
+		})
+
+		It("should treat integer as int", func() {
+			type model struct {
+				ID int32 `athenaconv:"id"`
+			}
+			diff, err := DiffSchema(reflect.TypeOf(model{}), []types.Column{column("id", "integer")})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(diff.HasChanges()).To(BeFalse())
+		})
+	})
+
+	When("table is an iceberg table", func() {
+		type icebergEvent struct {
+			ID       int64  `athenaconv:"id"`
+			Priority int8   `athenaconv:"priority"`
+			Name     string `athenaconv:"name"`
+			Day      string `athenaconv:"day,partition"`
+		}
+
+		It("should compare the iceberg types and the partition columns", func() {
+			diff, err := DiffSchema(reflect.TypeOf(icebergEvent{}), []types.Column{
+				column("id", "bigint"),
+				column("priority", "int"),
+				column("name", "string"),
+				column("day", "string"),
+			}, DDLOptions{Iceberg: true})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(diff.HasChanges()).To(BeFalse())
+		})
+
+		It("should add, change and drop the columns one by one", func() {
+			// arrange
+			existing := []types.Column{
+				column("id", "int"),
+				column("priority", "int"),
+				column("legacy", "string"),
+			}
+
+			// act
+			diff, err := DiffSchema(reflect.TypeOf(icebergEvent{}), existing, DDLOptions{Iceberg: true})
+			Expect(err).ToNot(HaveOccurred())
+			statements, err := diff.AlterStatements("my_db.events")
+
+			// assert
+			Expect(err).ToNot(HaveOccurred())
+			Expect(diff.Changes).To(Equal([]ColumnChange{
+				{Kind: ColumnTypeChanged, Name: "id", ModelType: "bigint", TableType: "int"},
+				{Kind: ColumnAdded, Name: "name", ModelType: "string"},
+				{Kind: ColumnAdded, Name: "day", ModelType: "string"},
+				{Kind: ColumnRemoved, Name: "legacy", TableType: "string"},
+			}))
+			Expect(statements).To(Equal([]string{
+				"ALTER TABLE `my_db`.`events` ADD COLUMNS (\n  `name` string,\n  `day` string\n)",
+				"ALTER TABLE `my_db`.`events` CHANGE COLUMN `id` `id` bigint",
+				"ALTER TABLE `my_db`.`events` DROP COLUMN `legacy`",
+			}))
+		})
+	})
+
+	When("names are matched with a name matcher", func() {
+		It("should derive the column names of fields without tag", func() {
+			type model struct {
+				SourceID int64
+			}
+			diff, err := DiffSchema(reflect.TypeOf(model{}), []types.Column{column("source_id", "bigint")}, DDLOptions{NameMatcher: SnakeCaseNameMatcher})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(diff.HasChanges()).To(BeFalse())
+		})
+	})
+
+	When("input is invalid", func() {
+		It("should return error for empty table column name", func() {
+			_, err := DiffSchema(reflect.TypeOf(event{}), []types.Column{{Type: util.RefString("int")}})
+			Expect(err).To(MatchError("table column name is empty, index: 0"))
+		})
+
+		It("should return error for invalid table name", func() {
+			diff, err := DiffSchema(reflect.TypeOf(event{}), nil)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = diff.AlterStatements("")
+			Expect(err).To(MatchError("missing table name"))
+		})
+	})
+})
@@ -10,10 +10,18 @@ type modelDefinitionMap map[string]modelDefinitionColInfo
 
 // modelDefinitionColInfo as defined in the user-defined struct field tags
 type modelDefinitionColInfo struct {
-	fieldName string
+	fieldName  string
+	fieldIndex int
+	// columnName is the athena column name as defined in the struct tag or derived by the NameMatcher, before normalization
+	columnName string
+	// expr is the SQL expression selecting the column defined with the expr tag option, if any
+	expr string
+	// partition is true if the column is marked with the partition tag option
+	partition bool
 }
 
-func newModelDefinitionMap(modelType reflect.Type) (modelDefinitionMap, error) {
+// newModelDefinitionMap reads the schema definition from struct tags, keyed by the column name normalized by the given NameMatcher
+func newModelDefinitionMap(modelType reflect.Type, matcher NameMatcher) (modelDefinitionMap, error) {
 	if modelType.Kind() != reflect.Struct {
 		err := fmt.Errorf("%s is invalid modelType, expecting kind of 'struct' but got '%s'", modelType.String(), modelType.Kind())
 		return nil, err
@@ -28,15 +36,31 @@ func newModelDefinitionMap(modelType reflect.Type) (modelDefinitionMap, error) {
 	for i := 0; i < modelType.NumField(); i++ {
 		field := modelType.Field(i)
 		fieldName := field.Name
-		athenaColName := field.Tag.Get("athenaconv")
+		tag, err := parseFieldTag(field)
+		if err != nil {
+			return nil, err
+		}
+		athenaColName := tag.columnName
+		if athenaColName == "" {
+			athenaColName = matcher.ColumnName(field)
+		}
 		if athenaColName == "" {
 			err := fmt.Errorf("missing athenaColName for fieldName: %s", fieldName)
 			return nil, err
 		}
+		columnName := athenaColName
+		athenaColName, err = normalizeColumnKey(matcher, athenaColName)
+		if err != nil {
+			return nil, err
+		}
 
 		if _, ok := schema[athenaColName]; !ok {
 			schema[athenaColName] = modelDefinitionColInfo{
-				fieldName: fieldName,
+				fieldName:  fieldName,
+				fieldIndex: i,
+				columnName: columnName,
+				expr:       tag.expr,
+				partition:  tag.partition,
 			}
 		} else {
 			err := fmt.Errorf("duplicate athenaColName found: %s", athenaColName)
@@ -16,7 +16,7 @@ var _ = Describe("Schema: model definition", func() {
 				Name         string `athenaconv:"name_col"`
 				privateField string `athenaconv:"pvt_field"`
 			}
-			def, err := newModelDefinitionMap(reflect.TypeOf(test{}))
+			def, err := newModelDefinitionMap(reflect.TypeOf(test{}), ExactNameMatcher)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(len(def)).To(Equal(3))
 			Expect(def["my_id_col"].fieldName).To(Equal("ID"))
@@ -31,7 +31,7 @@ var _ = Describe("Schema: model definition", func() {
 				ID   int `athenaconv:"my_id_col"`
 				Name string
 			}
-			_, err := newModelDefinitionMap(reflect.TypeOf(test{}))
+			_, err := newModelDefinitionMap(reflect.TypeOf(test{}), ExactNameMatcher)
 			Expect(err).To(HaveOccurred())
 			Expect(strings.ToLower(err.Error())).To(MatchRegexp("missing .* name"))
 		})
@@ -43,7 +43,7 @@ var _ = Describe("Schema: model definition", func() {
 				ID   int    `athenaconv:"my_id_col"`
 				Name string `athenaconv:"my_id_col"`
 			}
-			_, err := newModelDefinitionMap(reflect.TypeOf(test{}))
+			_, err := newModelDefinitionMap(reflect.TypeOf(test{}), ExactNameMatcher)
 			Expect(err).To(HaveOccurred())
 			Expect(strings.ToLower(err.Error())).To(MatchRegexp("duplicate .* my_id_col"))
 		})
@@ -52,7 +52,7 @@ var _ = Describe("Schema: model definition", func() {
 	When("struct has no fields", func() {
 		It("should return expected column definition", func() {
 			type test struct{}
-			_, err := newModelDefinitionMap(reflect.TypeOf(test{}))
+			_, err := newModelDefinitionMap(reflect.TypeOf(test{}), ExactNameMatcher)
 			Expect(err).To(HaveOccurred())
 			Expect(strings.ToLower(err.Error())).To(ContainSubstring("at least one field"))
 		})
@@ -63,7 +63,7 @@ var _ = Describe("Schema: model definition", func() {
 			type test struct {
 				ID int `athenaconv:"my_id_col"`
 			}
-			_, err := newModelDefinitionMap(reflect.TypeOf(&test{}))
+			_, err := newModelDefinitionMap(reflect.TypeOf(&test{}), ExactNameMatcher)
 			Expect(err).To(HaveOccurred())
 			Expect(strings.ToLower(err.Error())).To(ContainSubstring("invalid modeltype"))
 		})
@@ -72,7 +72,7 @@ var _ = Describe("Schema: model definition", func() {
 	When("model type is an int instead of struct", func() {
 		It("should return error", func() {
 			var num int = 0
-			_, err := newModelDefinitionMap(reflect.TypeOf(num))
+			_, err := newModelDefinitionMap(reflect.TypeOf(num), ExactNameMatcher)
 			Expect(err).To(HaveOccurred())
 			Expect(strings.ToLower(err.Error())).To(ContainSubstring("invalid modeltype"))
 		})
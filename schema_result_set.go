@@ -8,15 +8,24 @@ import (
 	"github.com/kent-id/athenaconv/util"
 )
 
-// resultSetDefinitionMap is a map of athenaColName to each column returned by atena queries
+// resultSetDefinitionMap is a map of athenaColName to each column returned by atena queries.
+// Duplicate column names are keyed by their occurrence, e.g. id, id#2, id#3.
 type resultSetDefinitionMap map[string]resultSetColInfo
 
 // resultSetColInfo as retrieved from the ResultSetMetadata returned by athena queries
 type resultSetColInfo struct {
 	index            int
+	name             string
 	athenaColumnType string
 }
 
+// resultSetBinding binds a model field to the result set column it is converted from
+type resultSetBinding struct {
+	fieldName  string
+	fieldIndex int
+	colInfo    resultSetColInfo
+}
+
 // newResultSetDefinitionMap reads the schema definition from result set metadata
 func newResultSetDefinitionMap(ctx context.Context, resultSetMetadataSchema *types.ResultSetMetadata) (resultSetDefinitionMap, error) {
 	if len(resultSetMetadataSchema.ColumnInfo) <= 0 {
@@ -25,6 +34,7 @@ func newResultSetDefinitionMap(ctx context.Context, resultSetMetadataSchema *typ
 	}
 
 	schema := make(map[string]resultSetColInfo)
+	occurrences := make(map[string]int)
 	for index, columnInfo := range resultSetMetadataSchema.ColumnInfo {
 		columnName := util.SafeString(columnInfo.Name)
 		if columnName == "" {
@@ -38,33 +48,101 @@ func newResultSetDefinitionMap(ctx context.Context, resultSetMetadataSchema *typ
 			return nil, err
 		}
 
-		if _, ok := schema[*columnInfo.Name]; !ok {
-			schema[*columnInfo.Name] = resultSetColInfo{
+		occurrences[columnName]++
+		key := occurrenceKey(columnName, occurrences[columnName])
+		if _, ok := schema[key]; !ok {
+			schema[key] = resultSetColInfo{
 				index:            index,
-				athenaColumnType: *columnInfo.Type,
+				name:             columnName,
+				athenaColumnType: columnType,
 			}
 		} else {
-			err := fmt.Errorf("duplicate column name from result set, index: %d, name: %s, columnInfo: %+v", index, columnName, columnInfo)
+			err := fmt.Errorf("duplicate column key from result set, index: %d, key: %s, columnInfo: %+v", index, key, columnInfo)
 			return nil, err
 		}
 	}
 	return schema, nil
 }
 
+// normalize returns the schema keyed by the column names normalized by the given NameMatcher
+func (schema resultSetDefinitionMap) normalize(matcher NameMatcher) (resultSetDefinitionMap, error) {
+	columns := make([]resultSetColInfo, len(schema))
+	for _, colInfo := range schema {
+		columns[colInfo.index] = colInfo
+	}
+
+	normalized := make(map[string]resultSetColInfo, len(schema))
+	occurrences := make(map[string]int, len(schema))
+	for _, colInfo := range columns {
+		name := matcher.Normalize(colInfo.name)
+		occurrences[name]++
+		key := occurrenceKey(name, occurrences[name])
+		if _, ok := normalized[key]; ok {
+			err := fmt.Errorf("duplicate normalized column key from result set, index: %d, key: %s, name: %s", colInfo.index, key, colInfo.name)
+			return nil, err
+		}
+		normalized[key] = colInfo
+	}
+	return normalized, nil
+}
+
+// lookup returns the column bound by the given athenaconv column key, see parseColumnKey
+func (schema resultSetDefinitionMap) lookup(key string) (resultSetColInfo, bool, error) {
+	parsedKey, err := parseColumnKey(key)
+	if err != nil {
+		return resultSetColInfo{}, false, err
+	}
+
+	if parsedKey.isPositional() {
+		for _, colInfo := range schema {
+			if colInfo.index == parsedKey.position {
+				return colInfo, true, nil
+			}
+		}
+		return resultSetColInfo{}, false, nil
+	}
+
+	colInfo, ok := schema[occurrenceKey(parsedKey.name, parsedKey.occurrence)]
+	return colInfo, ok, nil
+}
+
 func validateResultSetSchema(ctx context.Context, resultSetSchema resultSetDefinitionMap, modelDefSchema modelDefinitionMap) error {
+	_, err := bindResultSetSchema(ctx, resultSetSchema, modelDefSchema)
+	return err
+}
+
+// bindResultSetSchema validates the result set against the model definition and binds every model field to exactly one column
+func bindResultSetSchema(ctx context.Context, resultSetSchema resultSetDefinitionMap, modelDefSchema modelDefinitionMap) ([]resultSetBinding, error) {
 	modelSchemaLength := len(modelDefSchema)
 	resultMetadataSchemaLength := len(resultSetSchema)
 	if modelSchemaLength != resultMetadataSchemaLength {
 		err := fmt.Errorf("mismatched schema definition and result set columns count, modelSchemaLength: %d, resultMetadataSchemaLength: %d", modelSchemaLength, resultMetadataSchemaLength)
-		return err
+		return nil, err
 	}
 
-	for key := range modelDefSchema {
-		if _, ok := resultSetSchema[key]; !ok {
+	bindings := make([]resultSetBinding, 0, modelSchemaLength)
+	boundKeys := make(map[int]string, modelSchemaLength)
+	for key, modelDefColInfo := range modelDefSchema {
+		colInfo, ok, err := resultSetSchema.lookup(key)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
 			err := fmt.Errorf("column '%s' is defined in model schema but not found in result set", key)
-			return err
+			return nil, err
 		}
+		if boundKey, ok := boundKeys[colInfo.index]; ok {
+			err := fmt.Errorf("column '%s' and '%s' defined in model schema are bound to the same result set column, index: %d", boundKey, key, colInfo.index)
+			return nil, err
+		}
+		boundKeys[colInfo.index] = key
+
+		bindings = append(bindings, resultSetBinding{
+			fieldName:  modelDefColInfo.fieldName,
+			fieldIndex: modelDefColInfo.fieldIndex,
+			colInfo:    colInfo,
+		})
 	}
 
-	return nil
+	return bindings, nil
 }
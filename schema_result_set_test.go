@@ -69,7 +69,7 @@ var _ = Describe("Schema: result set", func() {
 		})
 
 		When("result set metadata has duplicate column name", func() {
-			It("should return error", func() {
+			It("should keep every occurrence keyed by occurrence", func() {
 				metadata := types.ResultSetMetadata{
 					ColumnInfo: make([]types.ColumnInfo, 0),
 				}
@@ -77,13 +77,38 @@ var _ = Describe("Schema: result set", func() {
 					Name: util.RefString("my_id_col"),
 					Type: util.RefString("varchar"),
 				})
+				metadata.ColumnInfo = append(metadata.ColumnInfo, types.ColumnInfo{
+					Name: util.RefString("my_id_col"),
+					Type: util.RefString("integer"),
+				})
+				def, err := newResultSetDefinitionMap(ctx, &metadata)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(def)).To(Equal(2))
+				Expect(def["my_id_col"].index).To(Equal(0))
+				Expect(def["my_id_col#2"].index).To(Equal(1))
+				Expect(def["my_id_col#2"].name).To(Equal("my_id_col"))
+				Expect(def["my_id_col#2"].athenaColumnType).To(Equal("integer"))
+			})
+
+			It("should return error if occurrence key collides with another column name", func() {
+				metadata := types.ResultSetMetadata{
+					ColumnInfo: make([]types.ColumnInfo, 0),
+				}
+				metadata.ColumnInfo = append(metadata.ColumnInfo, types.ColumnInfo{
+					Name: util.RefString("my_id_col#2"),
+					Type: util.RefString("varchar"),
+				})
+				metadata.ColumnInfo = append(metadata.ColumnInfo, types.ColumnInfo{
+					Name: util.RefString("my_id_col"),
+					Type: util.RefString("varchar"),
+				})
 				metadata.ColumnInfo = append(metadata.ColumnInfo, types.ColumnInfo{
 					Name: util.RefString("my_id_col"),
 					Type: util.RefString("varchar"),
 				})
 				_, err := newResultSetDefinitionMap(ctx, &metadata)
 				Expect(err).To(HaveOccurred())
-				Expect(strings.ToLower(err.Error())).To(MatchRegexp("duplicate .* my_id_col"))
+				Expect(strings.ToLower(err.Error())).To(MatchRegexp("duplicate .* my_id_col#2"))
 			})
 		})
 
@@ -107,7 +132,7 @@ var _ = Describe("Schema: result set", func() {
 					ID   int    `athenaconv:"my_id_col"`
 					Name string `athenaconv:"name_col"`
 				}
-				modelDefinitionSchema, err := newModelDefinitionMap(reflect.TypeOf(test{}))
+				modelDefinitionSchema, err := newModelDefinitionMap(reflect.TypeOf(test{}), ExactNameMatcher)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(len(modelDefinitionSchema)).To(Equal(2))
 
@@ -139,7 +164,7 @@ var _ = Describe("Schema: result set", func() {
 					ID   int    `athenaconv:"my_id_col"`
 					Name string `athenaconv:"name_col"`
 				}
-				modelDefinitionSchema, err := newModelDefinitionMap(reflect.TypeOf(test{}))
+				modelDefinitionSchema, err := newModelDefinitionMap(reflect.TypeOf(test{}), ExactNameMatcher)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(len(modelDefinitionSchema)).To(Equal(2))
 
@@ -165,4 +190,77 @@ var _ = Describe("Schema: result set", func() {
 			})
 		})
 	})
+
+	Context("bindResultSetSchema", func() {
+		var metadata types.ResultSetMetadata
+
+		BeforeEach(func() {
+			// select a.id, b.id, 1 from a join b
+			metadata = types.ResultSetMetadata{
+				ColumnInfo: []types.ColumnInfo{
+					{Name: util.RefString("id"), Type: util.RefString("integer")},
+					{Name: util.RefString("id"), Type: util.RefString("varchar")},
+					{Name: util.RefString("_col2"), Type: util.RefString("integer")},
+				},
+			}
+		})
+
+		When("model binds columns by occurrence and position", func() {
+			It("should bind every field to the expected column", func() {
+				type test struct {
+					AID   int    `athenaconv:"id"`
+					BID   string `athenaconv:"id#2"`
+					Third int    `athenaconv:"#2"`
+				}
+				modelDefinitionSchema, err := newModelDefinitionMap(reflect.TypeOf(test{}), ExactNameMatcher)
+				Expect(err).ToNot(HaveOccurred())
+				resultSetSchema, err := newResultSetDefinitionMap(ctx, &metadata)
+				Expect(err).ToNot(HaveOccurred())
+
+				bindings, err := bindResultSetSchema(ctx, resultSetSchema, modelDefinitionSchema)
+				Expect(err).ToNot(HaveOccurred())
+				indexes := make(map[string]int)
+				for _, binding := range bindings {
+					indexes[binding.fieldName] = binding.colInfo.index
+				}
+				Expect(indexes).To(Equal(map[string]int{"AID": 0, "BID": 1, "Third": 2}))
+			})
+		})
+
+		When("model binds the same column twice", func() {
+			It("should return error", func() {
+				type test struct {
+					AID   int    `athenaconv:"id#1"`
+					BID   string `athenaconv:"#0"`
+					Third int    `athenaconv:"#2"`
+				}
+				modelDefinitionSchema, err := newModelDefinitionMap(reflect.TypeOf(test{}), ExactNameMatcher)
+				Expect(err).ToNot(HaveOccurred())
+				resultSetSchema, err := newResultSetDefinitionMap(ctx, &metadata)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = bindResultSetSchema(ctx, resultSetSchema, modelDefinitionSchema)
+				Expect(err).To(HaveOccurred())
+				Expect(strings.ToLower(err.Error())).To(ContainSubstring("bound to the same result set column"))
+			})
+		})
+
+		When("model binds a position out of range", func() {
+			It("should return error", func() {
+				type test struct {
+					AID   int    `athenaconv:"id"`
+					BID   string `athenaconv:"id#2"`
+					Third int    `athenaconv:"#3"`
+				}
+				modelDefinitionSchema, err := newModelDefinitionMap(reflect.TypeOf(test{}), ExactNameMatcher)
+				Expect(err).ToNot(HaveOccurred())
+				resultSetSchema, err := newResultSetDefinitionMap(ctx, &metadata)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = bindResultSetSchema(ctx, resultSetSchema, modelDefinitionSchema)
+				Expect(err).To(HaveOccurred())
+				Expect(strings.ToLower(err.Error())).To(MatchRegexp("'#3' .* not found"))
+			})
+		})
+	})
 })
@@ -0,0 +1,151 @@
+package athenaconv
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SelectOption configures the SQL query generated by BuildSelect
+type SelectOption func(*selectOptions)
+
+type selectOptions struct {
+	nameMatcher NameMatcher
+	jsonCast    bool
+	where       []string
+	orderBy     []string
+	limit       int
+}
+
+// Where adds the condition to the WHERE clause, conditions of multiple Where options are combined with AND
+func Where(condition string) SelectOption {
+	return func(options *selectOptions) {
+		options.where = append(options.where, condition)
+	}
+}
+
+// OrderBy adds the expressions to the ORDER BY clause, e.g. OrderBy("day DESC", "id")
+func OrderBy(exprs ...string) SelectOption {
+	return func(options *selectOptions) {
+		options.orderBy = append(options.orderBy, exprs...)
+	}
+}
+
+// Limit sets the LIMIT clause, no limit by default
+func Limit(limit int) SelectOption {
+	return func(options *selectOptions) {
+		options.limit = limit
+	}
+}
+
+// SelectNameMatcher sets how the column names of fields without athenaconv tag are derived, defaults to ExactNameMatcher.
+// It should be the same NameMatcher as the one of the mapper converting the results.
+func SelectNameMatcher(nameMatcher NameMatcher) SelectOption {
+	return func(options *selectOptions) {
+		options.nameMatcher = nameMatcher
+	}
+}
+
+// CastComplexToJSON selects the columns of complex fields, i.e. slices, maps and nested structs, as json_format(cast(... as json)).
+// The mapper decodes these columns from JSON, so that values containing commas or = are converted exactly,
+// unlike the text rendering of arrays, maps and rows by athena.
+func CastComplexToJSON() SelectOption {
+	return func(options *selectOptions) {
+		options.jsonCast = true
+	}
+}
+
+// BuildSelect generates the SELECT statement of the columns of modelType from the given relation, in the order of the struct fields.
+// Columns are selected by name, or with the SQL expression of the expr tag option aliased to the column name.
+// The generated projection matches the model by construction, so the model and the query cannot drift apart.
+//
+// Example:
+//
+//	type MyModel struct {
+//		Day   time.Time `athenaconv:"day"`
+//		Total int64     `athenaconv:"total,expr=sum(amount)"`
+//	}
+//	sql, err := athenaconv.BuildSelect(reflect.TypeOf(MyModel{}), "my_db.sales", athenaconv.Where("year = 2021"), athenaconv.OrderBy("day"))
+//	// SELECT "day", sum(amount) AS "total" FROM my_db.sales WHERE year = 2021 ORDER BY day
+func BuildSelect(modelType reflect.Type, from string, opts ...SelectOption) (string, error) {
+	options := selectOptions{nameMatcher: ExactNameMatcher}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if strings.TrimSpace(from) == "" {
+		err := fmt.Errorf("missing from relation of select statement")
+		return "", err
+	}
+	if options.limit < 0 {
+		err := fmt.Errorf("invalid limit: %d, limit should be zero or positive", options.limit)
+		return "", err
+	}
+
+	projection, err := selectProjection(modelType, options.nameMatcher, options.jsonCast)
+	if err != nil {
+		return "", err
+	}
+
+	var builder strings.Builder
+	builder.WriteString("SELECT ")
+	builder.WriteString(strings.Join(projection, ", "))
+	builder.WriteString(" FROM ")
+	builder.WriteString(from)
+	if len(options.where) == 1 {
+		builder.WriteString(" WHERE " + options.where[0])
+	} else if len(options.where) > 1 {
+		builder.WriteString(" WHERE (" + strings.Join(options.where, ") AND (") + ")")
+	}
+	if len(options.orderBy) > 0 {
+		builder.WriteString(" ORDER BY " + strings.Join(options.orderBy, ", "))
+	}
+	if options.limit > 0 {
+		builder.WriteString(" LIMIT " + strconv.Itoa(options.limit))
+	}
+	return builder.String(), nil
+}
+
+// selectProjection returns the select items of the columns of modelType in the order of the struct fields,
+// complex columns are cast to JSON if jsonCast is true
+func selectProjection(modelType reflect.Type, matcher NameMatcher, jsonCast bool) ([]string, error) {
+	modelDefinitionSchema, err := newModelDefinitionMap(modelType, matcher)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make([]modelDefinitionColInfo, 0, len(modelDefinitionSchema))
+	for _, colInfo := range modelDefinitionSchema {
+		columns = append(columns, colInfo)
+	}
+	sort.Slice(columns, func(i, j int) bool {
+		return columns[i].fieldIndex < columns[j].fieldIndex
+	})
+
+	projection := make([]string, 0, len(columns))
+	for _, colInfo := range columns {
+		key, err := parseColumnKey(colInfo.columnName)
+		if err != nil {
+			return nil, err
+		}
+		if key.isPositional() {
+			err := fmt.Errorf("positional column key '%s' of field %s cannot be selected by name", colInfo.columnName, colInfo.fieldName)
+			return nil, err
+		}
+
+		column := quoteIdentifier(key.name)
+		expr := colInfo.expr
+		if jsonCast && isJSONFieldType(modelType.Field(colInfo.fieldIndex).Type) {
+			if expr == "" {
+				expr = column
+			}
+			expr = "json_format(cast(" + expr + " as json))"
+		}
+		if expr != "" {
+			column = expr + " AS " + column
+		}
+		projection = append(projection, column)
+	}
+	return projection, nil
+}
@@ -0,0 +1,126 @@
+package athenaconv
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BuildSelect", func() {
+	type salesModel struct {
+		Day   time.Time `athenaconv:"day"`
+		Shop  string    `athenaconv:"shop"`
+		Total int64     `athenaconv:"total,expr=sum(amount)"`
+	}
+
+	When("model is tagged", func() {
+		It("should select the columns in the order of the fields", func() {
+			sql, err := BuildSelect(reflect.TypeOf(salesModel{}), "my_db.sales")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sql).To(Equal(`SELECT "day", "shop", sum(amount) AS "total" FROM my_db.sales`))
+		})
+
+		It("should append where, order by and limit clauses", func() {
+			sql, err := BuildSelect(reflect.TypeOf(salesModel{}), "my_db.sales",
+				Where("year = 2021"),
+				Where("shop <> 'test' OR day > ?"),
+				OrderBy("day DESC", "shop"),
+				Limit(10),
+			)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sql).To(Equal(`SELECT "day", "shop", sum(amount) AS "total" FROM my_db.sales` +
+				` WHERE (year = 2021) AND (shop <> 'test' OR day > ?) ORDER BY day DESC, shop LIMIT 10`))
+		})
+
+		It("should generate the projection validated by the mapper", func() {
+			// arrange
+			sql, err := BuildSelect(reflect.TypeOf(salesModel{}), "sales")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sql).To(ContainSubstring(`AS "total"`))
+			mapper, err := NewMapperFor(reflect.TypeOf(salesModel{}))
+			Expect(err).ToNot(HaveOccurred())
+
+			// act: result set as returned by athena for the generated query
+			resultSet := &types.ResultSet{
+				ResultSetMetadata: &types.ResultSetMetadata{ColumnInfo: []types.ColumnInfo{
+					{Name: util.RefString("day"), Type: util.RefString("date")},
+					{Name: util.RefString("shop"), Type: util.RefString("varchar")},
+					{Name: util.RefString("total"), Type: util.RefString("bigint")},
+				}},
+				Rows: []types.Row{{Data: []types.Datum{
+					{VarCharValue: util.RefString("2021-01-02")},
+					{VarCharValue: util.RefString("main")},
+					{VarCharValue: util.RefString("42")},
+				}}},
+			}
+			result, err := mapper.FromAthenaResultSetV2(context.Background(), resultSet)
+
+			// assert
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(HaveLen(1))
+			Expect(result[0].(*salesModel).Total).To(Equal(int64(42)))
+		})
+	})
+
+	When("complex columns are cast to JSON", func() {
+		It("should wrap the expression of complex fields", func() {
+			type model struct {
+				ID    int      `athenaconv:"id"`
+				Names []string `athenaconv:"names,expr=array_agg(name)"`
+			}
+			sql, err := BuildSelect(reflect.TypeOf(model{}), "t", CastComplexToJSON(), OrderBy("id"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sql).To(Equal(`SELECT "id", json_format(cast(array_agg(name) as json)) AS "names" FROM t ORDER BY id`))
+		})
+	})
+
+	When("model has no tags", func() {
+		type untagged struct {
+			SourceComputersCount int
+		}
+
+		It("should derive the column names with the name matcher", func() {
+			sql, err := BuildSelect(reflect.TypeOf(untagged{}), "t", SelectNameMatcher(SnakeCaseNameMatcher))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(sql).To(Equal(`SELECT "source_computers_count" FROM t`))
+		})
+
+		It("should return error with exact name matcher", func() {
+			_, err := BuildSelect(reflect.TypeOf(untagged{}), "t")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("input is invalid", func() {
+		It("should return error for positional column", func() {
+			type positional struct {
+				First string `athenaconv:"#0"`
+			}
+			_, err := BuildSelect(reflect.TypeOf(positional{}), "t")
+			Expect(err).To(MatchError("positional column key '#0' of field First cannot be selected by name"))
+		})
+
+		It("should return error for missing from relation", func() {
+			_, err := BuildSelect(reflect.TypeOf(salesModel{}), " ")
+			Expect(err).To(MatchError("missing from relation of select statement"))
+		})
+
+		It("should return error for negative limit", func() {
+			_, err := BuildSelect(reflect.TypeOf(salesModel{}), "t", Limit(-1))
+			Expect(err).To(MatchError("invalid limit: -1, limit should be zero or positive"))
+		})
+
+		It("should return error for invalid tag option", func() {
+			type invalid struct {
+				ID int `athenaconv:"id,omitempty"`
+			}
+			_, err := BuildSelect(reflect.TypeOf(invalid{}), "t")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,68 @@
+package athenaconv
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const (
+	// tagName is the struct tag of the model fields: the athena column name followed by comma separated options
+	tagName = "athenaconv"
+	// exprTagOption is the SQL expression of the column generated by BuildSelect, e.g. athenaconv:"total,expr=sum(amount)".
+	// The expression may contain commas so it should be the last option.
+	exprTagOption = "expr="
+	// partitionTagOption marks the partition columns of the table generated by GenerateDDL, e.g. athenaconv:"day,partition"
+	partitionTagOption = "partition"
+)
+
+// fieldTag is the parsed athenaconv struct tag of a model field
+type fieldTag struct {
+	// columnName is the athena column name, empty if not defined
+	columnName string
+	// expr is the SQL expression selecting the column, empty if the column is selected by name
+	expr string
+	// partition is true if the column is a partition column of the table
+	partition bool
+}
+
+// parseFieldTag parses the athenaconv struct tag of the field: "column_name[,partition][,expr=sql expression]"
+func parseFieldTag(field reflect.StructField) (fieldTag, error) {
+	tag := field.Tag.Get(tagName)
+	parts := strings.SplitN(tag, ",", 2)
+	parsed := fieldTag{columnName: strings.TrimSpace(parts[0])}
+	if len(parts) == 1 {
+		return parsed, nil
+	}
+
+	options := strings.TrimSpace(parts[1])
+	for options != "" {
+		if strings.HasPrefix(options, exprTagOption) {
+			parsed.expr = strings.TrimSpace(options[len(exprTagOption):])
+			if parsed.expr == "" {
+				err := fmt.Errorf("empty expr in athenaconv tag of field: %s", field.Name)
+				return fieldTag{}, err
+			}
+			return parsed, nil
+		}
+
+		option := strings.SplitN(options, ",", 2)
+		switch strings.TrimSpace(option[0]) {
+		case partitionTagOption:
+			parsed.partition = true
+		default:
+			err := fmt.Errorf("unknown option '%s' in athenaconv tag of field: %s", strings.TrimSpace(option[0]), field.Name)
+			return fieldTag{}, err
+		}
+		options = ""
+		if len(option) == 2 {
+			options = strings.TrimSpace(option[1])
+		}
+	}
+	return parsed, nil
+}
+
+// tagColumnName returns the athena column name of the athenaconv struct tag of the field, ignoring the options
+func tagColumnName(field reflect.StructField) string {
+	return strings.TrimSpace(strings.SplitN(field.Tag.Get(tagName), ",", 2)[0])
+}
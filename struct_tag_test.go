@@ -0,0 +1,67 @@
+package athenaconv
+
+import (
+	"reflect"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Struct tag", func() {
+	type test struct {
+		Plain    int    `athenaconv:"plain"`
+		Expr     int64  `athenaconv:"total, expr=coalesce(sum(amount), 0)"`
+		Untagged string ``
+		Unknown  string `athenaconv:"unknown,omitempty"`
+		Empty    string `athenaconv:"empty,expr="`
+		Day      string `athenaconv:"day, partition, expr=date_format(ts, '%Y-%m-%d')"`
+	}
+	field := func(name string) reflect.StructField {
+		structField, _ := reflect.TypeOf(test{}).FieldByName(name)
+		return structField
+	}
+
+	When("tag has no options", func() {
+		It("should return the column name", func() {
+			tag, err := parseFieldTag(field("Plain"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tag).To(Equal(fieldTag{columnName: "plain"}))
+			Expect(tagColumnName(field("Plain"))).To(Equal("plain"))
+		})
+
+		It("should return empty column name for untagged field", func() {
+			tag, err := parseFieldTag(field("Untagged"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tag).To(Equal(fieldTag{}))
+		})
+	})
+
+	When("tag has expr option", func() {
+		It("should return the expression including commas", func() {
+			tag, err := parseFieldTag(field("Expr"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tag).To(Equal(fieldTag{columnName: "total", expr: "coalesce(sum(amount), 0)"}))
+			Expect(tagColumnName(field("Expr"))).To(Equal("total"))
+		})
+
+		It("should return error for empty expression", func() {
+			_, err := parseFieldTag(field("Empty"))
+			Expect(err).To(MatchError("empty expr in athenaconv tag of field: Empty"))
+		})
+	})
+
+	When("tag has partition option", func() {
+		It("should return partition and the expression after it", func() {
+			tag, err := parseFieldTag(field("Day"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tag).To(Equal(fieldTag{columnName: "day", expr: "date_format(ts, '%Y-%m-%d')", partition: true}))
+		})
+	})
+
+	When("tag has unknown option", func() {
+		It("should return error", func() {
+			_, err := parseFieldTag(field("Unknown"))
+			Expect(err).To(MatchError("unknown option 'omitempty' in athenaconv tag of field: Unknown"))
+		})
+	})
+})
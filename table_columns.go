@@ -0,0 +1,119 @@
+package athenaconv
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv/util"
+)
+
+// tableColumnJSON is a table column in the JSON output of the AWS CLI
+type tableColumnJSON struct {
+	Name    string  `json:"Name"`
+	Type    string  `json:"Type"`
+	Comment *string `json:"Comment"`
+}
+
+// tableMetadataJSON holds the columns and partition keys of the JSON output of aws athena get-table-metadata, aws glue get-table,
+// or of their TableMetadata or Table object
+type tableMetadataJSON struct {
+	TableMetadata *tableMetadataJSON `json:"TableMetadata"`
+	Table         *tableMetadataJSON `json:"Table"`
+	Name          string             `json:"Name"`
+	Columns       []tableColumnJSON  `json:"Columns"`
+	PartitionKeys []tableColumnJSON  `json:"PartitionKeys"`
+	// StorageDescriptor holds the columns of glue tables
+	StorageDescriptor *tableMetadataJSON `json:"StorageDescriptor"`
+}
+
+// ReadTableColumns reads the columns of a table from a local JSON dump, excluding the partition keys, for DiffSchema.
+// The JSON is the output of aws athena get-table-metadata or aws glue get-table, or an array of columns with Name and Type.
+//
+// Example:
+//
+//	// aws athena get-table-metadata --catalog-name AwsDataCatalog --database-name my_db --table-name events > events.json
+//	columns, err := athenaconv.ReadTableColumns(file)
+func ReadTableColumns(reader io.Reader) ([]types.Column, error) {
+	metadata, err := ReadTableMetadata(reader)
+	if err != nil {
+		return nil, err
+	}
+	return metadata.Columns, nil
+}
+
+// ReadTableMetadata reads the name, columns and partition keys of a table from a local JSON dump, see ReadTableColumns
+func ReadTableMetadata(reader io.Reader) (*types.TableMetadata, error) {
+	data, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+
+	var table tableMetadataJSON
+	var columns []tableColumnJSON
+	if arrayErr := json.Unmarshal(data, &columns); arrayErr != nil {
+		if err := json.Unmarshal(data, &table); err != nil {
+			err := fmt.Errorf("invalid table columns JSON: %v", err)
+			return nil, err
+		}
+		table = table.table()
+		if table.Columns == nil {
+			err := fmt.Errorf("invalid table columns JSON: no columns found")
+			return nil, err
+		}
+		columns = table.Columns
+	}
+
+	metadata := &types.TableMetadata{}
+	if table.Name != "" {
+		metadata.Name = util.RefString(table.Name)
+	}
+	if metadata.Columns, err = newTableColumns(columns); err != nil {
+		return nil, err
+	}
+	if metadata.PartitionKeys, err = newTableColumns(table.PartitionKeys); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+func newTableColumns(columns []tableColumnJSON) ([]types.Column, error) {
+	result := make([]types.Column, 0, len(columns))
+	for i, column := range columns {
+		if column.Name == "" || column.Type == "" {
+			err := fmt.Errorf("invalid table columns JSON: missing name or type of column %d", i)
+			return nil, err
+		}
+		result = append(result, types.Column{
+			Name:    util.RefString(column.Name),
+			Type:    util.RefString(column.Type),
+			Comment: column.Comment,
+		})
+	}
+	return result, nil
+}
+
+// table returns the first nested object defining the columns, with the name and partition keys of the table defining it,
+// as glue tables define the columns in their StorageDescriptor
+func (t *tableMetadataJSON) table() tableMetadataJSON {
+	if t == nil {
+		return tableMetadataJSON{}
+	}
+	if t.Columns != nil {
+		return *t
+	}
+	for _, nested := range []*tableMetadataJSON{t.TableMetadata, t.Table, t.StorageDescriptor} {
+		if table := nested.table(); table.Columns != nil {
+			if table.Name == "" {
+				table.Name = t.Name
+			}
+			if table.PartitionKeys == nil {
+				table.PartitionKeys = t.PartitionKeys
+			}
+			return table
+		}
+	}
+	return tableMetadataJSON{}
+}
@@ -0,0 +1,61 @@
+package athenaconv
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/athena/types"
+	"github.com/kent-id/athenaconv/util"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReadTableColumns", func() {
+	expected := []types.Column{
+		{Name: util.RefString("id"), Type: util.RefString("bigint")},
+		{Name: util.RefString("tags"), Type: util.RefString("array<string>"), Comment: util.RefString("labels")},
+	}
+	const columnsJSON = `[{"Name": "id", "Type": "bigint"}, {"Name": "tags", "Type": "array<string>", "Comment": "labels"}]`
+
+	It("should read the output of get-table-metadata without the partition keys", func() {
+		columns, err := ReadTableColumns(strings.NewReader(`{"TableMetadata": {"Name": "events", "Columns": ` + columnsJSON +
+			`, "PartitionKeys": [{"Name": "day", "Type": "string"}]}}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(columns).To(Equal(expected))
+	})
+
+	It("should read the output of glue get-table", func() {
+		columns, err := ReadTableColumns(strings.NewReader(`{"Table": {"Name": "events", "StorageDescriptor": {"Columns": ` + columnsJSON + `}}}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(columns).To(Equal(expected))
+	})
+
+	It("should read an array of columns", func() {
+		columns, err := ReadTableColumns(strings.NewReader(columnsJSON))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(columns).To(Equal(expected))
+	})
+
+	It("should read the name and partition keys of the table", func() {
+		metadata, err := ReadTableMetadata(strings.NewReader(`{"Table": {"Name": "events", "StorageDescriptor": {"Columns": ` + columnsJSON +
+			`}, "PartitionKeys": [{"Name": "day", "Type": "string"}]}}`))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(*metadata.Name).To(Equal("events"))
+		Expect(metadata.Columns).To(Equal(expected))
+		Expect(metadata.PartitionKeys).To(Equal([]types.Column{{Name: util.RefString("day"), Type: util.RefString("string")}}))
+	})
+
+	It("should return error for invalid JSON", func() {
+		_, err := ReadTableColumns(strings.NewReader(`{"Columns": [`))
+		Expect(err).To(MatchError(HavePrefix("invalid table columns JSON")))
+	})
+
+	It("should return error if no columns are found", func() {
+		_, err := ReadTableColumns(strings.NewReader(`{"Table": {"Name": "events"}}`))
+		Expect(err).To(MatchError("invalid table columns JSON: no columns found"))
+	})
+
+	It("should return error for column without type", func() {
+		_, err := ReadTableColumns(strings.NewReader(`[{"Name": "id"}]`))
+		Expect(err).To(MatchError("invalid table columns JSON: missing name or type of column 0"))
+	})
+})